@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"sync"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/sync/singleflight"
@@ -33,6 +34,21 @@ const (
 	closed
 )
 
+func (s connectionState) String() string {
+	switch s {
+	case connected:
+		return "connected"
+	case disconnected:
+		return "disconnected"
+	case reconnecting:
+		return "reconnecting"
+	case closed:
+		return "closed"
+	default:
+		return "unknown"
+	}
+}
+
 // ErrorEvent represents an error from a reader or writer with connection generation info.
 type ErrorEvent struct {
 	Err        error
@@ -43,8 +59,34 @@ type ErrorEvent struct {
 const (
 	// Default buffer capacity used by the writer - 64MB
 	DefaultBufferSize = 64 * 1024 * 1024
+
+	// maxRecentReconnects is the number of reconnection attempts retained
+	// for diagnostics.
+	maxRecentReconnects = 5
 )
 
+// ReconnectAttempt records the outcome of a single reconnection attempt.
+type ReconnectAttempt struct {
+	Time       time.Time `json:"time"`
+	Generation uint64    `json:"generation"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// DebugInfo is a point-in-time snapshot of the pipe's internal state. It is
+// intended for diagnosing replay mismatches without enabling debug logging.
+type DebugInfo struct {
+	State             string             `json:"state"`
+	Generation        uint64             `json:"generation"`
+	ReaderSequenceNum uint64             `json:"reader_sequence_num"`
+	WriterSequenceNum uint64             `json:"writer_sequence_num"`
+	BufferedBytes     int                `json:"buffered_bytes"`
+	BufferCapacity    int                `json:"buffer_capacity"`
+	RecentReconnects  []ReconnectAttempt `json:"recent_reconnects"`
+	LastRead          time.Time          `json:"last_read"`
+	LastWrite         time.Time          `json:"last_write"`
+	IdleMS            int64              `json:"idle_ms"`
+}
+
 // Reconnector is an interface for establishing connections when the BackedPipe needs to reconnect.
 // Implementations should:
 // 1. Establish a new connection to the remote side
@@ -62,6 +104,20 @@ type Reconnector interface {
 	Reconnect(ctx context.Context, readerSeqNum uint64) (conn io.ReadWriteCloser, remoteReaderSeqNum uint64, err error)
 }
 
+// Pipe is the subset of BackedPipe used by immortal streams. It allows
+// consumers to substitute a deterministic fake in tests, see the
+// backedpipetest package.
+type Pipe interface {
+	io.ReadWriteCloser
+	// Connected returns whether the pipe currently has a live connection.
+	Connected() bool
+	// ForceReconnect replaces the current connection, if any, with a new
+	// one obtained from the pipe's Reconnector.
+	ForceReconnect() error
+}
+
+var _ Pipe = (*BackedPipe)(nil)
+
 // BackedPipe provides a reliable bidirectional byte stream over unreliable network connections.
 // It orchestrates a BackedReader and BackedWriter to provide transparent reconnection
 // and data replay capabilities.
@@ -86,6 +142,22 @@ type BackedPipe struct {
 
 	// Track first error per generation to avoid duplicate reconnections
 	lastErrorGen uint64
+
+	// Most recent reconnection attempts, oldest first, for diagnostics
+	recentReconnects []ReconnectAttempt
+
+	// Optional per-direction bandwidth limits
+	readThrottle  *throttle
+	writeThrottle *throttle
+
+	// Idle tracking and optional idle disconnection
+	activity    *activity
+	idleTimeout time.Duration
+	idleTimer   *time.Timer
+
+	// failErr is set when the pipe was closed because it can't continue,
+	// such as ErrStreamDiverged. Reads and writes then return it.
+	failErr error
 }
 
 // NewBackedPipe creates a new BackedPipe with default options and the specified reconnector.
@@ -102,11 +174,19 @@ func NewBackedPipe(ctx context.Context, reconnector Reconnector) *BackedPipe {
 		state:       disconnected,
 		connGen:     0, // Start with generation 0
 		errChan:     errChan,
+
+		readThrottle:  newThrottle(),
+		writeThrottle: newThrottle(),
+		activity:      newActivity(time.Now()),
 	}
 
 	// Create reader and writer with typed error channel for generation-aware error reporting
 	bp.reader = NewBackedReader(errChan)
 	bp.writer = NewBackedWriter(DefaultBufferSize, errChan)
+	if _, ok := reconnector.(ChecksumReconnector); ok {
+		bp.reader.EnableChecksums()
+		bp.writer.EnableChecksums()
+	}
 
 	// Start error handler goroutine
 	go bp.handleErrors()
@@ -120,6 +200,9 @@ func (bp *BackedPipe) Connect() error {
 	defer bp.mu.Unlock()
 
 	if bp.state == closed {
+		if bp.failErr != nil {
+			return bp.failErr
+		}
 		return ErrPipeClosed
 	}
 
@@ -134,7 +217,17 @@ func (bp *BackedPipe) Connect() error {
 
 // Read implements io.Reader by delegating to the BackedReader.
 func (bp *BackedPipe) Read(p []byte) (int, error) {
-	return bp.reader.Read(p)
+	n, err := bp.reader.Read(p)
+	if err != nil {
+		err = bp.failureOr(err)
+	}
+	if n > 0 {
+		bp.activity.lastRead.Store(time.Now().UnixNano())
+		// The data has already been consumed, so it is returned even if the
+		// pipe is closed while waiting on the limiter.
+		_ = bp.readThrottle.wait(bp.ctx, n)
+	}
+	return n, err
 }
 
 // Write implements io.Writer by delegating to the BackedWriter.
@@ -142,17 +235,63 @@ func (bp *BackedPipe) Write(p []byte) (int, error) {
 	bp.mu.RLock()
 	writer := bp.writer
 	state := bp.state
+	failErr := bp.failErr
 	bp.mu.RUnlock()
 
 	if state == closed {
+		if failErr != nil {
+			return 0, failErr
+		}
 		return 0, io.EOF
 	}
 
-	return writer.Write(p)
+	if err := bp.writeThrottle.wait(bp.ctx, len(p)); err != nil {
+		return 0, bp.failureOr(io.EOF)
+	}
+
+	n, err := writer.Write(p)
+	if n > 0 {
+		bp.activity.lastWrite.Store(time.Now().UnixNano())
+	}
+	if err != nil {
+		err = bp.failureOr(err)
+	}
+	return n, err
+}
+
+// failureOr returns the error the pipe failed with, or err if it didn't.
+func (bp *BackedPipe) failureOr(err error) error {
+	bp.mu.RLock()
+	defer bp.mu.RUnlock()
+	if bp.failErr != nil {
+		return bp.failErr
+	}
+	return err
+}
+
+// SetRateLimit updates the bandwidth limits of the pipe. It may be called at
+// any time, including while reads and writes are blocked on the current
+// limits.
+func (bp *BackedPipe) SetRateLimit(limit RateLimit) {
+	bp.readThrottle.setLimit(limit.ReadBytesPerSecond)
+	bp.writeThrottle.setLimit(limit.WriteBytesPerSecond)
+}
+
+// RateLimit returns the current bandwidth limits of the pipe.
+func (bp *BackedPipe) RateLimit() RateLimit {
+	return RateLimit{
+		ReadBytesPerSecond:  bp.readThrottle.limit(),
+		WriteBytesPerSecond: bp.writeThrottle.limit(),
+	}
 }
 
 // Close closes the pipe and all underlying connections.
 func (bp *BackedPipe) Close() error {
+	// Cancel the main context before taking the lock so that an in-flight
+	// reconnection, which holds the lock while waiting on the Reconnector,
+	// is aborted instead of blocking Close.
+	bp.cancel()
+
 	bp.mu.Lock()
 	defer bp.mu.Unlock()
 
@@ -160,8 +299,25 @@ func (bp *BackedPipe) Close() error {
 		return nil
 	}
 
+	return bp.closeLocked()
+}
+
+// failLocked permanently closes the pipe because it can't continue. Pending
+// and future reads and writes return err. Must be called with write lock
+// held.
+func (bp *BackedPipe) failLocked(err error) {
+	bp.failErr = err
+	bp.cancel()
+	_ = bp.closeLocked()
+}
+
+// closeLocked closes the pipe and all underlying connections. Must be called
+// with write lock held, and the pipe not already closed.
+func (bp *BackedPipe) closeLocked() error {
 	bp.state = closed
-	bp.cancel() // Cancel main context
+	if bp.idleTimer != nil {
+		bp.idleTimer.Stop()
+	}
 
 	// Close all components in parallel to avoid deadlocks
 	//
@@ -202,8 +358,44 @@ func (bp *BackedPipe) Connected() bool {
 	return bp.state == connected && bp.reader.Connected() && bp.writer.Connected()
 }
 
+// DebugInfo returns a snapshot of the pipe's sequence numbers, buffer
+// occupancy, connection state and recent reconnection attempts.
+func (bp *BackedPipe) DebugInfo() DebugInfo {
+	bp.mu.RLock()
+	defer bp.mu.RUnlock()
+
+	return DebugInfo{
+		State:             bp.state.String(),
+		Generation:        bp.connGen,
+		ReaderSequenceNum: bp.reader.SequenceNum(),
+		WriterSequenceNum: bp.writer.SequenceNum(),
+		BufferedBytes:     bp.writer.BufferedBytes(),
+		BufferCapacity:    bp.writer.BufferCapacity(),
+		RecentReconnects:  append([]ReconnectAttempt(nil), bp.recentReconnects...),
+		LastRead:          bp.LastRead(),
+		LastWrite:         bp.LastWrite(),
+		IdleMS:            bp.IdleDuration().Milliseconds(),
+	}
+}
+
+// recordReconnectLocked appends the outcome of a reconnection attempt to the
+// diagnostic history. Must be called with write lock held.
+func (bp *BackedPipe) recordReconnectLocked(err error) {
+	attempt := ReconnectAttempt{
+		Time:       time.Now(),
+		Generation: bp.connGen,
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	bp.recentReconnects = append(bp.recentReconnects, attempt)
+	if len(bp.recentReconnects) > maxRecentReconnects {
+		bp.recentReconnects = bp.recentReconnects[len(bp.recentReconnects)-maxRecentReconnects:]
+	}
+}
+
 // reconnectLocked handles the reconnection logic. Must be called with write lock held.
-func (bp *BackedPipe) reconnectLocked() error {
+func (bp *BackedPipe) reconnectLocked() (err error) {
 	if bp.state == reconnecting {
 		return ErrReconnectionInProgress
 	}
@@ -229,6 +421,15 @@ func (bp *BackedPipe) reconnectLocked() error {
 	bp.connGen++
 	bp.reader.SetGeneration(bp.connGen)
 	bp.writer.SetGeneration(bp.connGen)
+	// Record the underlying cause rather than the sentinel error returned
+	// to the caller, so the diagnostic history is actionable.
+	var cause error
+	defer func() {
+		if cause == nil {
+			cause = err
+		}
+		bp.recordReconnectLocked(cause)
+	}()
 
 	// Reconnect reader and writer
 	seqNum := make(chan uint64, 1)
@@ -244,10 +445,19 @@ func (bp *BackedPipe) reconnectLocked() error {
 	}
 
 	// Perform reconnect using the exact sequence number we just received
-	conn, remoteReaderSeqNum, err := bp.reconnector.Reconnect(bp.ctx, readerSeqNum)
+	conn, remoteReaderSeqNum, err := bp.dialLocked(readerSeqNum)
 	if err != nil {
 		// Unblock reader reconnect
 		newR <- nil
+		cause = err
+		if xerrors.Is(err, ErrStreamDiverged) {
+			// The remote has different bytes than we sent, so any further
+			// data would be corrupt. Fail the pipe so both pending and
+			// future reads and writes see the divergence, whether this was
+			// an automatic or a manual reconnect.
+			bp.failLocked(ErrStreamDiverged)
+			return ErrStreamDiverged
+		}
 		return ErrReconnectFailed
 	}
 
@@ -257,16 +467,48 @@ func (bp *BackedPipe) reconnectLocked() error {
 	// Replay our outbound data from the remote's reader sequence number
 	writerReconnectErr := bp.writer.Reconnect(remoteReaderSeqNum, conn)
 	if writerReconnectErr != nil {
+		cause = writerReconnectErr
 		return ErrReconnectWriterFailed
 	}
 
 	// Success - update state
 	bp.conn = conn
 	bp.state = connected
+	bp.activity.lastConnect.Store(time.Now().UnixNano())
 
 	return nil
 }
 
+// dialLocked establishes a new connection via the reconnector. If the
+// reconnector supports checksums, the remote reader's checksum is verified
+// against what our writer sent. Must be called with write lock held.
+func (bp *BackedPipe) dialLocked(readerSeqNum uint64) (io.ReadWriteCloser, uint64, error) {
+	cr, ok := bp.reconnector.(ChecksumReconnector)
+	if !ok {
+		return bp.reconnector.Reconnect(bp.ctx, readerSeqNum)
+	}
+
+	// The reader holds its lock during reconnection, so the checksum is
+	// consistent with readerSeqNum.
+	conn, remoteReaderSeqNum, remoteChecksum, err := cr.ReconnectWithChecksum(bp.ctx, readerSeqNum, bp.reader.Checksum())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	expected, err := bp.writer.ChecksumAt(remoteReaderSeqNum)
+	if err != nil {
+		// Leave it to the writer reconnect to report unavailable or
+		// future sequence numbers.
+		return conn, remoteReaderSeqNum, nil
+	}
+	if expected != remoteChecksum {
+		_ = conn.Close()
+		return nil, 0, xerrors.Errorf("remote read %d bytes with checksum %08x, expected %08x: %w",
+			remoteReaderSeqNum, remoteChecksum, expected, ErrStreamDiverged)
+	}
+	return conn, remoteReaderSeqNum, nil
+}
+
 // handleErrors listens for connection errors from reader/writer and triggers reconnection.
 // It filters errors from old connections and ensures only the first error per generation
 // triggers reconnection.
@@ -313,15 +555,10 @@ func (bp *BackedPipe) handleConnectionError(errorEvt ErrorEvent) {
 	// Mark as disconnected
 	bp.state = disconnected
 
-	// Try to reconnect using internal context
-	reconnectErr := bp.reconnectLocked()
-
-	if reconnectErr != nil {
-		// Reconnection failed - log or handle as needed
-		// For now, we'll just continue and wait for manual reconnection
-		_ = errorEvt.Err       // Use the original error from the component
-		_ = errorEvt.Component // Component info available for potential logging by higher layers
-	}
+	// Try to reconnect using internal context. If that fails, the pipe
+	// waits for a manual reconnection, unless the stream diverged, in which
+	// case reconnectLocked has failed the pipe.
+	_ = bp.reconnectLocked()
 }
 
 // ForceReconnect forces a reconnection attempt immediately.
@@ -336,6 +573,9 @@ func (bp *BackedPipe) ForceReconnect() error {
 		defer bp.mu.Unlock()
 
 		if bp.state == closed {
+			if bp.failErr != nil {
+				return nil, bp.failErr
+			}
 			return nil, io.EOF
 		}
 
@@ -525,6 +525,42 @@ func TestBackedPipe_CloseIdempotent(t *testing.T) {
 	require.NoError(t, err)
 }
 
+// ctxReconnector blocks every reconnection attempt until its context is
+// canceled, like a server waiting for the client to dial back.
+type ctxReconnector struct {
+	entered chan struct{}
+}
+
+func (c *ctxReconnector) Reconnect(ctx context.Context, _ uint64) (io.ReadWriteCloser, uint64, error) {
+	close(c.entered)
+	<-ctx.Done()
+	return nil, 0, ctx.Err()
+}
+
+func TestBackedPipe_CloseAbortsReconnect(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	reconnector := &ctxReconnector{entered: make(chan struct{})}
+	bp := backedpipe.NewBackedPipe(context.Background(), reconnector)
+
+	connectErr := make(chan error, 1)
+	go func() {
+		connectErr <- bp.Connect()
+	}()
+	testutil.TryReceive(ctx, t, reconnector.entered)
+
+	// Connect holds the pipe lock while the reconnector blocks, so Close
+	// must cancel the reconnect before it can take the lock.
+	closeErr := make(chan error, 1)
+	go func() {
+		closeErr <- bp.Close()
+	}()
+	require.NoError(t, testutil.RequireReceive(ctx, t, closeErr))
+	require.ErrorIs(t, testutil.RequireReceive(ctx, t, connectErr), backedpipe.ErrReconnectFailed)
+	require.False(t, bp.Connected())
+}
+
 func TestBackedPipe_ReconnectFunctionFailure(t *testing.T) {
 	t.Parallel()
 
@@ -591,6 +627,318 @@ func TestBackedPipe_ForceReconnect(t *testing.T) {
 	require.Equal(t, "response data", string(buf[:n]))
 }
 
+func TestBackedPipe_DebugInfo(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	conn1 := newMockConnection()
+	conn2 := newMockConnection()
+	conn2.seqNum = 5
+	reconnector, _ := mockReconnectFunc(conn1, conn2)
+
+	bp := backedpipe.NewBackedPipe(ctx, reconnector)
+	defer bp.Close()
+
+	info := bp.DebugInfo()
+	require.Equal(t, "disconnected", info.State)
+	require.Empty(t, info.RecentReconnects)
+	require.Equal(t, backedpipe.DefaultBufferSize, info.BufferCapacity)
+
+	err := bp.Connect()
+	require.NoError(t, err)
+
+	_, err = bp.Write([]byte("hello world"))
+	require.NoError(t, err)
+
+	conn1.WriteString("abc")
+	buf := make([]byte, 3)
+	_, err = io.ReadFull(bp, buf)
+	require.NoError(t, err)
+
+	err = bp.ForceReconnect()
+	require.NoError(t, err)
+
+	info = bp.DebugInfo()
+	require.Equal(t, "connected", info.State)
+	require.Equal(t, uint64(2), info.Generation)
+	require.Equal(t, uint64(3), info.ReaderSequenceNum)
+	require.Equal(t, uint64(11), info.WriterSequenceNum)
+	require.Equal(t, 11, info.BufferedBytes)
+	require.Len(t, info.RecentReconnects, 2)
+	for _, attempt := range info.RecentReconnects {
+		require.Empty(t, attempt.Error)
+	}
+	// The remote had read 5 bytes, so the remaining 6 were replayed.
+	require.Equal(t, " world", conn2.ReadString())
+}
+
+func TestBackedPipe_DebugInfoRecordsFailures(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	failingReconnector := &mockReconnector{
+		connections: nil, // No connections available
+	}
+
+	bp := backedpipe.NewBackedPipe(ctx, failingReconnector)
+	defer bp.Close()
+
+	for range 7 {
+		err := bp.Connect()
+		require.ErrorIs(t, err, backedpipe.ErrReconnectFailed)
+	}
+
+	info := bp.DebugInfo()
+	require.Equal(t, "disconnected", info.State)
+	// Only the most recent attempts are retained.
+	require.Len(t, info.RecentReconnects, 5)
+	require.Equal(t, uint64(3), info.RecentReconnects[0].Generation)
+	require.Equal(t, uint64(7), info.RecentReconnects[4].Generation)
+	for _, attempt := range info.RecentReconnects {
+		require.Contains(t, attempt.Error, "no more connections available")
+	}
+}
+
+func TestBackedPipe_RateLimit(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	conn := newMockConnection()
+	reconnector, _ := mockReconnectFunc(conn)
+
+	bp := backedpipe.NewBackedPipe(ctx, reconnector)
+	defer bp.Close()
+
+	require.Equal(t, backedpipe.RateLimit{}, bp.RateLimit())
+
+	err := bp.Connect()
+	require.NoError(t, err)
+
+	limit := backedpipe.RateLimit{
+		ReadBytesPerSecond:  1000,
+		WriteBytesPerSecond: 200,
+	}
+	bp.SetRateLimit(limit)
+	require.Equal(t, limit, bp.RateLimit())
+
+	// The first 200 bytes fit in the burst, the remaining 100 must wait
+	// for roughly half a second.
+	start := time.Now()
+	_, err = bp.Write(bytes.Repeat([]byte("a"), 300))
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+	require.Len(t, conn.ReadString(), 300)
+
+	// Removing the limit makes writes immediate again.
+	bp.SetRateLimit(backedpipe.RateLimit{})
+	require.Equal(t, backedpipe.RateLimit{}, bp.RateLimit())
+	start = time.Now()
+	_, err = bp.Write(bytes.Repeat([]byte("b"), 10000))
+	require.NoError(t, err)
+	require.Less(t, time.Since(start), testutil.WaitShort)
+}
+
+func TestBackedPipe_RateLimitWriteUnblocksOnClose(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	conn := newMockConnection()
+	reconnector, _ := mockReconnectFunc(conn)
+
+	bp := backedpipe.NewBackedPipe(ctx, reconnector)
+	err := bp.Connect()
+	require.NoError(t, err)
+
+	bp.SetRateLimit(backedpipe.RateLimit{WriteBytesPerSecond: 1})
+
+	writeDone := make(chan error, 1)
+	go func() {
+		_, err := bp.Write([]byte("this will take a long time"))
+		writeDone <- err
+	}()
+
+	require.NoError(t, bp.Close())
+	err = testutil.RequireReceive(testutil.Context(t, testutil.WaitShort), t, writeDone)
+	require.ErrorIs(t, err, io.EOF)
+}
+
+// checksumReconnector is a reconnector that exchanges checksums, reporting
+// that the remote read the given bytes on the second connection.
+type checksumReconnector struct {
+	mockReconnector
+	remoteRead []byte
+
+	mu                 sync.Mutex
+	lastReaderChecksum uint32
+}
+
+func (c *checksumReconnector) ReconnectWithChecksum(ctx context.Context, readerSeqNum uint64, readerChecksum uint32) (io.ReadWriteCloser, uint64, uint32, error) {
+	conn, _, err := c.Reconnect(ctx, readerSeqNum)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	c.mu.Lock()
+	c.lastReaderChecksum = readerChecksum
+	c.mu.Unlock()
+	if c.GetCallCount() == 1 {
+		return conn, 0, backedpipe.Checksum(nil), nil
+	}
+	return conn, uint64(len(c.remoteRead)), backedpipe.Checksum(c.remoteRead), nil
+}
+
+func TestBackedPipe_Checksums(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Match", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		conn1 := newMockConnection()
+		conn2 := newMockConnection()
+		reconnector := &checksumReconnector{
+			mockReconnector: mockReconnector{connections: []*mockConnection{conn1, conn2}},
+			remoteRead:      []byte("hello"),
+		}
+
+		bp := backedpipe.NewBackedPipe(ctx, reconnector)
+		defer bp.Close()
+
+		err := bp.Connect()
+		require.NoError(t, err)
+
+		_, err = bp.Write([]byte("hello world"))
+		require.NoError(t, err)
+
+		conn1.WriteString("abc")
+		buf := make([]byte, 3)
+		_, err = io.ReadFull(bp, buf)
+		require.NoError(t, err)
+
+		err = bp.ForceReconnect()
+		require.NoError(t, err)
+		require.True(t, bp.Connected())
+		require.Equal(t, " world", conn2.ReadString())
+
+		reconnector.mu.Lock()
+		defer reconnector.mu.Unlock()
+		require.Equal(t, backedpipe.Checksum([]byte("abc")), reconnector.lastReaderChecksum)
+	})
+
+	t.Run("Diverged", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		conn1 := newMockConnection()
+		conn2 := newMockConnection()
+		reconnector := &checksumReconnector{
+			mockReconnector: mockReconnector{connections: []*mockConnection{conn1, conn2}},
+			// Same length as what was sent, but different content.
+			remoteRead: []byte("jello"),
+		}
+
+		bp := backedpipe.NewBackedPipe(ctx, reconnector)
+		defer bp.Close()
+
+		err := bp.Connect()
+		require.NoError(t, err)
+
+		_, err = bp.Write([]byte("hello world"))
+		require.NoError(t, err)
+
+		err = bp.ForceReconnect()
+		require.ErrorIs(t, err, backedpipe.ErrStreamDiverged)
+		require.False(t, bp.Connected())
+		require.Empty(t, conn2.ReadString())
+
+		info := bp.DebugInfo()
+		require.Contains(t, info.RecentReconnects[len(info.RecentReconnects)-1].Error, "checksum mismatch")
+	})
+
+	t.Run("DivergedOnAutoReconnect", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		conn1 := newMockConnection()
+		conn2 := newMockConnection()
+		reconnector := &checksumReconnector{
+			mockReconnector: mockReconnector{connections: []*mockConnection{conn1, conn2}},
+			remoteRead:      []byte("jello"),
+		}
+
+		bp := backedpipe.NewBackedPipe(ctx, reconnector)
+		defer bp.Close()
+
+		err := bp.Connect()
+		require.NoError(t, err)
+
+		_, err = bp.Write([]byte("hello world"))
+		require.NoError(t, err)
+
+		readErr := make(chan error, 1)
+		go func() {
+			_, err := bp.Read(make([]byte, 1))
+			readErr <- err
+		}()
+
+		// Losing the connection reconnects automatically, which diverges.
+		conn1.SetReadError(xerrors.New("connection lost"))
+
+		err = testutil.RequireReceive(ctx, t, readErr)
+		require.ErrorIs(t, err, backedpipe.ErrStreamDiverged)
+		require.False(t, bp.Connected())
+
+		_, err = bp.Read(make([]byte, 1))
+		require.ErrorIs(t, err, backedpipe.ErrStreamDiverged)
+		_, err = bp.Write([]byte("more"))
+		require.ErrorIs(t, err, backedpipe.ErrStreamDiverged)
+		err = bp.ForceReconnect()
+		require.ErrorIs(t, err, backedpipe.ErrStreamDiverged)
+		require.Empty(t, conn2.ReadString())
+	})
+}
+
+func TestBackedPipe_IdleTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	conn1 := newMockConnection()
+	conn2 := newMockConnection()
+	conn2.seqNum = 4
+	reconnector, _ := mockReconnectFunc(conn1, conn2)
+
+	bp := backedpipe.NewBackedPipe(ctx, reconnector)
+	defer bp.Close()
+
+	err := bp.Connect()
+	require.NoError(t, err)
+
+	_, err = bp.Write([]byte("data"))
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now(), bp.LastWrite(), testutil.WaitShort)
+
+	bp.SetIdleTimeout(50 * time.Millisecond)
+
+	// The connection is dropped once idle, without reconnecting.
+	require.Eventually(t, func() bool {
+		return !bp.Connected()
+	}, testutil.WaitShort, testutil.IntervalFast)
+	conn1.mu.Lock()
+	require.True(t, conn1.closed)
+	conn1.mu.Unlock()
+	require.Equal(t, 1, reconnector.GetCallCount())
+
+	info := bp.DebugInfo()
+	require.Equal(t, "disconnected", info.State)
+	require.GreaterOrEqual(t, info.IdleMS, int64(50))
+
+	// The stream is preserved and can be resumed by the remote side.
+	bp.SetIdleTimeout(0)
+	err = bp.ForceReconnect()
+	require.NoError(t, err)
+	require.True(t, bp.Connected())
+	require.Less(t, bp.IdleDuration(), testutil.WaitShort)
+}
+
 func TestBackedPipe_ForceReconnectWhenClosed(t *testing.T) {
 	t.Parallel()
 
@@ -3,6 +3,7 @@ package backedpipe
 import (
 	"io"
 	"sync"
+	"sync/atomic"
 )
 
 // BackedReader wraps an unreliable io.Reader and makes it resilient to disconnections.
@@ -15,6 +16,12 @@ type BackedReader struct {
 	sequenceNum uint64
 	closed      bool
 
+	// checksum is the running CRC-32C of all bytes read, tracked only when
+	// checksums are enabled. It is written with mu held but may be loaded
+	// without it, see Checksum.
+	checksums bool
+	checksum  atomic.Uint32
+
 	// Error channel for generation-aware error reporting
 	errorEventChan chan<- ErrorEvent
 
@@ -61,6 +68,9 @@ func (br *BackedReader) Read(p []byte) (int, error) {
 		// This ensures proper synchronization with Reconnect and Close operations
 		n, err := br.reader.Read(p)
 		br.sequenceNum += uint64(n) // #nosec G115 -- n is always >= 0 per io.Reader contract
+		if br.checksums && n > 0 {
+			br.checksum.Store(updateChecksum(br.checksum.Load(), p[:n]))
+		}
 
 		if err == nil {
 			return n, nil
@@ -151,6 +161,22 @@ func (br *BackedReader) SequenceNum() uint64 {
 	return br.sequenceNum
 }
 
+// EnableChecksums makes the reader track a running checksum of all bytes
+// read. It must be called before the first read.
+func (br *BackedReader) EnableChecksums() {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	br.checksums = true
+}
+
+// Checksum returns the running checksum of all bytes read so far. It does not
+// acquire the reader's lock so it can be called while Reconnect holds it, in
+// which case the value is consistent with the sequence number sent by
+// Reconnect.
+func (br *BackedReader) Checksum() uint32 {
+	return br.checksum.Load()
+}
+
 // Connected returns whether the reader is currently connected.
 func (br *BackedReader) Connected() bool {
 	br.mu.Lock()
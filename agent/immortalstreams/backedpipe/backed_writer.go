@@ -27,6 +27,11 @@ type BackedWriter struct {
 	sequenceNum uint64 // total bytes written
 	closed      bool
 
+	// baseChecksum is the CRC-32C of all bytes evicted from the buffer,
+	// tracked only when checksums are enabled.
+	checksums    bool
+	baseChecksum uint32
+
 	// Error channel for generation-aware error reporting
 	errorEventChan chan<- ErrorEvent
 
@@ -84,6 +89,9 @@ func (bw *BackedWriter) Write(p []byte) (int, error) {
 	}
 
 	// Write to buffer
+	if bw.checksums {
+		bw.trackEvictionLocked(p)
+	}
 	bw.buffer.Write(p)
 	bw.sequenceNum += uint64(len(p))
 
@@ -228,6 +236,66 @@ func (bw *BackedWriter) SequenceNum() uint64 {
 	return bw.sequenceNum
 }
 
+// EnableChecksums makes the writer track checksums so that ChecksumAt can
+// verify what the remote has read. It must be called before the first write.
+func (bw *BackedWriter) EnableChecksums() {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	bw.checksums = true
+}
+
+// ChecksumAt returns the checksum of the first seq bytes written. The bytes
+// after the last evicted byte must still be in the buffer.
+func (bw *BackedWriter) ChecksumAt(seq uint64) (uint32, error) {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+
+	if seq > bw.sequenceNum {
+		return 0, ErrFutureSequence
+	}
+	bufferStart := bw.sequenceNum - uint64(bw.buffer.Size())
+	if seq < bufferStart {
+		return 0, ErrReplayDataUnavailable
+	}
+	//nolint:gosec // Safe conversion: seq-bufferStart <= buffer size, which is an int
+	return bw.checksumFirstLocked(int(seq - bufferStart)), nil
+}
+
+// checksumFirstLocked returns baseChecksum extended with the first n bytes
+// of the buffer. It reads the buffer in place, since it may hold up to the
+// whole buffer capacity. Must be called with the mutex held.
+func (bw *BackedWriter) checksumFirstLocked(n int) uint32 {
+	first, second := bw.buffer.segments(0, n)
+	return updateChecksum(updateChecksum(bw.baseChecksum, first), second)
+}
+
+// trackEvictionLocked folds the bytes that writing p will evict from the
+// buffer into baseChecksum. Must be called with the mutex held, before p is
+// written to the buffer.
+func (bw *BackedWriter) trackEvictionLocked(p []byte) {
+	size := bw.buffer.Size()
+	overflow := size + len(p) - len(bw.buffer.buffer)
+	if overflow <= 0 {
+		return
+	}
+	bw.baseChecksum = bw.checksumFirstLocked(min(overflow, size))
+	if overflow > size {
+		bw.baseChecksum = updateChecksum(bw.baseChecksum, p[:overflow-size])
+	}
+}
+
+// BufferedBytes returns the number of bytes currently retained for replay.
+func (bw *BackedWriter) BufferedBytes() int {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	return bw.buffer.Size()
+}
+
+// BufferCapacity returns the maximum number of bytes retained for replay.
+func (bw *BackedWriter) BufferCapacity() int {
+	return len(bw.buffer.buffer)
+}
+
 // Connected returns whether the writer is currently connected.
 func (bw *BackedWriter) Connected() bool {
 	bw.mu.Lock()
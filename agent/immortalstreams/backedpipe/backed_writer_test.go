@@ -380,6 +380,50 @@ func TestBackedWriter_BufferEviction(t *testing.T) {
 	require.True(t, bw.Connected())
 }
 
+func TestBackedWriter_ChecksumAt(t *testing.T) {
+	t.Parallel()
+
+	bw := newBackedWriterForTest(5)
+	bw.EnableChecksums()
+
+	writer := newMockWriter()
+	err := bw.Reconnect(0, writer)
+	require.NoError(t, err)
+
+	_, err = bw.Write([]byte("abc"))
+	require.NoError(t, err)
+
+	sum, err := bw.ChecksumAt(0)
+	require.NoError(t, err)
+	require.Equal(t, backedpipe.Checksum(nil), sum)
+
+	sum, err = bw.ChecksumAt(2)
+	require.NoError(t, err)
+	require.Equal(t, backedpipe.Checksum([]byte("ab")), sum)
+
+	// Overflow the buffer, including a single write larger than it, so
+	// evicted bytes come from both the buffer and the write itself.
+	_, err = bw.Write([]byte("defg"))
+	require.NoError(t, err)
+	_, err = bw.Write([]byte("hijklmn"))
+	require.NoError(t, err)
+
+	// "jklmn" remains buffered, starting at sequence 9.
+	sum, err = bw.ChecksumAt(9)
+	require.NoError(t, err)
+	require.Equal(t, backedpipe.Checksum([]byte("abcdefghi")), sum)
+
+	sum, err = bw.ChecksumAt(12)
+	require.NoError(t, err)
+	require.Equal(t, backedpipe.Checksum([]byte("abcdefghijkl")), sum)
+
+	_, err = bw.ChecksumAt(8)
+	require.ErrorIs(t, err, backedpipe.ErrReplayDataUnavailable)
+
+	_, err = bw.ChecksumAt(15)
+	require.ErrorIs(t, err, backedpipe.ErrFutureSequence)
+}
+
 func TestBackedWriter_Close(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,146 @@
+// Package backedpipetest provides a deterministic in-memory implementation of
+// backedpipe.Pipe for unit tests that should not depend on real reconnection
+// timing.
+package backedpipetest
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/coder/coder/v2/agent/immortalstreams/backedpipe"
+)
+
+var _ backedpipe.Pipe = (*Pipe)(nil)
+
+// Pipe is a fake backedpipe.Pipe. Data written to the pipe is recorded and
+// can be inspected with Written, data to be read is supplied with Feed.
+// Like a BackedPipe, reads and writes block while the pipe is disconnected
+// and return io.EOF once it is closed.
+type Pipe struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	readBuf   bytes.Buffer
+	written   bytes.Buffer
+	connected bool
+	closed    bool
+
+	reconnectErr   error
+	reconnectCalls int
+}
+
+// New returns a fake pipe in the given connection state.
+func New(connected bool) *Pipe {
+	p := &Pipe{connected: connected}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// Read implements io.Reader. It blocks until data has been fed, or the pipe
+// is closed. Reads also block while the pipe is disconnected.
+func (p *Pipe) Read(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for !p.closed && (!p.connected || p.readBuf.Len() == 0) {
+		p.cond.Wait()
+	}
+	if p.closed {
+		return 0, io.EOF
+	}
+	return p.readBuf.Read(b)
+}
+
+// Write implements io.Writer. It blocks while the pipe is disconnected.
+func (p *Pipe) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for !p.closed && !p.connected {
+		p.cond.Wait()
+	}
+	if p.closed {
+		return 0, io.EOF
+	}
+	return p.written.Write(b)
+}
+
+// Close implements io.Closer and unblocks all pending reads and writes.
+func (p *Pipe) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+	p.connected = false
+	p.cond.Broadcast()
+	return nil
+}
+
+// Connected implements backedpipe.Pipe.
+func (p *Pipe) Connected() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.connected
+}
+
+// ForceReconnect implements backedpipe.Pipe. It connects the pipe unless an
+// error was configured with SetReconnectError, which is returned instead.
+func (p *Pipe) ForceReconnect() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.reconnectCalls++
+	if p.closed {
+		return io.EOF
+	}
+	if p.reconnectErr != nil {
+		return p.reconnectErr
+	}
+	p.connected = true
+	p.cond.Broadcast()
+	return nil
+}
+
+// Feed makes data available to Read, as if it had been received from the
+// remote side.
+func (p *Pipe) Feed(data []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, _ = p.readBuf.Write(data)
+	p.cond.Broadcast()
+}
+
+// Written returns a copy of all data written to the pipe.
+func (p *Pipe) Written() []byte {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return bytes.Clone(p.written.Bytes())
+}
+
+// SetConnected simulates the pipe connecting or disconnecting.
+func (p *Pipe) SetConnected(connected bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return
+	}
+	p.connected = connected
+	p.cond.Broadcast()
+}
+
+// SetReconnectError configures the error returned by ForceReconnect. A nil
+// error makes ForceReconnect succeed.
+func (p *Pipe) SetReconnectError(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reconnectErr = err
+}
+
+// ReconnectCalls returns the number of times ForceReconnect was called.
+func (p *Pipe) ReconnectCalls() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.reconnectCalls
+}
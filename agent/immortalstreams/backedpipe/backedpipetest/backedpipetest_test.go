@@ -0,0 +1,55 @@
+package backedpipetest_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/v2/agent/immortalstreams/backedpipe/backedpipetest"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestPipe(t *testing.T) {
+	t.Parallel()
+
+	p := backedpipetest.New(false)
+	require.False(t, p.Connected())
+
+	// Writes block until connected.
+	writeDone := make(chan error, 1)
+	go func() {
+		_, err := p.Write([]byte("hello"))
+		writeDone <- err
+	}()
+
+	p.SetReconnectError(xerrors.New("no route"))
+	require.Error(t, p.ForceReconnect())
+	require.False(t, p.Connected())
+
+	p.SetReconnectError(nil)
+	require.NoError(t, p.ForceReconnect())
+	require.True(t, p.Connected())
+	require.Equal(t, 2, p.ReconnectCalls())
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	require.NoError(t, testutil.RequireReceive(ctx, t, writeDone))
+	require.Equal(t, "hello", string(p.Written()))
+
+	p.Feed([]byte("world"))
+	buf := make([]byte, 5)
+	_, err := io.ReadFull(p, buf)
+	require.NoError(t, err)
+	require.Equal(t, "world", string(buf))
+
+	// Close unblocks pending reads.
+	readDone := make(chan error, 1)
+	go func() {
+		_, err := p.Read(buf)
+		readDone <- err
+	}()
+	require.NoError(t, p.Close())
+	require.ErrorIs(t, testutil.RequireReceive(ctx, t, readDone), io.EOF)
+	require.ErrorIs(t, p.ForceReconnect(), io.EOF)
+}
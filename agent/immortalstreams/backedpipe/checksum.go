@@ -0,0 +1,46 @@
+package backedpipe
+
+import (
+	"context"
+	"hash/crc32"
+	"io"
+
+	"golang.org/x/xerrors"
+)
+
+// ErrStreamDiverged is returned when the checksums exchanged during a
+// reconnect show that the two ends no longer agree on the bytes that were
+// transferred. Continuing would silently corrupt the stream, so the pipe is
+// closed and later reads and writes return this error too.
+var ErrStreamDiverged = xerrors.New("stream checksum mismatch: data diverged")
+
+// checksumTable is the CRC-32 table used for stream checksums. Castagnoli is
+// hardware accelerated on most platforms.
+var checksumTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ChecksumReconnector is an optional extension of Reconnector that also
+// exchanges running stream checksums with the remote side. When the
+// Reconnector passed to NewBackedPipe implements it, both the reader and
+// writer track a CRC-32C of every byte transferred and each reconnect
+// verifies that the remote's reader received exactly the bytes our writer
+// sent. Both ends perform the same check, so a divergence is reported on
+// both sides.
+type ChecksumReconnector interface {
+	Reconnector
+	// ReconnectWithChecksum behaves like Reconnect, additionally sending
+	// readerChecksum (the checksum of the first readerSeqNum bytes read
+	// locally) and returning the remote reader's checksum of the first
+	// remoteReaderSeqNum bytes it has read.
+	ReconnectWithChecksum(ctx context.Context, readerSeqNum uint64, readerChecksum uint32) (conn io.ReadWriteCloser, remoteReaderSeqNum uint64, remoteReaderChecksum uint32, err error)
+}
+
+// Checksum returns the CRC-32C checksum of data, as computed for stream
+// integrity checks. It is exported so Reconnector implementations and tests
+// can compute expected values.
+func Checksum(data []byte) uint32 {
+	return crc32.Checksum(data, checksumTable)
+}
+
+func updateChecksum(crc uint32, data []byte) uint32 {
+	return crc32.Update(crc, checksumTable, data)
+}
@@ -0,0 +1,71 @@
+package backedpipe
+
+import (
+	"net"
+	"os"
+	"time"
+)
+
+// pipeAddr is the net.Addr reported by Conn when no address is provided.
+type pipeAddr struct{}
+
+func (pipeAddr) Network() string { return "backedpipe" }
+func (pipeAddr) String() string  { return "backedpipe" }
+
+// Conn adapts a Pipe to net.Conn so that existing connection-oriented
+// servers, such as the reconnecting PTY server, can be carried over a backed
+// pipe and inherit its buffering and replay semantics instead of
+// implementing their own.
+//
+// Deadlines are not supported since reads and writes on a Pipe are
+// expected to block across reconnections; the deadline setters return
+// os.ErrNoDeadline. The reconnecting PTY does not set deadlines on the
+// connections it attaches.
+type Conn struct {
+	Pipe
+	localAddr  net.Addr
+	remoteAddr net.Addr
+}
+
+var _ net.Conn = (*Conn)(nil)
+
+// NewConn returns a net.Conn backed by p. Closing the Conn closes the pipe.
+// Nil addresses are replaced with a placeholder address.
+func NewConn(p Pipe, localAddr, remoteAddr net.Addr) *Conn {
+	if localAddr == nil {
+		localAddr = pipeAddr{}
+	}
+	if remoteAddr == nil {
+		remoteAddr = pipeAddr{}
+	}
+	return &Conn{
+		Pipe:       p,
+		localAddr:  localAddr,
+		remoteAddr: remoteAddr,
+	}
+}
+
+// LocalAddr implements net.Conn.
+func (c *Conn) LocalAddr() net.Addr {
+	return c.localAddr
+}
+
+// RemoteAddr implements net.Conn.
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.remoteAddr
+}
+
+// SetDeadline implements net.Conn. Deadlines are not supported.
+func (*Conn) SetDeadline(time.Time) error {
+	return os.ErrNoDeadline
+}
+
+// SetReadDeadline implements net.Conn. Deadlines are not supported.
+func (*Conn) SetReadDeadline(time.Time) error {
+	return os.ErrNoDeadline
+}
+
+// SetWriteDeadline implements net.Conn. Deadlines are not supported.
+func (*Conn) SetWriteDeadline(time.Time) error {
+	return os.ErrNoDeadline
+}
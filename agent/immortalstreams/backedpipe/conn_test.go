@@ -0,0 +1,159 @@
+package backedpipe_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/v2/agent/agentexec"
+	"github.com/coder/coder/v2/agent/immortalstreams/backedpipe"
+	"github.com/coder/coder/v2/agent/immortalstreams/backedpipe/backedpipetest"
+	"github.com/coder/coder/v2/agent/immortalstreams/immortalstreamstest"
+	"github.com/coder/coder/v2/agent/reconnectingpty"
+	"github.com/coder/coder/v2/codersdk/workspacesdk"
+	"github.com/coder/coder/v2/pty"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestConn(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	conn1 := newMockConnection()
+	conn2 := newMockConnection()
+	conn2.seqNum = 5
+	reconnector, _ := mockReconnectFunc(conn1, conn2)
+
+	bp := backedpipe.NewBackedPipe(ctx, reconnector)
+	remote := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1234}
+	var c net.Conn = backedpipe.NewConn(bp, nil, remote)
+	defer c.Close()
+
+	require.Equal(t, "backedpipe", c.LocalAddr().Network())
+	require.Equal(t, remote, c.RemoteAddr())
+	require.ErrorIs(t, c.SetDeadline(time.Now()), os.ErrNoDeadline)
+	require.ErrorIs(t, c.SetReadDeadline(time.Now()), os.ErrNoDeadline)
+	require.ErrorIs(t, c.SetWriteDeadline(time.Now()), os.ErrNoDeadline)
+
+	require.NoError(t, bp.Connect())
+
+	// Writes made through the conn are replayed after a reconnect.
+	_, err := c.Write([]byte("hello world"))
+	require.NoError(t, err)
+	require.NoError(t, bp.ForceReconnect())
+	require.Equal(t, " world", conn2.ReadString())
+
+	conn2.WriteString("output")
+	buf := make([]byte, 6)
+	_, err = io.ReadFull(c, buf)
+	require.NoError(t, err)
+	require.Equal(t, "output", string(buf))
+
+	require.NoError(t, c.Close())
+	_, err = c.Write([]byte("closed"))
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestConn_FakePipe(t *testing.T) {
+	t.Parallel()
+
+	p := backedpipetest.New(true)
+	var c net.Conn = backedpipe.NewConn(p, nil, nil)
+
+	_, err := c.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(p.Written()))
+
+	p.Feed([]byte("world"))
+	buf := make([]byte, 5)
+	_, err = io.ReadFull(c, buf)
+	require.NoError(t, err)
+	require.Equal(t, "world", string(buf))
+
+	require.NoError(t, c.Close())
+	_, err = c.Write([]byte("closed"))
+	require.Error(t, err)
+}
+
+func TestConn_ReconnectingPTY(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("bash is not available on Windows")
+	}
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	logger := slogtest.Make(t, &slogtest.Options{IgnoreErrors: true})
+	n := immortalstreamstest.NewNetwork(immortalstreamstest.Options{})
+	client, server := immortalstreamstest.NewPipePair(ctx, t, n)
+
+	// The agent attaches a reconnecting PTY to its end of the pipe, as
+	// reconnectingpty.Server does with its connections. The PTY never sets
+	// deadlines on the conn.
+	rpty := reconnectingpty.New(ctx, logger, agentexec.DefaultExecer, pty.Command("bash", "--norc"), &reconnectingpty.Options{
+		Metrics:     prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test"}, []string{"type"}),
+		BackendType: "buffered",
+	})
+	t.Cleanup(func() {
+		rpty.Close(nil)
+		rpty.Wait()
+	})
+	attachDone := make(chan error, 1)
+	go func() {
+		attachDone <- rpty.Attach(ctx, "conn", backedpipe.NewConn(server, nil, nil), 80, 80, logger)
+	}()
+
+	conn := backedpipe.NewConn(client, nil, nil)
+	tr := testutil.NewTerminalReader(t, conn)
+	send := func(data string) <-chan error {
+		errc := make(chan error, 1)
+		go func() {
+			errc <- json.NewEncoder(conn).Encode(workspacesdk.ReconnectingPTYRequest{Data: data})
+		}()
+		return errc
+	}
+	matchOutput := func(want string) func(line string) bool {
+		return func(line string) bool {
+			return strings.Contains(line, want) && !strings.Contains(line, "echo")
+		}
+	}
+
+	require.NoError(t, tr.ReadUntil(ctx, func(line string) bool {
+		return strings.Contains(line, "$ ") || strings.Contains(line, "# ")
+	}), "find prompt")
+	require.NoError(t, testutil.RequireReceive(ctx, t, send("echo before\r")))
+	require.NoError(t, tr.ReadUntil(ctx, matchOutput("before")), "find output before partition")
+
+	// Input typed during a partition is delivered once the client
+	// reconnects, without reattaching to the PTY.
+	n.Partition()
+	require.ErrorIs(t, client.ForceReconnect(), backedpipe.ErrReconnectFailed)
+	sent := send("echo during\r")
+	n.Heal()
+	require.NoError(t, client.ForceReconnect())
+	require.NoError(t, testutil.RequireReceive(ctx, t, sent))
+	require.NoError(t, tr.ReadUntil(ctx, matchOutput("during")), "find output after partition")
+
+	// Output lost in flight is replayed by the pipe rather than by the PTY.
+	generation := client.DebugInfo().Generation
+	n.DropAt(immortalstreamstest.ServerToClient, n.BytesSent(immortalstreamstest.ServerToClient)+4)
+	require.NoError(t, testutil.RequireReceive(ctx, t, send("echo after\r")))
+	require.NoError(t, tr.ReadUntil(ctx, matchOutput("after")), "find output after drop")
+	require.Greater(t, client.DebugInfo().Generation, generation)
+
+	select {
+	case err := <-attachDone:
+		t.Fatalf("attach ended early: %v", err)
+	default:
+	}
+}
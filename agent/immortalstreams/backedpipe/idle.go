@@ -0,0 +1,101 @@
+package backedpipe
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// activity tracks when data last moved through the pipe in each direction.
+// Timestamps are stored as Unix nanoseconds so they can be updated from the
+// read and write paths without taking the pipe's lock.
+type activity struct {
+	lastRead    atomic.Int64
+	lastWrite   atomic.Int64
+	lastConnect atomic.Int64
+}
+
+func newActivity(now time.Time) *activity {
+	a := &activity{}
+	a.lastRead.Store(now.UnixNano())
+	a.lastWrite.Store(now.UnixNano())
+	a.lastConnect.Store(now.UnixNano())
+	return a
+}
+
+// idleSince returns the most recent of the last read, last write and last
+// connect times.
+func (a *activity) idleSince() time.Time {
+	latest := max(a.lastRead.Load(), a.lastWrite.Load(), a.lastConnect.Load())
+	return time.Unix(0, latest)
+}
+
+// LastRead returns when data was last read from the pipe.
+func (bp *BackedPipe) LastRead() time.Time {
+	return time.Unix(0, bp.activity.lastRead.Load())
+}
+
+// LastWrite returns when data was last written to the pipe.
+func (bp *BackedPipe) LastWrite() time.Time {
+	return time.Unix(0, bp.activity.lastWrite.Load())
+}
+
+// IdleDuration returns how long it has been since data last moved through the
+// pipe in either direction, or since the pipe last connected if that is more
+// recent.
+func (bp *BackedPipe) IdleDuration() time.Duration {
+	return time.Since(bp.activity.idleSince())
+}
+
+// SetIdleTimeout configures the pipe to drop its connection after no data
+// has been transferred in either direction for the given duration. Unlike
+// Close, the pipe and its replay buffer are preserved and no reconnection is
+// attempted automatically, so the remote side can resume later via Connect or
+// ForceReconnect. A timeout <= 0 disables idle disconnection.
+func (bp *BackedPipe) SetIdleTimeout(timeout time.Duration) {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+
+	if bp.idleTimer != nil {
+		bp.idleTimer.Stop()
+		bp.idleTimer = nil
+	}
+	bp.idleTimeout = timeout
+	if timeout <= 0 || bp.state == closed {
+		return
+	}
+	bp.idleTimer = time.AfterFunc(timeout, bp.checkIdle)
+}
+
+// checkIdle disconnects the pipe if it has been idle for longer than the idle
+// timeout and reschedules itself.
+func (bp *BackedPipe) checkIdle() {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+
+	if bp.state == closed || bp.idleTimeout <= 0 || bp.idleTimer == nil {
+		return
+	}
+
+	idle := time.Since(bp.activity.idleSince())
+	if idle < bp.idleTimeout {
+		bp.idleTimer.Reset(bp.idleTimeout - idle)
+		return
+	}
+
+	if bp.state == connected {
+		bp.disconnectLocked()
+	}
+	bp.idleTimer.Reset(bp.idleTimeout)
+}
+
+// disconnectLocked drops the current connection without triggering a
+// reconnection. Errors reported by the reader and writer for the dropped
+// connection are ignored since the pipe is no longer in the connected state.
+// Must be called with write lock held.
+func (bp *BackedPipe) disconnectLocked() {
+	bp.state = disconnected
+	if bp.conn != nil {
+		_ = bp.conn.Close()
+		bp.conn = nil
+	}
+}
@@ -107,23 +107,36 @@ func (rb *ringBuffer) ReadLast(n int) ([]byte, error) {
 		return nil, xerrors.Errorf("requested %d bytes but only %d available", n, size)
 	}
 
+	// Read n bytes starting n bytes before the end
+	return rb.readAt(size-n, n), nil
+}
+
+// readAt copies n bytes starting at the given offset from the first valid
+// byte. The caller must ensure offset+n does not exceed Size().
+func (rb *ringBuffer) readAt(offset, n int) []byte {
 	result := make([]byte, n)
-	capacity := len(rb.buffer)
+	first, second := rb.segments(offset, n)
+	copy(result, first)
+	copy(result[len(first):], second)
+	return result
+}
 
-	// Calculate where to start reading from (n bytes before the end)
-	startOffset := size - n
-	actualStart := (rb.start + startOffset) % capacity
+// segments returns the n bytes starting at the given offset from the first
+// valid byte as up to two slices of the underlying buffer, without copying.
+// The slices are only valid until the next Write. The caller must ensure
+// offset+n does not exceed Size().
+func (rb *ringBuffer) segments(offset, n int) (first, second []byte) {
+	if n == 0 {
+		return nil, nil
+	}
+	capacity := len(rb.buffer)
+	actualStart := (rb.start + offset) % capacity
 
-	// Copy the last n bytes
 	if actualStart+n <= capacity {
 		// No wrap needed
-		copy(result, rb.buffer[actualStart:actualStart+n])
-	} else {
-		// Need to wrap around
-		firstChunk := capacity - actualStart
-		copy(result[0:firstChunk], rb.buffer[actualStart:capacity])
-		copy(result[firstChunk:], rb.buffer[0:n-firstChunk])
+		return rb.buffer[actualStart : actualStart+n], nil
 	}
-
-	return result, nil
+	// Need to wrap around
+	firstChunk := capacity - actualStart
+	return rb.buffer[actualStart:capacity], rb.buffer[0 : n-firstChunk]
 }
@@ -148,6 +148,32 @@ func TestRingBuffer_ReadLastEdgeCases(t *testing.T) {
 	require.Equal(t, []byte("llo"), data)
 }
 
+func TestRingBuffer_Segments(t *testing.T) {
+	t.Parallel()
+
+	rb := newRingBuffer(5)
+	rb.Write([]byte("abc"))
+
+	first, second := rb.segments(0, 2)
+	require.Equal(t, "ab", string(first))
+	require.Empty(t, second)
+
+	// Wrap around so the oldest bytes are at the end of the backing array
+	rb.Write([]byte("defg"))
+
+	first, second = rb.segments(0, 5)
+	require.Equal(t, "cde", string(first))
+	require.Equal(t, "fg", string(second))
+
+	first, second = rb.segments(1, 3)
+	require.Equal(t, "de", string(first))
+	require.Equal(t, "f", string(second))
+
+	first, second = rb.segments(0, 0)
+	require.Empty(t, first)
+	require.Empty(t, second)
+}
+
 func TestRingBuffer_EmptyWrite(t *testing.T) {
 	t.Parallel()
 
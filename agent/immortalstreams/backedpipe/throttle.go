@@ -0,0 +1,83 @@
+package backedpipe
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimit configures the maximum throughput of a BackedPipe in each
+// direction. A zero value disables throttling for that direction.
+type RateLimit struct {
+	// ReadBytesPerSecond limits the rate at which data is read from the
+	// remote side.
+	ReadBytesPerSecond int64 `json:"read_bytes_per_second"`
+	// WriteBytesPerSecond limits the rate at which data is written to the
+	// remote side.
+	WriteBytesPerSecond int64 `json:"write_bytes_per_second"`
+}
+
+// throttle is a byte-oriented token bucket whose limit can be adjusted while
+// readers and writers are waiting on it. It is unlimited by default.
+type throttle struct {
+	limiter *rate.Limiter
+}
+
+func newThrottle() *throttle {
+	return &throttle{
+		limiter: rate.NewLimiter(rate.Inf, 0),
+	}
+}
+
+// setLimit updates the limit in bytes per second. The burst allows up to one
+// second worth of data to be transferred at once. A limit <= 0 disables
+// throttling.
+func (t *throttle) setLimit(bytesPerSecond int64) {
+	if bytesPerSecond <= 0 {
+		t.limiter.SetLimit(rate.Inf)
+		t.limiter.SetBurst(0)
+		return
+	}
+	burst := bytesPerSecond
+	if burst > int64(maxBurst) {
+		burst = int64(maxBurst)
+	}
+	t.limiter.SetLimit(rate.Limit(bytesPerSecond))
+	t.limiter.SetBurst(int(burst))
+}
+
+// limit returns the current limit in bytes per second, or 0 if unlimited.
+func (t *throttle) limit() int64 {
+	l := t.limiter.Limit()
+	if l == rate.Inf {
+		return 0
+	}
+	return int64(l)
+}
+
+// wait blocks until n bytes may be transferred or the context is canceled.
+// Requests larger than the burst are split so they never fail outright.
+func (t *throttle) wait(ctx context.Context, n int) error {
+	for n > 0 {
+		step := n
+		if t.limiter.Limit() != rate.Inf {
+			if burst := t.limiter.Burst(); burst > 0 && step > burst {
+				step = burst
+			}
+		}
+		if err := t.limiter.WaitN(ctx, step); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// The limit changed concurrently and the step no longer fits
+			// in the burst, recompute it.
+			continue
+		}
+		n -= step
+	}
+	return nil
+}
+
+// maxBurst caps the token bucket size so that very large limits don't
+// overflow int on 32-bit platforms.
+const maxBurst = 1 << 30
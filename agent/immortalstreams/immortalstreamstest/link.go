@@ -0,0 +1,158 @@
+package immortalstreamstest
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// chunk is a piece of data in flight, readable once its delivery time has
+// passed.
+type chunk struct {
+	data []byte
+	at   time.Time
+}
+
+// half is one direction of a link.
+type half struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	chunks []chunk
+	closed bool
+
+	// Reorder state, guarded by Network.mu.
+	held         []byte
+	holdNeed     int
+	releaseAfter int
+}
+
+func newHalf() *half {
+	h := &half{}
+	h.cond = sync.NewCond(&h.mu)
+	return h
+}
+
+func (h *half) push(data []byte, latency time.Duration) {
+	if len(data) == 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.chunks = append(h.chunks, chunk{data: data, at: time.Now().Add(latency)})
+	h.cond.Broadcast()
+	if latency > 0 {
+		time.AfterFunc(latency, h.wake)
+	}
+}
+
+func (h *half) wake() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.cond.Broadcast()
+}
+
+// read blocks until data is deliverable or the half is closed. Data still in
+// flight when the half is closed is lost.
+func (h *half) read(p []byte) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for {
+		if h.closed {
+			return 0, io.ErrClosedPipe
+		}
+		if len(h.chunks) > 0 && !time.Now().Before(h.chunks[0].at) {
+			break
+		}
+		h.cond.Wait()
+	}
+
+	n := copy(p, h.chunks[0].data)
+	if n == len(h.chunks[0].data) {
+		h.chunks = h.chunks[1:]
+	} else {
+		h.chunks[0].data = h.chunks[0].data[n:]
+	}
+	return n, nil
+}
+
+func (h *half) isClosed() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.closed
+}
+
+func (h *half) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	h.chunks = nil
+	h.cond.Broadcast()
+}
+
+// link is a single simulated connection between the client and the server.
+type link struct {
+	n      *Network
+	halves [2]*half
+	client *Conn
+	server *Conn
+}
+
+func newLink(n *Network) *link {
+	l := &link{
+		n:      n,
+		halves: [2]*half{newHalf(), newHalf()},
+	}
+	l.client = &Conn{link: l, out: ClientToServer, in: ServerToClient}
+	l.server = &Conn{link: l, out: ServerToClient, in: ClientToServer}
+	return l
+}
+
+func (l *link) half(dir Direction) *half {
+	return l.halves[dir]
+}
+
+func (l *link) close() {
+	for _, h := range l.halves {
+		h.close()
+	}
+}
+
+// Conn is one end of a simulated link. It is returned by the reconnectors
+// and satisfies io.ReadWriteCloser.
+type Conn struct {
+	link *link
+	out  Direction
+	in   Direction
+}
+
+// Read implements io.Reader.
+func (c *Conn) Read(p []byte) (int, error) {
+	return c.link.half(c.in).read(p)
+}
+
+// Write implements io.Writer, applying any scripted faults. If a drop is
+// triggered the write is truncated at the drop offset and the link is
+// broken.
+func (c *Conn) Write(p []byte) (int, error) {
+	h := c.link.half(c.out)
+	if h.isClosed() {
+		return 0, io.ErrClosedPipe
+	}
+	deliver, accepted, drop := c.link.n.send(c.link, c.out, p)
+	h.push(deliver, c.link.n.opts.Latency)
+	if drop {
+		c.link.close()
+		return accepted, io.ErrClosedPipe
+	}
+	return len(p), nil
+}
+
+// Close breaks the link in both directions.
+func (c *Conn) Close() error {
+	c.link.close()
+	return nil
+}
@@ -0,0 +1,391 @@
+// Package immortalstreamstest provides a simulated network for driving
+// immortal stream components, such as backedpipe.BackedPipe, through
+// scripted failure scenarios. Faults are triggered at defined byte offsets
+// rather than after sleeps, so replay regressions are caught
+// deterministically.
+package immortalstreamstest
+
+import (
+	"context"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/v2/agent/immortalstreams/backedpipe"
+)
+
+var (
+	// ErrPartitioned is returned by the client reconnector while the network
+	// is partitioned.
+	ErrPartitioned = xerrors.New("network is partitioned")
+	// ErrNetworkClosed is returned by reconnectors once the network is
+	// closed.
+	ErrNetworkClosed = xerrors.New("network is closed")
+)
+
+// Direction identifies one half of the simulated link.
+type Direction int
+
+const (
+	// ClientToServer carries data written by the client pipe.
+	ClientToServer Direction = iota
+	// ServerToClient carries data written by the server pipe.
+	ServerToClient
+)
+
+func (d Direction) String() string {
+	switch d {
+	case ClientToServer:
+		return "client-to-server"
+	case ServerToClient:
+		return "server-to-client"
+	default:
+		return "unknown"
+	}
+}
+
+// Options configures a Network.
+type Options struct {
+	// Latency delays the delivery of every write by the given duration.
+	Latency time.Duration
+	// Checksums makes the reconnectors implement
+	// backedpipe.ChecksumReconnector so that pipes verify stream integrity
+	// on every reconnect.
+	Checksums bool
+}
+
+// fault is a scripted event triggered when the cumulative number of bytes
+// sent in a direction reaches offset.
+type fault struct {
+	offset uint64
+	// reorder is the length of the chunk to deliver out of order, or 0 for
+	// a drop.
+	reorder int
+}
+
+// dialRequest is sent from the client reconnector to the server
+// reconnector to perform the sequence number handshake.
+type dialRequest struct {
+	conn           *Conn
+	readerSeqNum   uint64
+	readerChecksum uint32
+	resp           chan handshake
+}
+
+type handshake struct {
+	readerSeqNum   uint64
+	readerChecksum uint32
+}
+
+// Network simulates the unreliable transport between a client and a server
+// pipe. Every reconnect by the client creates a new link; faults apply to
+// the link that is current when they trigger.
+//
+// Byte offsets count all bytes accepted by the network in a direction,
+// across all links, including bytes replayed after a reconnect.
+type Network struct {
+	opts     Options
+	incoming chan dialRequest
+	closed   chan struct{}
+
+	mu          sync.Mutex
+	link        *link
+	partitioned bool
+	sent        [2]uint64
+	faults      [2][]fault
+	closeOnce   sync.Once
+}
+
+// NewNetwork creates a simulated network with the given options.
+func NewNetwork(opts Options) *Network {
+	return &Network{
+		opts:     opts,
+		incoming: make(chan dialRequest),
+		closed:   make(chan struct{}),
+	}
+}
+
+// ClientReconnector returns the reconnector for the client pipe. Each call to
+// Reconnect dials a new link, replacing the current one.
+func (n *Network) ClientReconnector() backedpipe.Reconnector {
+	if n.opts.Checksums {
+		return &checksumClientReconnector{clientReconnector{n}}
+	}
+	return &clientReconnector{n}
+}
+
+// ServerReconnector returns the reconnector for the server pipe. Reconnect
+// blocks until the client dials or the context is canceled.
+//
+// Like any BackedPipe, the server only notices that its link was replaced
+// when it reads from or writes to it, so a client dial completes only once
+// the server has a read or write in progress. Scenarios should keep a read
+// pending on the server, as the stream copy loops do.
+func (n *Network) ServerReconnector() backedpipe.Reconnector {
+	if n.opts.Checksums {
+		return &checksumServerReconnector{serverReconnector{n}}
+	}
+	return &serverReconnector{n}
+}
+
+// DropAt breaks the current link once offset bytes have been sent in the
+// given direction. The triggering write is truncated at the offset and all
+// data in flight on the link is lost.
+func (n *Network) DropAt(dir Direction, offset uint64) {
+	n.addFault(dir, fault{offset: offset})
+}
+
+// ReorderAt delivers the length bytes starting at offset in the given
+// direction after the length bytes that follow them, simulating a transport
+// bug that reorders data. The pipes cannot detect this until their next
+// reconnect, and only with Options.Checksums set. The test fails if length
+// is not positive.
+func (n *Network) ReorderAt(t testing.TB, dir Direction, offset uint64, length int) {
+	t.Helper()
+	if length <= 0 {
+		t.Fatalf("reorder length must be > 0, got %d", length)
+	}
+	n.addFault(dir, fault{offset: offset, reorder: length})
+}
+
+func (n *Network) addFault(dir Direction, f fault) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.faults[dir] = append(n.faults[dir], f)
+	sort.SliceStable(n.faults[dir], func(i, j int) bool {
+		return n.faults[dir][i].offset < n.faults[dir][j].offset
+	})
+}
+
+// Disconnect breaks the current link. Data in flight is lost.
+func (n *Network) Disconnect() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.link != nil {
+		n.link.close()
+	}
+}
+
+// Partition breaks the current link and makes client dials fail until Heal
+// is called.
+func (n *Network) Partition() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.partitioned = true
+	if n.link != nil {
+		n.link.close()
+	}
+}
+
+// Heal ends a partition. Pipes must be reconnected explicitly, e.g. with
+// ForceReconnect on the client pipe.
+func (n *Network) Heal() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.partitioned = false
+}
+
+// BytesSent returns the number of bytes accepted by the network in the given
+// direction across all links.
+func (n *Network) BytesSent(dir Direction) uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.sent[dir]
+}
+
+// Close breaks the current link and fails all pending and future dials.
+func (n *Network) Close() error {
+	n.closeOnce.Do(func() {
+		close(n.closed)
+	})
+	n.Disconnect()
+	return nil
+}
+
+// dial creates a new link, replacing the current one, and performs the
+// handshake with the server reconnector.
+func (n *Network) dial(ctx context.Context, readerSeqNum uint64, readerChecksum uint32) (*Conn, handshake, error) {
+	n.mu.Lock()
+	if n.partitioned {
+		n.mu.Unlock()
+		return nil, handshake{}, ErrPartitioned
+	}
+	if n.link != nil {
+		n.link.close()
+	}
+	l := newLink(n)
+	n.link = l
+	n.mu.Unlock()
+
+	req := dialRequest{
+		conn:           l.server,
+		readerSeqNum:   readerSeqNum,
+		readerChecksum: readerChecksum,
+		resp:           make(chan handshake, 1),
+	}
+	select {
+	case n.incoming <- req:
+	case <-ctx.Done():
+		l.close()
+		return nil, handshake{}, ctx.Err()
+	case <-n.closed:
+		l.close()
+		return nil, handshake{}, ErrNetworkClosed
+	}
+
+	select {
+	case hs := <-req.resp:
+		return l.client, hs, nil
+	case <-ctx.Done():
+		l.close()
+		return nil, handshake{}, ctx.Err()
+	case <-n.closed:
+		l.close()
+		return nil, handshake{}, ErrNetworkClosed
+	}
+}
+
+// accept waits for the client to dial and completes the handshake.
+func (n *Network) accept(ctx context.Context, readerSeqNum uint64, readerChecksum uint32) (dialRequest, error) {
+	select {
+	case req := <-n.incoming:
+		req.resp <- handshake{readerSeqNum: readerSeqNum, readerChecksum: readerChecksum}
+		return req, nil
+	case <-ctx.Done():
+		return dialRequest{}, ctx.Err()
+	case <-n.closed:
+		return dialRequest{}, ErrNetworkClosed
+	}
+}
+
+// send applies scripted faults to data written in the given direction on l.
+// It returns the bytes to deliver now, the number of bytes of data accepted
+// and whether the link must be dropped after delivery.
+func (n *Network) send(l *link, dir Direction, data []byte) (deliver []byte, accepted int, drop bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	h := l.half(dir)
+	start := n.sent[dir]
+
+	// A drop truncates the write at its offset.
+	for i, f := range n.faults[dir] {
+		if f.reorder != 0 || f.offset >= start+uint64(len(data)) {
+			continue
+		}
+		cut := 0
+		if f.offset > start {
+			//nolint:gosec // Safe conversion: f.offset-start < len(data)
+			cut = int(f.offset - start)
+		}
+		data = data[:cut]
+		drop = true
+		n.faults[dir] = append(n.faults[dir][:i:i], n.faults[dir][i+1:]...)
+		break
+	}
+	accepted = len(data)
+
+	off := start
+	for len(data) > 0 {
+		switch {
+		case h.holdNeed > 0:
+			k := min(h.holdNeed, len(data))
+			h.held = append(h.held, data[:k]...)
+			h.holdNeed -= k
+			data = data[k:]
+			off += uint64(k)
+		case h.releaseAfter > 0:
+			k := min(h.releaseAfter, len(data))
+			deliver = append(deliver, data[:k]...)
+			h.releaseAfter -= k
+			data = data[k:]
+			off += uint64(k)
+			if h.releaseAfter == 0 {
+				deliver = append(deliver, h.held...)
+				h.held = nil
+			}
+		default:
+			idx := -1
+			for i, f := range n.faults[dir] {
+				if f.reorder != 0 && f.offset < off+uint64(len(data)) {
+					idx = i
+					break
+				}
+			}
+			if idx == -1 {
+				deliver = append(deliver, data...)
+				off += uint64(len(data))
+				data = nil
+				continue
+			}
+			f := n.faults[dir][idx]
+			n.faults[dir] = append(n.faults[dir][:idx:idx], n.faults[dir][idx+1:]...)
+			k := 0
+			if f.offset > off {
+				//nolint:gosec // Safe conversion: f.offset-off < len(data)
+				k = int(f.offset - off)
+			}
+			deliver = append(deliver, data[:k]...)
+			data = data[k:]
+			off += uint64(k)
+			h.holdNeed = f.reorder
+			h.releaseAfter = f.reorder
+		}
+	}
+
+	//nolint:gosec // Safe conversion: accepted is a non-negative length
+	n.sent[dir] += uint64(accepted)
+	return deliver, accepted, drop
+}
+
+type clientReconnector struct {
+	n *Network
+}
+
+func (c *clientReconnector) Reconnect(ctx context.Context, readerSeqNum uint64) (io.ReadWriteCloser, uint64, error) {
+	conn, hs, err := c.n.dial(ctx, readerSeqNum, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	return conn, hs.readerSeqNum, nil
+}
+
+type checksumClientReconnector struct {
+	clientReconnector
+}
+
+func (c *checksumClientReconnector) ReconnectWithChecksum(ctx context.Context, readerSeqNum uint64, readerChecksum uint32) (io.ReadWriteCloser, uint64, uint32, error) {
+	conn, hs, err := c.n.dial(ctx, readerSeqNum, readerChecksum)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return conn, hs.readerSeqNum, hs.readerChecksum, nil
+}
+
+type serverReconnector struct {
+	n *Network
+}
+
+func (s *serverReconnector) Reconnect(ctx context.Context, readerSeqNum uint64) (io.ReadWriteCloser, uint64, error) {
+	req, err := s.n.accept(ctx, readerSeqNum, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	return req.conn, req.readerSeqNum, nil
+}
+
+type checksumServerReconnector struct {
+	serverReconnector
+}
+
+func (s *checksumServerReconnector) ReconnectWithChecksum(ctx context.Context, readerSeqNum uint64, readerChecksum uint32) (io.ReadWriteCloser, uint64, uint32, error) {
+	req, err := s.n.accept(ctx, readerSeqNum, readerChecksum)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return req.conn, req.readerSeqNum, req.readerChecksum, nil
+}
@@ -0,0 +1,139 @@
+package immortalstreamstest_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/agent/immortalstreams/backedpipe"
+	"github.com/coder/coder/v2/agent/immortalstreams/immortalstreamstest"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func payload(n int) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	return data
+}
+
+func TestNetwork_Transfer(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Context(t, testutil.WaitMedium)
+	n := immortalstreamstest.NewNetwork(immortalstreamstest.Options{
+		Latency: 5 * time.Millisecond,
+	})
+	client, server := immortalstreamstest.NewPipePair(ctx, t, n)
+
+	immortalstreamstest.RequireTransfer(ctx, t, client, server, []byte("ping"))
+	immortalstreamstest.RequireTransfer(ctx, t, server, client, []byte("pong"))
+	require.Equal(t, uint64(4), n.BytesSent(immortalstreamstest.ClientToServer))
+	require.Equal(t, uint64(4), n.BytesSent(immortalstreamstest.ServerToClient))
+}
+
+func TestNetwork_DropAt(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Context(t, testutil.WaitMedium)
+	n := immortalstreamstest.NewNetwork(immortalstreamstest.Options{})
+	client, server := immortalstreamstest.NewPipePair(ctx, t, n)
+
+	// Break the link in the middle of a write in each direction; the pipes
+	// reconnect on their own and replay the lost bytes.
+	n.DropAt(immortalstreamstest.ClientToServer, 1000)
+	n.DropAt(immortalstreamstest.ServerToClient, 10)
+
+	data := payload(4096)
+	immortalstreamstest.RequireTransfer(ctx, t, client, server, data)
+	immortalstreamstest.RequireTransfer(ctx, t, server, client, data)
+
+	require.Eventually(t, func() bool {
+		return client.Connected() && server.Connected()
+	}, testutil.WaitShort, testutil.IntervalFast)
+	require.Greater(t, client.DebugInfo().Generation, uint64(1))
+}
+
+func TestNetwork_Partition(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Context(t, testutil.WaitMedium)
+	n := immortalstreamstest.NewNetwork(immortalstreamstest.Options{})
+	client, server := immortalstreamstest.NewPipePair(ctx, t, n)
+
+	immortalstreamstest.RequireTransfer(ctx, t, client, server, []byte("before"))
+
+	// Keep a read pending on the server so it notices the partition.
+	got := make([]byte, len("during"))
+	readDone := make(chan error, 1)
+	go func() {
+		_, err := io.ReadFull(server, got)
+		readDone <- err
+	}()
+
+	n.Partition()
+	require.ErrorIs(t, client.ForceReconnect(), backedpipe.ErrReconnectFailed)
+	require.False(t, client.Connected())
+
+	// Data written during the partition is delivered after healing.
+	written := make(chan error, 1)
+	go func() {
+		_, err := client.Write([]byte("during"))
+		written <- err
+	}()
+
+	n.Heal()
+	require.NoError(t, client.ForceReconnect())
+	require.NoError(t, testutil.RequireReceive(ctx, t, written))
+	require.NoError(t, testutil.RequireReceive(ctx, t, readDone))
+	require.Equal(t, "during", string(got))
+}
+
+func TestNetwork_ReorderDetectedByChecksums(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Context(t, testutil.WaitMedium)
+	n := immortalstreamstest.NewNetwork(immortalstreamstest.Options{
+		Checksums: true,
+	})
+	client, server := immortalstreamstest.NewPipePair(ctx, t, n)
+
+	n.ReorderAt(t, immortalstreamstest.ClientToServer, 2, 2)
+	_, err := client.Write([]byte("abcdef"))
+	require.NoError(t, err)
+
+	got := make([]byte, 6)
+	_, err = io.ReadFull(server, got)
+	require.NoError(t, err)
+	require.Equal(t, "abefcd", string(got))
+
+	// Keep a read pending on the server so it takes part in the reconnect.
+	go func() {
+		_, _ = server.Read(make([]byte, 1))
+	}()
+
+	// The corruption is detected on the next reconnect.
+	err = client.ForceReconnect()
+	require.ErrorIs(t, err, backedpipe.ErrStreamDiverged)
+}
+
+func TestNetwork_CloseUnblocksServer(t *testing.T) {
+	t.Parallel()
+
+	n := immortalstreamstest.NewNetwork(immortalstreamstest.Options{})
+	server := backedpipe.NewBackedPipe(context.Background(), n.ServerReconnector())
+
+	connectErr := make(chan error, 1)
+	go func() {
+		connectErr <- server.Connect()
+	}()
+
+	require.NoError(t, n.Close())
+	ctx := testutil.Context(t, testutil.WaitShort)
+	require.ErrorIs(t, testutil.RequireReceive(ctx, t, connectErr), backedpipe.ErrReconnectFailed)
+	require.NoError(t, server.Close())
+}
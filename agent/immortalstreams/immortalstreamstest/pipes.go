@@ -0,0 +1,63 @@
+package immortalstreamstest
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/coder/coder/v2/agent/immortalstreams/backedpipe"
+)
+
+// NewPipePair creates a connected client and server BackedPipe communicating
+// over n. The pipes and the network are closed when the test ends.
+func NewPipePair(ctx context.Context, t testing.TB, n *Network) (client, server *backedpipe.BackedPipe) {
+	t.Helper()
+
+	client = backedpipe.NewBackedPipe(ctx, n.ClientReconnector())
+	server = backedpipe.NewBackedPipe(ctx, n.ServerReconnector())
+	t.Cleanup(func() {
+		_ = client.Close()
+		_ = server.Close()
+		_ = n.Close()
+	})
+
+	var eg errgroup.Group
+	eg.Go(server.Connect)
+	eg.Go(client.Connect)
+	require.NoError(t, eg.Wait(), "connect pipe pair")
+	return client, server
+}
+
+// RequireTransfer writes data to from and requires that exactly the same
+// bytes are read from to, regardless of any reconnections in between.
+func RequireTransfer(ctx context.Context, t testing.TB, from io.Writer, to io.Reader, data []byte) {
+	t.Helper()
+
+	writeErr := make(chan error, 1)
+	go func() {
+		_, err := from.Write(data)
+		writeErr <- err
+	}()
+
+	got := make([]byte, len(data))
+	readErr := make(chan error, 1)
+	go func() {
+		_, err := io.ReadFull(to, got)
+		readErr <- err
+	}()
+
+	for range 2 {
+		select {
+		case err := <-writeErr:
+			require.NoError(t, err, "write")
+		case err := <-readErr:
+			require.NoError(t, err, "read")
+		case <-ctx.Done():
+			t.Fatalf("timed out transferring %d bytes: %v", len(data), ctx.Err())
+		}
+	}
+	require.Equal(t, data, got, "transferred data does not match")
+}
@@ -1,17 +1,24 @@
 package config
 
 import (
+	"context"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/gofrs/flock"
 	"github.com/kirsle/configdir"
 	"golang.org/x/xerrors"
 )
 
 const (
 	FlagName = "global-config"
+
+	// lockTimeout bounds how long writes wait for another process, e.g. a
+	// parallel `coder login`, to finish writing the same file.
+	lockTimeout = 5 * time.Second
 )
 
 // Root represents the configuration directory.
@@ -83,6 +90,15 @@ func (f File) Delete() error {
 	if f == "" {
 		return xerrors.Errorf("empty file path")
 	}
+	// Callers check for os.ErrNotExist, which the lock would obscure.
+	if _, err := os.Stat(string(f)); err != nil {
+		return err
+	}
+	unlock, err := lock(string(f))
+	if err != nil {
+		return err
+	}
+	defer unlock()
 	return os.Remove(string(f))
 }
 
@@ -115,14 +131,62 @@ func open(path string, flag int, mode os.FileMode) (*os.File, error) {
 	return os.OpenFile(path, flag, mode)
 }
 
+// write replaces the file at path atomically, by writing to a temporary file
+// and renaming it into place, so that concurrent readers see either the old
+// or the new contents. Writers take an advisory lock on a lock file next to
+// it, so that parallel CLI invocations don't interleave their writes.
 func write(path string, mode os.FileMode, dat []byte) error {
-	fi, err := open(path, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, mode)
+	err := os.MkdirAll(filepath.Dir(path), 0o750)
 	if err != nil {
 		return err
 	}
-	defer fi.Close()
-	_, err = fi.Write(dat)
-	return err
+	unlock, err := lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	fi, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := fi.Name()
+	defer func() {
+		// A no-op once the file is renamed.
+		_ = os.Remove(tmp)
+	}()
+	err = fi.Chmod(mode)
+	if err == nil {
+		_, err = fi.Write(dat)
+	}
+	if err == nil {
+		err = fi.Sync()
+	}
+	if closeErr := fi.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// lock takes the advisory lock for the file at path, waiting up to
+// lockTimeout for another process to release it. The lock is a separate file,
+// as Windows does not allow renaming a file over a locked one.
+func lock(path string) (unlock func(), err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), lockTimeout)
+	defer cancel()
+
+	l := flock.New(path + ".lock")
+	ok, err := l.TryLockContext(ctx, 50*time.Millisecond)
+	if !ok {
+		if err == nil || ctx.Err() != nil {
+			return nil, xerrors.Errorf("%s is locked by another coder process, try again once it has finished", path)
+		}
+		return nil, xerrors.Errorf("lock %s: %w", path, err)
+	}
+	return func() { _ = l.Close() }, nil
 }
 
 func read(path string) ([]byte, error) {
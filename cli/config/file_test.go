@@ -1,11 +1,17 @@
 package config_test
 
 import (
+	"fmt"
+	"os"
+	"sync"
 	"testing"
 
+	"github.com/gofrs/flock"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/coder/coder/v2/cli/config"
+	"github.com/coder/coder/v2/testutil"
 )
 
 func TestFile(t *testing.T) {
@@ -35,4 +41,57 @@ func TestFile(t *testing.T) {
 		err = root.Session().Delete()
 		require.NoError(t, err)
 	})
+	t.Run("DeleteNotExist", func(t *testing.T) {
+		t.Parallel()
+		err := config.Root(t.TempDir()).Session().Delete()
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("ConcurrentWrites", func(t *testing.T) {
+		t.Parallel()
+		session := config.Root(t.TempDir()).Session()
+		require.NoError(t, session.Write("initial"))
+
+		// Readers only ever see complete tokens while writers race.
+		var wg sync.WaitGroup
+		for i := range 10 {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, session.Write(fmt.Sprintf("token-%d", i)))
+			}()
+			go func() {
+				defer wg.Done()
+				data, err := session.Read()
+				if assert.NoError(t, err) {
+					assert.Regexp(t, `^(initial|token-\d)$`, data)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("WaitsForLock", func(t *testing.T) {
+		t.Parallel()
+		session := config.Root(t.TempDir()).Session()
+		require.NoError(t, session.Write("initial"))
+
+		// Another process holds the lock, e.g. while logging in.
+		lock := flock.New(string(session) + ".lock")
+		locked, err := lock.TryLock()
+		require.NoError(t, err)
+		require.True(t, locked)
+
+		done := make(chan error, 1)
+		go func() {
+			done <- session.Write("next")
+		}()
+		require.NoError(t, lock.Unlock())
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		require.NoError(t, testutil.RequireReceive(ctx, t, done))
+		data, err := session.Read()
+		require.NoError(t, err)
+		require.Equal(t, "next", data)
+	})
 }
@@ -361,6 +361,10 @@ func (r *RootCmd) Server(newAPI func(context.Context, *coderd.Options) (*coderd.
 			filesRateLimit := 12
 			if vals.RateLimit.DisableAll {
 				vals.RateLimit.API = -1
+				vals.RateLimit.AITaskName = -1
+				vals.RateLimit.AITaskNameDeployment = -1
+				vals.RateLimit.AITaskNameTokens = -1
+				vals.RateLimit.AITaskNameDeploymentTokens = -1
 				loginRateLimit = -1
 				filesRateLimit = -1
 			}
@@ -640,6 +644,15 @@ func (r *RootCmd) Server(newAPI func(context.Context, *coderd.Options) (*coderd.
 					vals.WorkspaceHostnameSuffix.String())
 			}
 
+			var aiTaskNameSystemPrompt string
+			if path := vals.AI.TaskNameSystemPromptFile.String(); path != "" {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					return xerrors.Errorf("read ai task name system prompt file: %w", err)
+				}
+				aiTaskNameSystemPrompt = string(data)
+			}
+
 			options := &coderd.Options{
 				AccessURL:                   vals.AccessURL.Value(),
 				AppHostname:                 appHostname,
@@ -661,14 +674,20 @@ func (r *RootCmd) Server(newAPI func(context.Context, *coderd.Options) (*coderd.
 				// Do not pass secret values to DeploymentOptions. All values should be read from
 				// the DeploymentValues instead, this just serves to indicate the source of each
 				// option. This is just defensive to prevent accidentally leaking.
-				DeploymentOptions:           codersdk.DeploymentOptionsWithoutSecrets(opts),
-				PrometheusRegistry:          promRegistry,
-				APIRateLimit:                int(vals.RateLimit.API.Value()),
-				LoginRateLimit:              loginRateLimit,
-				FilesRateLimit:              filesRateLimit,
-				HTTPClient:                  httpClient,
-				TemplateScheduleStore:       &atomic.Pointer[schedule.TemplateScheduleStore]{},
-				UserQuietHoursScheduleStore: &atomic.Pointer[schedule.UserQuietHoursScheduleStore]{},
+				DeploymentOptions:                  codersdk.DeploymentOptionsWithoutSecrets(opts),
+				PrometheusRegistry:                 promRegistry,
+				APIRateLimit:                       int(vals.RateLimit.API.Value()),
+				LoginRateLimit:                     loginRateLimit,
+				FilesRateLimit:                     filesRateLimit,
+				AITaskNameRateLimit:                int(vals.RateLimit.AITaskName.Value()),
+				AITaskNameDeploymentRateLimit:      int(vals.RateLimit.AITaskNameDeployment.Value()),
+				AITaskNameConcurrency:              int(vals.RateLimit.AITaskNameConcurrency.Value()),
+				AITaskNameTokenRateLimit:           vals.RateLimit.AITaskNameTokens.Value(),
+				AITaskNameDeploymentTokenRateLimit: vals.RateLimit.AITaskNameDeploymentTokens.Value(),
+				AITaskNameSystemPrompt:             aiTaskNameSystemPrompt,
+				HTTPClient:                         httpClient,
+				TemplateScheduleStore:              &atomic.Pointer[schedule.TemplateScheduleStore]{},
+				UserQuietHoursScheduleStore:        &atomic.Pointer[schedule.UserQuietHoursScheduleStore]{},
 				SSHConfig: codersdk.SSHConfigResponse{
 					HostnamePrefix:   vals.SSHConfig.DeploymentName.String(),
 					SSHConfigOptions: configSSHOptions,
@@ -1,21 +1,35 @@
 package coderd
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
+	"regexp"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/xerrors"
 
 	"cdr.dev/slog"
 
 	"github.com/coder/coder/v2/coderd/audit"
 	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/db2sdk"
+	"github.com/coder/coder/v2/coderd/database/dbauthz"
+	"github.com/coder/coder/v2/coderd/database/dbtime"
 	"github.com/coder/coder/v2/coderd/httpapi"
 	"github.com/coder/coder/v2/coderd/httpapi/httperror"
 	"github.com/coder/coder/v2/coderd/httpmw"
@@ -78,6 +92,990 @@ func (api *API) aiTasksPrompts(rw http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// This endpoint is experimental and not guaranteed to be stable, so we're not
+// generating public-facing documentation for it.
+func (api *API) aiTasksName(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req codersdk.AITaskNameRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	apiKey := httpmw.APIKey(r)
+	opts, orgID, err := api.aiTaskNameOptions(ctx, apiKey.UserID, req)
+	if err != nil {
+		httperror.WriteResponseError(ctx, rw, err)
+		return
+	}
+	existingNames, err := api.ownerWorkspaceNames(ctx, apiKey.UserID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspaces.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	avoidNames := slices.Concat(existingNames, req.AvoidNames)
+
+	names, err := api.generateAuditedTaskNames(r, orgID, req.Prompt, max(req.Count, 1), avoidNames, opts...)
+	if status, resp, ok := aiTaskNameErrorResponse(err); ok {
+		httpapi.Write(ctx, rw, status, resp)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.AITaskNameResponse{
+		Name:  names[0],
+		Names: names,
+	})
+}
+
+// aiTasksNameStream is the streaming variant of aiTasksName. The model's
+// response is forwarded as server-sent events while it is generated, followed
+// by a final event with the names.
+//
+// This endpoint is experimental and not guaranteed to be stable, so we're not
+// generating public-facing documentation for it.
+func (api *API) aiTasksNameStream(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req codersdk.AITaskNameRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	apiKey := httpmw.APIKey(r)
+	opts, orgID, err := api.aiTaskNameOptions(ctx, apiKey.UserID, req)
+	if err != nil {
+		httperror.WriteResponseError(ctx, rw, err)
+		return
+	}
+	existingNames, err := api.ownerWorkspaceNames(ctx, apiKey.UserID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspaces.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	avoidNames := slices.Concat(existingNames, req.AvoidNames)
+
+	// The sender stops when its context is done, so cancel it and wait for
+	// it before returning rather than holding the connection open.
+	senderCtx, cancel := context.WithCancel(ctx)
+	sendEvent, senderClosed, err := httpapi.ServerSentEventSender(rw, r.WithContext(senderCtx))
+	if err != nil {
+		cancel()
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error setting up server-sent events.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	defer func() {
+		cancel()
+		<-senderClosed
+	}()
+
+	opts = append(opts, taskname.WithStream(func(delta string) {
+		_ = sendEvent(codersdk.ServerSentEvent{
+			Type: codersdk.ServerSentEventTypeData,
+			Data: codersdk.AITaskNameStreamEvent{Delta: delta},
+		})
+	}))
+	names, err := api.generateAuditedTaskNames(r, orgID, req.Prompt, max(req.Count, 1), avoidNames, opts...)
+	if _, resp, ok := aiTaskNameErrorResponse(err); ok {
+		_ = sendEvent(codersdk.ServerSentEvent{
+			Type: codersdk.ServerSentEventTypeError,
+			Data: resp,
+		})
+		return
+	}
+	_ = sendEvent(codersdk.ServerSentEvent{
+		Type: codersdk.ServerSentEventTypeData,
+		Data: codersdk.AITaskNameStreamEvent{
+			Name:  names[0],
+			Names: names,
+		},
+	})
+}
+
+const (
+	// maxAITaskNameBatchSize is the maximum number of requests in a task name
+	// batch.
+	maxAITaskNameBatchSize = 20
+	// aiTaskNameBatchConcurrency bounds how many names of a batch are
+	// generated at once, on top of the deployment-wide bound.
+	aiTaskNameBatchConcurrency = 4
+	// maxAITaskNameBatchBodySize is the largest task name batch request
+	// body that is read.
+	maxAITaskNameBatchBodySize = 1 << 20
+)
+
+// aiTasksNameBatch generates names for several task prompts at once, for
+// automation that creates many tasks. Each request in the batch counts
+// against the rate limit, and fails or succeeds on its own.
+//
+// This endpoint is experimental and not guaranteed to be stable, so we're not
+// generating public-facing documentation for it.
+func (api *API) aiTasksNameBatch(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	apiKey := httpmw.APIKey(r)
+
+	var req codersdk.AITaskNameBatchRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if len(req.Requests) == 0 || len(req.Requests) > maxAITaskNameBatchSize {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("A batch must contain between 1 and %d requests.", maxAITaskNameBatchSize),
+		})
+		return
+	}
+
+	existingNames, err := api.ownerWorkspaceNames(ctx, apiKey.UserID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspaces.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	results := make([]codersdk.AITaskNameBatchResult, len(req.Requests))
+	var eg errgroup.Group
+	eg.SetLimit(aiTaskNameBatchConcurrency)
+	for i, nameReq := range req.Requests {
+		eg.Go(func() error {
+			result := codersdk.AITaskNameBatchResult{Index: i}
+			defer func() {
+				results[i] = result
+			}()
+
+			opts, orgID, err := api.aiTaskNameOptions(ctx, apiKey.UserID, nameReq)
+			if err != nil {
+				resp := codersdk.Response{
+					Message: "Internal error generating task name.",
+					Detail:  err.Error(),
+				}
+				if responder, ok := httperror.IsResponder(err); ok {
+					_, resp = responder.Response()
+				}
+				result.Error = &resp
+				return nil
+			}
+			names, err := api.generateAuditedTaskNames(r, orgID, nameReq.Prompt, max(nameReq.Count, 1), slices.Concat(existingNames, nameReq.AvoidNames), opts...)
+			if _, resp, ok := aiTaskNameErrorResponse(err); ok {
+				result.Error = &resp
+				return nil
+			}
+			result.Name = names[0]
+			result.Names = names
+			return nil
+		})
+	}
+	_ = eg.Wait()
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.AITaskNameBatchResponse{
+		Results: results,
+	})
+}
+
+// aiTaskNameBatchCost is the number of requests a task name batch counts as
+// against the rate limit. The body is capped at maxAITaskNameBatchBodySize,
+// and only read as far as the first maxAITaskNameBatchSize requests; what was
+// read is put back for the handler.
+func aiTaskNameBatchCost(r *http.Request) int {
+	// Without a response writer, going over the cap only fails the read, and
+	// the handler rejects the body as invalid JSON.
+	body := http.MaxBytesReader(nil, r.Body, maxAITaskNameBatchBodySize)
+	var read bytes.Buffer
+	n := countAITaskNameBatchRequests(json.NewDecoder(io.TeeReader(body, &read)))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(&read, body), body}
+	return max(n, 1)
+}
+
+// countAITaskNameBatchRequests counts the entries of the requests array of a
+// task name batch, up to maxAITaskNameBatchSize. It returns what it has
+// counted so far if the body is not a valid batch.
+func countAITaskNameBatchRequests(dec *json.Decoder) int {
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return 0
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return 0
+		}
+		if key != "requests" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return 0
+			}
+			continue
+		}
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+			return 0
+		}
+		n := 0
+		for n < maxAITaskNameBatchSize && dec.More() {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return n
+			}
+			n++
+		}
+		return n
+	}
+	return 0
+}
+
+// aiTasksParameters suggests values for the rich parameters of a template
+// version from a task prompt, so that the parameter form can be pre-filled
+// when creating a task. No values are suggested if no language model is
+// configured or it fails.
+//
+// This endpoint is experimental and not guaranteed to be stable, so we're not
+// generating public-facing documentation for it.
+func (api *API) aiTasksParameters(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req codersdk.AITaskParametersRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Prompt) == "" {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "A prompt is required to suggest parameter values.",
+		})
+		return
+	}
+
+	templateVersion, err := api.Database.GetTemplateVersionByID(ctx, req.TemplateVersionID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	dbParams, err := api.Database.GetTemplateVersionParameters(ctx, templateVersion.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version parameters.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	params, err := db2sdk.TemplateVersionParameters(dbParams)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error converting template version parameters.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	suggestions := map[string]string{}
+	if api.aiTaskNameProvider != nil && len(params) > 0 {
+		suggestions, err = api.suggestAuditedTaskParameters(r, templateVersion.OrganizationID, req.Prompt, params)
+		switch {
+		case errors.Is(err, errTaskNameTimeout):
+			httpapi.Write(ctx, rw, http.StatusGatewayTimeout, codersdk.Response{
+				Message: "Timed out suggesting parameter values.",
+				Detail:  "The language model did not respond in time. Try again, or fill in the parameters yourself.",
+			})
+			return
+		case errors.Is(err, taskname.ErrPromptRejected):
+			httpapi.Write(ctx, rw, http.StatusBadRequest, aiTaskPromptRejectedResponse)
+			return
+		}
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.AITaskParametersResponse{
+		Suggestions: suggestions,
+	})
+}
+
+// suggestAuditedTaskParameters suggests parameter values with the task name
+// provider, within the same timeouts and concurrency bound as task names, and
+// records the request in the audit log. The models of the organization are
+// used. Errors are as for generateTaskNames.
+func (api *API) suggestAuditedTaskParameters(r *http.Request, orgID uuid.UUID, prompt string, params []codersdk.TemplateVersionParameter) (suggestions map[string]string, err error) {
+	ctx := r.Context()
+	aiReq := database.AIRequest{Feature: "task_parameters"}
+	start := api.Clock.Now()
+	defer func() {
+		aiReq.LatencyMS = api.Clock.Since(start).Milliseconds()
+		status := http.StatusOK
+		switch {
+		case errors.Is(err, errTaskNameTimeout):
+			status = http.StatusGatewayTimeout
+			aiReq.Outcome = aiRequestOutcomeTimeout
+		case errors.Is(err, taskname.ErrPromptRejected):
+			status = http.StatusBadRequest
+			aiReq.Outcome = aiRequestOutcomeRejected
+		case len(suggestions) > 0:
+			aiReq.Outcome = aiRequestOutcomeGenerated
+		default:
+			aiReq.Outcome = aiRequestOutcomeFallback
+		}
+		api.auditAIRequest(r, status, aiReq, prompt)
+	}()
+
+	genCtx := ctx
+	if timeout := api.DeploymentValues.AI.TaskNameTimeout.Value(); timeout > 0 {
+		var cancel context.CancelFunc
+		genCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if _, ok := api.aiTaskNameTokenBudget.Allow(aiTaskNameTokenBudgetKey(ctx)); !ok {
+		return map[string]string{}, nil
+	}
+	if !api.acquireAITaskNameSlot(genCtx) {
+		if ctx.Err() == nil {
+			return map[string]string{}, errTaskNameTimeout
+		}
+		return map[string]string{}, nil
+	}
+	defer api.releaseAITaskNameSlot()
+
+	suggestions, err = taskname.SuggestParameters(genCtx, prompt, params,
+		taskname.WithProvider(api.aiTaskNameProvider),
+		api.aiTaskNameModels(ctx, orgID),
+		taskname.WithCallTimeout(api.DeploymentValues.AI.TaskNameCallTimeout.Value()),
+		taskname.WithModerator(api.aiTaskPromptModerator),
+		taskname.WithUsageCallback(func(usage taskname.Usage) {
+			aiReq.Model = usage.Model
+			api.recordAITaskNameUsage(ctx, orgID, aiReq.Feature, usage)
+		}),
+	)
+	if err != nil {
+		return map[string]string{}, api.aiTaskModelError(ctx, err, "unable to suggest task parameters")
+	}
+	return suggestions, nil
+}
+
+// aiTaskNameModels returns the option that selects the models to generate
+// task names with for the organization. If the organization's settings can't
+// be read, the deployment's models are used.
+func (api *API) aiTaskNameModels(ctx context.Context, orgID uuid.UUID) taskname.Option {
+	if orgID == uuid.Nil {
+		return taskname.WithModels(taskname.GetModelsFromEnv()...)
+	}
+	//nolint:gocritic // Requires system context to read runtime config
+	models, err := api.aiTaskNameModelPolicy.Models(dbauthz.AsSystemRestricted(ctx), api.Database, orgID)
+	if err != nil {
+		api.Logger.Warn(ctx, "unable to read organization task name models, using deployment models",
+			slog.F("organization_id", orgID), slog.Error(err))
+		return taskname.WithModels(taskname.GetModelsFromEnv()...)
+	}
+	return taskname.WithModels(models...)
+}
+
+// This endpoint is experimental and not guaranteed to be stable, so we're not
+// generating public-facing documentation for it.
+func (api *API) aiTaskNameModelSettings(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	organization := httpmw.OrganizationParam(r)
+
+	//nolint:gocritic // Requires system context to read runtime config
+	settings, err := api.aiTaskNameModelPolicy.OrganizationSettings(dbauthz.AsSystemRestricted(ctx), api.Database, organization.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, settings)
+}
+
+// This endpoint is experimental and not guaranteed to be stable, so we're not
+// generating public-facing documentation for it.
+func (api *API) patchAITaskNameModelSettings(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	organization := httpmw.OrganizationParam(r)
+
+	if !api.Authorize(r, policy.ActionUpdate, organization) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	var req codersdk.AITaskNameModelSettings
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	models := make([]string, 0, len(req.Models))
+	for _, model := range req.Models {
+		model = strings.TrimSpace(model)
+		if model == "" {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Invalid request to update organization task name models.",
+				Validations: []codersdk.ValidationError{{
+					Field:  "models",
+					Detail: "Models must not be empty.",
+				}},
+			})
+			return
+		}
+		models = append(models, model)
+	}
+
+	//nolint:gocritic // Requires system context to update runtime config
+	sysCtx := dbauthz.AsSystemRestricted(ctx)
+	err := api.aiTaskNameModelPolicy.UpdateOrganizationSettings(sysCtx, api.Database, organization.ID, codersdk.AITaskNameModelSettings{Models: models})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	settings, err := api.aiTaskNameModelPolicy.OrganizationSettings(sysCtx, api.Database, organization.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, settings)
+}
+
+// aiTasksUsage reports the language model tokens used by AI tasks, by user,
+// organization, feature and model.
+//
+// This endpoint is experimental and not guaranteed to be stable, so we're not
+// generating public-facing documentation for it.
+func (api *API) aiTasksUsage(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	p := httpapi.NewQueryParamParser().
+		RequiredNotEmpty("start_time").
+		RequiredNotEmpty("end_time")
+	vals := r.URL.Query()
+	var (
+		// The QueryParamParser does not preserve timezone, so we need
+		// to parse the time ourselves.
+		startTimeString = p.String(vals, "", "start_time")
+		endTimeString   = p.String(vals, "", "end_time")
+		organizationID  = p.UUID(vals, uuid.Nil, "organization_id")
+		userID          = p.UUID(vals, uuid.Nil, "user_id")
+	)
+	p.ErrorExcessParams(vals)
+	if len(p.Errors) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Query parameters have invalid values.",
+			Validations: p.Errors,
+		})
+		return
+	}
+
+	startTime, endTime, ok := parseInsightsStartAndEndTime(ctx, rw, api.Clock.Now(), startTimeString, endTimeString)
+	if !ok {
+		return
+	}
+
+	rows, err := api.Database.GetAIUsageSummary(ctx, database.GetAIUsageSummaryParams{
+		StartTime:      startTime,
+		EndTime:        endTime,
+		OrganizationID: organizationID,
+		UserID:         userID,
+	})
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching AI usage.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	usage := make([]codersdk.AITaskUsage, 0, len(rows))
+	for _, row := range rows {
+		var orgID *uuid.UUID
+		if row.OrganizationID.Valid {
+			orgID = &row.OrganizationID.UUID
+		}
+		usage = append(usage, codersdk.AITaskUsage{
+			UserID:           row.UserID,
+			OrganizationID:   orgID,
+			Feature:          row.Feature,
+			Model:            row.Model,
+			Calls:            row.Calls,
+			PromptTokens:     row.PromptTokens,
+			CompletionTokens: row.CompletionTokens,
+		})
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.AITaskUsageResponse{
+		StartTime: startTime,
+		EndTime:   endTime,
+		Usage:     usage,
+	})
+}
+
+var aiTaskNameTimeoutResponse = codersdk.Response{
+	Message: "Timed out generating a task name.",
+	Detail:  "The language model did not respond in time. Try again, or pick a name yourself.",
+}
+
+var aiTaskPromptRejectedResponse = codersdk.Response{
+	Message: "The prompt was rejected by content moderation.",
+	Detail:  "Rephrase the prompt and try again.",
+}
+
+// aiTaskNameErrorResponse returns the response for an error returned by
+// generateTaskNames, or false if the generated names can be used.
+func aiTaskNameErrorResponse(err error) (int, codersdk.Response, bool) {
+	switch {
+	case errors.Is(err, errTaskNameTimeout):
+		return http.StatusGatewayTimeout, aiTaskNameTimeoutResponse, true
+	case errors.Is(err, taskname.ErrPromptRejected):
+		return http.StatusBadRequest, aiTaskPromptRejectedResponse, true
+	default:
+		return 0, codersdk.Response{}, false
+	}
+}
+
+// aiTaskNameOptions validates a task name request and returns the options to
+// generate names with, and the organization of its template if it has one.
+// Errors are httperror responders.
+func (api *API) aiTaskNameOptions(ctx context.Context, userID uuid.UUID, req codersdk.AITaskNameRequest) ([]taskname.Option, uuid.UUID, error) {
+	if strings.TrimSpace(req.Prompt) == "" {
+		return nil, uuid.Nil, httperror.NewResponseError(http.StatusBadRequest, codersdk.Response{
+			Message: "A prompt is required to generate a task name.",
+		})
+	}
+
+	if req.Count < 0 || req.Count > taskname.MaxCandidates {
+		return nil, uuid.Nil, httperror.NewResponseError(http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Count must be between 1 and %d.", taskname.MaxCandidates),
+		})
+	}
+
+	opts := []taskname.Option{
+		taskname.WithRepositoryURL(req.RepositoryURL),
+	}
+	var orgID uuid.UUID
+	if req.TemplateID != uuid.Nil {
+		template, err := api.Database.GetTemplateByID(ctx, req.TemplateID)
+		if err != nil {
+			if httpapi.Is404Error(err) {
+				return nil, uuid.Nil, httperror.ErrResourceNotFound
+			}
+			return nil, uuid.Nil, httperror.NewResponseError(http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching template.",
+				Detail:  err.Error(),
+			})
+		}
+		templateName := template.DisplayName
+		if templateName == "" {
+			templateName = template.Name
+		}
+		opts = append(opts,
+			taskname.WithTemplateName(templateName),
+			api.aiTaskNameModels(ctx, template.OrganizationID),
+		)
+		orgID = template.OrganizationID
+	}
+	opts = append(opts, api.aiTaskIssueOptions(ctx, userID, req.Prompt)...)
+	return opts, orgID, nil
+}
+
+// aiTaskIssueURLPattern matches the URLs in a task prompt that may point to
+// an issue or pull request.
+var aiTaskIssueURLPattern = regexp.MustCompile(`https?://[^\s<>"'()\[\]]+`)
+
+// aiTaskIssueTimeout bounds fetching the issue linked in a task prompt, so a
+// slow Git provider only costs the name some context.
+const aiTaskIssueTimeout = 5 * time.Second
+
+// aiTaskIssueOptions gives the model the title and labels of the first issue
+// or pull request linked in the prompt, fetched with the user's external
+// auth. It returns no options if there is no such link or it can't be
+// fetched.
+func (api *API) aiTaskIssueOptions(ctx context.Context, userID uuid.UUID, prompt string) []taskname.Option {
+	if api.aiTaskNameProvider == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, aiTaskIssueTimeout)
+	defer cancel()
+
+	for _, issueURL := range aiTaskIssueURLPattern.FindAllString(prompt, -1) {
+		// Drop punctuation ending the sentence the URL is in.
+		issueURL = strings.TrimRight(issueURL, ".,;:!?")
+		for _, config := range api.ExternalAuthConfigs {
+			if config.Regex == nil || !config.Regex.MatchString(issueURL) {
+				continue
+			}
+			logger := api.Logger.With(slog.F("external_auth_id", config.ID), slog.F("url", issueURL))
+
+			link, err := api.Database.GetExternalAuthLink(ctx, database.GetExternalAuthLinkParams{
+				ProviderID: config.ID,
+				UserID:     userID,
+			})
+			if err != nil {
+				if !errors.Is(err, sql.ErrNoRows) {
+					logger.Warn(ctx, "get external auth link for task prompt issue", slog.Error(err))
+				}
+				continue
+			}
+			link, err = config.RefreshToken(ctx, api.Database, link)
+			if err != nil {
+				logger.Debug(ctx, "refresh external auth token for task prompt issue", slog.Error(err))
+				continue
+			}
+			issue, ok, err := config.Issue(ctx, link.OAuthAccessToken, issueURL)
+			if err != nil {
+				logger.Debug(ctx, "fetch task prompt issue", slog.Error(err))
+				continue
+			}
+			if !ok {
+				continue
+			}
+			return []taskname.Option{taskname.WithIssue(issue.Title, issue.Labels...)}
+		}
+	}
+	return nil
+}
+
+// acquireAITaskNameSlot waits for a free language model slot for task name
+// generation. It returns false if ctx is done first.
+func (api *API) acquireAITaskNameSlot(ctx context.Context) bool {
+	if api.aiTaskNameSem == nil {
+		return true
+	}
+	select {
+	case api.aiTaskNameSem <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (api *API) releaseAITaskNameSlot() {
+	if api.aiTaskNameSem != nil {
+		<-api.aiTaskNameSem
+	}
+}
+
+// aiTaskNameTokenLimit rejects requests from users who have used their
+// language model token budget for the minute, or when the deployment has.
+func (api *API) aiTaskNameTokenLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		retryAfter, ok := api.aiTaskNameTokenBudget.Allow(aiTaskNameTokenBudgetKey(ctx))
+		if !ok {
+			rw.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			httpapi.Write(ctx, rw, http.StatusTooManyRequests, codersdk.Response{
+				Message: "The language model token budget for generating task names has been used up for this minute.",
+				Detail:  fmt.Sprintf("Try again in %s.", retryAfter.Round(time.Second)),
+			})
+			return
+		}
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// aiTaskNameTokenBudgetKey returns the key the user making the request is
+// charged tokens under.
+func aiTaskNameTokenBudgetKey(ctx context.Context) string {
+	if actor, ok := httpmw.UserAuthorizationOptional(ctx); ok {
+		return actor.ID
+	}
+	return ""
+}
+
+// recordAITaskNameUsage attributes the tokens used by a language model call
+// for feature to the user making the request and the organization it was
+// made for, which is uuid.Nil if there is none.
+func (api *API) recordAITaskNameUsage(ctx context.Context, orgID uuid.UUID, feature string, usage taskname.Usage) {
+	api.aiTaskNameTokenBudget.Charge(aiTaskNameTokenBudgetKey(ctx), usage.PromptTokens+usage.CompletionTokens)
+	api.aiTaskNameTokens.WithLabelValues(usage.Model, "prompt").Add(float64(usage.PromptTokens))
+	api.aiTaskNameTokens.WithLabelValues(usage.Model, "completion").Add(float64(usage.CompletionTokens))
+
+	actor, ok := httpmw.UserAuthorizationOptional(ctx)
+	if !ok {
+		return
+	}
+	userID, err := uuid.Parse(actor.ID)
+	if err != nil {
+		return
+	}
+	// The usage is recorded even if the client has gone away.
+	//nolint:gocritic // Recording AI usage is a system function.
+	err = api.Database.InsertAIUsage(dbauthz.AsSystemRestricted(context.WithoutCancel(ctx)), database.InsertAIUsageParams{
+		ID:               uuid.New(),
+		CreatedAt:        dbtime.Time(api.Clock.Now()),
+		UserID:           userID,
+		OrganizationID:   uuid.NullUUID{UUID: orgID, Valid: orgID != uuid.Nil},
+		Feature:          feature,
+		Model:            usage.Model,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+	})
+	if err != nil {
+		api.Logger.Warn(ctx, "unable to record ai usage",
+			slog.F("user_id", userID),
+			slog.F("organization_id", orgID),
+			slog.F("model", usage.Model),
+			slog.Error(err),
+		)
+	}
+}
+
+// ownerWorkspaceNames returns the names of the workspaces owned by ownerID that
+// are visible to the caller, so generated task names can avoid them.
+func (api *API) ownerWorkspaceNames(ctx context.Context, ownerID uuid.UUID) ([]string, error) {
+	workspaces, err := api.Database.GetWorkspaces(ctx, database.GetWorkspacesParams{
+		OwnerID: ownerID,
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("get workspaces: %w", err)
+	}
+	names := make([]string, 0, len(workspaces))
+	for _, ws := range workspaces {
+		names = append(names, ws.Name)
+	}
+	return names, nil
+}
+
+// generateTaskName generates a workspace name for a task from its prompt that
+// is not one of avoidNames. The name and errors are as for generateTaskNames.
+func (api *API) generateTaskName(ctx context.Context, orgID uuid.UUID, prompt string, avoidNames []string, opts ...taskname.Option) (string, error) {
+	names, _, err := api.generateTaskNames(ctx, orgID, prompt, 1, avoidNames, opts...)
+	return names[0], err
+}
+
+// AI request outcomes recorded in the audit log.
+const (
+	aiRequestOutcomeGenerated = "generated"
+	aiRequestOutcomeFallback  = "fallback"
+	aiRequestOutcomeTimeout   = "timeout"
+	aiRequestOutcomeRejected  = "rejected"
+)
+
+// aiAuditPromptLength is how many characters of a prompt are kept in the
+// audit log, unless prompt retention is disabled.
+const aiAuditPromptLength = 256
+
+// generateAuditedTaskNames is generateTaskNames for the AI task name
+// endpoints, which record every generation in the audit log.
+func (api *API) generateAuditedTaskNames(r *http.Request, orgID uuid.UUID, prompt string, count int, avoidNames []string, opts ...taskname.Option) ([]string, error) {
+	aiReq := database.AIRequest{Feature: "task_name"}
+	opts = append(opts, taskname.WithUsageCallback(func(usage taskname.Usage) {
+		aiReq.Model = usage.Model
+	}))
+
+	start := api.Clock.Now()
+	names, generated, err := api.generateTaskNames(r.Context(), orgID, prompt, count, avoidNames, opts...)
+	aiReq.LatencyMS = api.Clock.Since(start).Milliseconds()
+
+	status := http.StatusOK
+	switch {
+	case errors.Is(err, errTaskNameTimeout):
+		status = http.StatusGatewayTimeout
+		aiReq.Outcome = aiRequestOutcomeTimeout
+	case errors.Is(err, taskname.ErrPromptRejected):
+		status = http.StatusBadRequest
+		aiReq.Outcome = aiRequestOutcomeRejected
+	case generated:
+		aiReq.Outcome = aiRequestOutcomeGenerated
+	default:
+		aiReq.Outcome = aiRequestOutcomeFallback
+	}
+	api.auditAIRequest(r, status, aiReq, prompt)
+	return names, err
+}
+
+// auditAIRequest records a language model request made on behalf of the user
+// of r in the audit log. Prompts are hashed so that repeated prompts can be
+// correlated, and only kept if prompt retention is enabled.
+func (api *API) auditAIRequest(r *http.Request, status int, aiReq database.AIRequest, prompt string) {
+	sum := sha256.Sum256([]byte(prompt))
+	aiReq.ID = uuid.New()
+	aiReq.PromptHash = hex.EncodeToString(sum[:8])
+	if api.DeploymentValues.AI.AuditPromptContent.Value() {
+		aiReq.Prompt = prompt
+		if runes := []rune(prompt); len(runes) > aiAuditPromptLength {
+			aiReq.Prompt = string(runes[:aiAuditPromptLength])
+		}
+	}
+
+	auditor := api.Auditor.Load()
+	// The entry is written even if the client has gone away.
+	audit.BackgroundAudit(context.WithoutCancel(r.Context()), &audit.BackgroundAuditParams[database.AIRequest]{
+		Audit:     *auditor,
+		Log:       api.Logger,
+		UserID:    httpmw.APIKey(r).UserID,
+		RequestID: httpmw.RequestID(r),
+		IP:        r.RemoteAddr,
+		UserAgent: r.UserAgent(),
+		Status:    status,
+		Action:    database.AuditActionCreate,
+		New:       aiReq,
+	})
+}
+
+// newAITaskNameProvider returns the language model provider configured to
+// generate task names, or nil if there is none.
+func newAITaskNameProvider(ctx context.Context, logger slog.Logger, vals *codersdk.DeploymentValues) taskname.Provider {
+	cfg := taskname.ProviderConfig{
+		Type:       taskname.ProviderType(vals.AI.TaskNameProvider.String()),
+		APIKey:     vals.AI.TaskNameProviderKey.String(),
+		BaseURL:    vals.AI.TaskNameProviderBaseURL.String(),
+		Region:     vals.AI.TaskNameProviderRegion.String(),
+		APIVersion: vals.AI.TaskNameProviderVersion.String(),
+	}
+	if cfg.APIKey == "" && (cfg.Type == "" || cfg.Type == taskname.ProviderAnthropic) {
+		cfg.APIKey = taskname.GetAnthropicAPIKeyFromEnv()
+	}
+	aliases, err := taskname.ParseModelAliases(vals.AI.TaskNameModelAliases.Value())
+	if err != nil {
+		logger.Error(ctx, "invalid ai task name model aliases, task names will not be generated", slog.Error(err))
+		return nil
+	}
+	cfg.ModelAliases = aliases
+
+	provider, err := taskname.NewProvider(ctx, cfg)
+	if err != nil {
+		// Without an API key, task names silently fall back to random
+		// names, as they always have.
+		if !errors.Is(err, taskname.ErrNoAPIKey) {
+			logger.Error(ctx, "unable to configure ai task name provider, task names will not be generated", slog.Error(err))
+		}
+		return nil
+	}
+	return provider
+}
+
+// newAITaskPromptModerator returns the deny-list moderator configured for AI
+// task prompts, or nil if moderation is off, which is the default. Prompts
+// are never rewritten unless sanitizing is configured, and then every
+// rewrite is logged.
+func newAITaskPromptModerator(ctx context.Context, logger slog.Logger, vals *codersdk.DeploymentValues) taskname.Moderator {
+	action := taskname.ModerationAction(vals.AI.TaskPromptModeration.String())
+	if action == "" {
+		action = taskname.ModerationOff
+	}
+	moderator, err := taskname.NewDenyListModerator(vals.AI.TaskPromptDenyList.Value(), action)
+	if err != nil {
+		// Fail closed: a typo in the configuration should not let
+		// prompts through unmoderated.
+		logger.Error(ctx, "invalid ai task prompt moderation, all prompts will be rejected", slog.Error(err))
+		return func(context.Context, string) (string, error) {
+			return "", xerrors.Errorf("invalid moderation configuration: %w", taskname.ErrPromptRejected)
+		}
+	}
+	if moderator == nil || action != taskname.ModerationSanitize {
+		return moderator
+	}
+	return func(ctx context.Context, prompt string) (string, error) {
+		sanitized, err := moderator(ctx, prompt)
+		if err == nil && sanitized != prompt {
+			logger.Info(ctx, "ai task prompt sanitized by moderation",
+				slog.F("removed_characters", len(prompt)-len(sanitized)),
+			)
+		}
+		return sanitized, err
+	}
+}
+
+// errTaskNameTimeout is returned by generateTaskNames when generation did not
+// complete within the configured timeouts.
+var errTaskNameTimeout = xerrors.New("task name generation timed out")
+
+// generateTaskNames generates count distinct workspace names for a task from
+// its prompt that are not in avoidNames. If no language model is configured,
+// or it comes up short, random fallback names make up the difference; generated
+// reports whether the model came up with any. Tokens used are attributed to
+// orgID, which is uuid.Nil if there is no organization. The names are always
+// returned; errTaskNameTimeout is returned alongside them if generation timed
+// out, and an error wrapping taskname.ErrPromptRejected if moderation rejected
+// the prompt.
+func (api *API) generateTaskNames(ctx context.Context, orgID uuid.UUID, prompt string, count int, avoidNames []string, opts ...taskname.Option) (names []string, generated bool, err error) {
+	if api.aiTaskNameProvider != nil {
+		names, err = api.generateTaskNamesWithModel(ctx, orgID, prompt, count, avoidNames, opts...)
+	}
+	generated = len(names) > 0
+
+	for len(names) < count {
+		names = append(names, taskname.GenerateFallbackAvoiding(slices.Concat(avoidNames, names)...))
+	}
+	return names, generated, err
+}
+
+// generateTaskNamesWithModel generates names with the configured language
+// model. Errors other than timeouts and rejected prompts are logged rather
+// than returned, so that the caller falls back to random names.
+func (api *API) generateTaskNamesWithModel(ctx context.Context, orgID uuid.UUID, prompt string, count int, avoidNames []string, opts ...taskname.Option) ([]string, error) {
+	// Bound the whole generation, including waiting for a free slot, so a
+	// slow provider can't hold the request open.
+	genCtx := ctx
+	if timeout := api.DeploymentValues.AI.TaskNameTimeout.Value(); timeout > 0 {
+		var cancel context.CancelFunc
+		genCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// Requests to the naming endpoints are rejected when the token budget
+	// is used up, but task creation and batches fall back to random names.
+	if _, ok := api.aiTaskNameTokenBudget.Allow(aiTaskNameTokenBudgetKey(ctx)); !ok {
+		api.Logger.Debug(ctx, "ai task name token budget used up, using a random name")
+		return nil, nil
+	}
+	if !api.acquireAITaskNameSlot(genCtx) {
+		if ctx.Err() == nil {
+			return nil, errTaskNameTimeout
+		}
+		return nil, nil
+	}
+	defer api.releaseAITaskNameSlot()
+
+	// The deployment's models are the default, which callers can override
+	// with the models of an organization.
+	opts = append([]taskname.Option{taskname.WithModels(taskname.GetModelsFromEnv()...)}, opts...)
+	opts = append(opts,
+		taskname.WithProvider(api.aiTaskNameProvider),
+		taskname.WithAvoidNames(avoidNames...),
+		taskname.WithSystemPrompt(api.AITaskNameSystemPrompt),
+		taskname.WithInstructions(api.DeploymentValues.AI.TaskNameInstructions.String()),
+		taskname.WithCallTimeout(api.DeploymentValues.AI.TaskNameCallTimeout.Value()),
+		taskname.WithDenyList(api.DeploymentValues.AI.TaskNameDenyList.Value()...),
+		taskname.WithModerator(api.aiTaskPromptModerator),
+		taskname.WithUsageCallback(func(usage taskname.Usage) {
+			api.recordAITaskNameUsage(ctx, orgID, "task_name", usage)
+		}),
+	)
+	if api.aiTaskNameCache != nil {
+		opts = append(opts, taskname.WithCache(api.aiTaskNameCache))
+	}
+	names, err := taskname.GenerateCandidates(genCtx, prompt, count, opts...)
+	if err != nil {
+		return nil, api.aiTaskModelError(ctx, err, "unable to generate task name")
+	}
+	return names, nil
+}
+
+// aiTaskModelError logs an error from a language model request made for a
+// task, and returns it if the caller needs to know about it: errTaskNameTimeout
+// if the request timed out, or the moderation error if the prompt was
+// rejected.
+func (api *API) aiTaskModelError(ctx context.Context, err error, msg string) error {
+	switch {
+	case errors.Is(err, taskname.ErrPromptRejected):
+		api.Logger.Info(ctx, "ai task prompt rejected by moderation", slog.Error(err))
+		return err
+	case ctx.Err() == nil && (errors.Is(err, context.DeadlineExceeded) || errors.Is(err, taskname.ErrTimeout)):
+		api.Logger.Error(ctx, msg, slog.Error(err))
+		return errTaskNameTimeout
+	default:
+		api.Logger.Error(ctx, msg, slog.Error(err))
+		return nil
+	}
+}
+
 // This endpoint is experimental and not guaranteed to be stable, so we're not
 // generating public-facing documentation for it.
 func (api *API) tasksCreate(rw http.ResponseWriter, r *http.Request) {
@@ -124,21 +1122,6 @@ func (api *API) tasksCreate(rw http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if taskName == "" {
-		taskName = taskname.GenerateFallback()
-
-		if anthropicAPIKey := taskname.GetAnthropicAPIKeyFromEnv(); anthropicAPIKey != "" {
-			anthropicModel := taskname.GetAnthropicModelFromEnv()
-
-			generatedName, err := taskname.Generate(ctx, req.Prompt, taskname.WithAPIKey(anthropicAPIKey), taskname.WithModel(anthropicModel))
-			if err != nil {
-				api.Logger.Error(ctx, "unable to generate task name", slog.Error(err))
-			} else {
-				taskName = generatedName
-			}
-		}
-	}
-
 	createReq := codersdk.CreateWorkspaceRequest{
 		Name:                    taskName,
 		TemplateVersionID:       req.TemplateVersionID,
@@ -194,6 +1177,39 @@ func (api *API) tasksCreate(rw http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if taskName == "" {
+		existingNames, err := api.ownerWorkspaceNames(ctx, owner.ID)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching workspaces.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		nameOpts := api.aiTaskIssueOptions(ctx, apiKey.UserID, req.Prompt)
+		var orgID uuid.UUID
+		if api.aiTaskNameProvider != nil {
+			templateVersion, err := api.Database.GetTemplateVersionByID(ctx, req.TemplateVersionID)
+			if err != nil {
+				httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+					Message: "Internal error fetching template version.",
+					Detail:  err.Error(),
+				})
+				return
+			}
+			orgID = templateVersion.OrganizationID
+			nameOpts = append(nameOpts, api.aiTaskNameModels(ctx, orgID))
+		}
+		name, err := api.generateTaskName(ctx, orgID, req.Prompt, existingNames, nameOpts...)
+		// A task is not created from a prompt that moderation rejected,
+		// but it is given a random name if the model timed out.
+		if errors.Is(err, taskname.ErrPromptRejected) {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, aiTaskPromptRejectedResponse)
+			return
+		}
+		createReq.Name = name
+	}
+
 	aReq, commitAudit := audit.InitRequest[database.WorkspaceTable](rw, &audit.RequestParams{
 		Audit:   *auditor,
 		Log:     api.Logger,
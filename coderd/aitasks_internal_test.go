@@ -0,0 +1,48 @@
+package coderd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAITaskNameBatchCost(t *testing.T) {
+	t.Parallel()
+
+	batch := func(n int) string {
+		return `{"requests":[` + strings.TrimSuffix(strings.Repeat(`{"prompt":"fix the login bug"},`, n), ",") + `]}`
+	}
+
+	for _, tc := range []struct {
+		name string
+		body string
+		cost int
+	}{
+		{name: "Batch", body: batch(3), cost: 3},
+		{name: "OtherFieldsFirst", body: `{"other":{"requests":[1,2]},"requests":[{},{}]}`, cost: 2},
+		{name: "Empty", body: `{"requests":[]}`, cost: 1},
+		{name: "Invalid", body: `not json`, cost: 1},
+		{name: "TooMany", body: batch(maxAITaskNameBatchSize + 5), cost: maxAITaskNameBatchSize},
+		{name: "TooLarge", body: `{"requests":[{"prompt":"` + strings.Repeat("a", maxAITaskNameBatchBodySize) + `"}]}`, cost: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+			require.Equal(t, tc.cost, aiTaskNameBatchCost(r))
+
+			// The handler reads the whole body, unless it is over the cap.
+			body, err := io.ReadAll(r.Body)
+			if len(tc.body) > maxAITaskNameBatchBodySize {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.body, string(body))
+		})
+	}
+}
@@ -174,6 +174,45 @@ const docTemplate = `{
                 }
             }
         },
+        "/applications/cors-check": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Applications"
+                ],
+                "summary": "Check CORS behavior of a workspace app",
+                "operationId": "check-cors-behavior-of-a-workspace-app",
+                "parameters": [
+                    {
+                        "description": "CORS check request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WorkspaceAppCORSCheckRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WorkspaceAppCORSCheckResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/applications/host": {
             "get": {
                 "security": [
@@ -2882,6 +2921,86 @@ const docTemplate = `{
                 }
             }
         },
+        "/organizations/{organization}/cors-policy": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Get organization CORS policy for workspace apps",
+                "operationId": "get-organization-cors-policy-for-workspace-apps",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.OrganizationCORSSettings"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Update organization CORS policy for workspace apps",
+                "operationId": "update-organization-cors-policy-for-workspace-apps",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New settings",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.OrganizationCORSSettings"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.OrganizationCORSSettings"
+                        }
+                    }
+                }
+            }
+        },
         "/organizations/{organization}/groups": {
             "get": {
                 "security": [
@@ -11800,11 +11919,13 @@ const docTemplate = `{
             "type": "string",
             "enum": [
                 "simple",
-                "passthru"
+                "passthru",
+                "allowlist"
             ],
             "x-enum-varnames": [
                 "CORSBehaviorSimple",
-                "CORSBehaviorPassthru"
+                "CORSBehaviorPassthru",
+                "CORSBehaviorAllowlist"
             ]
         },
         "codersdk.ChangePasswordWithOneTimePasscodeRequest": {
@@ -12148,6 +12269,17 @@ const docTemplate = `{
                         }
                     ]
                 },
+                "cors_allow_credentials": {
+                    "description": "CORSAllowCredentials allows credentialed cross-origin requests to apps\nfrom the allowlisted origins. It requires the allowlist CORS behavior\nand can't be combined with wildcard origins.",
+                    "type": "boolean"
+                },
+                "cors_allowed_origins": {
+                    "description": "CORSAllowedOrigins are the origins allowed to make cross-origin requests\nto apps when CORSBehavior is allowlist. Origins are exact, e.g.\nhttps://app.example.com, or match any subdomain, e.g.\nhttps://*.example.com.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "cors_behavior": {
                     "description": "CORSBehavior allows optionally specifying the CORS behavior for all shared ports.",
                     "allOf": [
@@ -13039,6 +13171,9 @@ const docTemplate = `{
                 "wildcard_access_url": {
                     "type": "string"
                 },
+                "workspace_app_cors": {
+                    "$ref": "#/definitions/codersdk.WorkspaceAppCORSConfig"
+                },
                 "workspace_hostname_suffix": {
                     "type": "string"
                 },
@@ -14827,6 +14962,23 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.OrganizationCORSSettings": {
+            "type": "object",
+            "properties": {
+                "allow_looser_templates": {
+                    "description": "AllowLooserTemplates lets templates use a CORS behavior that is looser\nthan DefaultBehavior. When false, templates can't be set to a looser\nbehavior, and apps of templates that already use one are served with\nDefaultBehavior instead.",
+                    "type": "boolean"
+                },
+                "default_behavior": {
+                    "description": "DefaultBehavior is the CORS behavior of templates created without one.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.CORSBehavior"
+                        }
+                    ]
+                }
+            }
+        },
         "codersdk.OrganizationMember": {
             "type": "object",
             "properties": {
@@ -16278,7 +16430,10 @@ const docTemplate = `{
                 "idp_sync_settings_group",
                 "idp_sync_settings_role",
                 "workspace_agent",
-                "workspace_app"
+                "workspace_app",
+                "ai_request",
+                "organization_cors_settings",
+                "workspace_app_cors_preflight"
             ],
             "x-enum-varnames": [
                 "ResourceTypeTemplate",
@@ -16305,7 +16460,10 @@ const docTemplate = `{
                 "ResourceTypeIdpSyncSettingsGroup",
                 "ResourceTypeIdpSyncSettingsRole",
                 "ResourceTypeWorkspaceAgent",
-                "ResourceTypeWorkspaceApp"
+                "ResourceTypeWorkspaceApp",
+                "ResourceTypeAIRequest",
+                "ResourceTypeOrganizationCORSSettings",
+                "ResourceTypeWorkspaceAppCORSPreflight"
             ]
         },
         "codersdk.Response": {
@@ -16613,6 +16771,17 @@ const docTemplate = `{
                 "build_time_stats": {
                     "$ref": "#/definitions/codersdk.TemplateBuildTimeStats"
                 },
+                "cors_allow_credentials": {
+                    "description": "CORSAllowCredentials is true if apps allow credentialed cross-origin\nrequests from the allowlisted origins.",
+                    "type": "boolean"
+                },
+                "cors_allowed_origins": {
+                    "description": "CORSAllowedOrigins are the origins allowed to make cross-origin\nrequests to apps when CORSBehavior is allowlist.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "cors_behavior": {
                     "$ref": "#/definitions/codersdk.CORSBehavior"
                 },
@@ -17570,6 +17739,17 @@ const docTemplate = `{
                         }
                     ]
                 },
+                "cors_allow_credentials": {
+                    "description": "CORSAllowCredentials allows credentialed cross-origin requests to apps\nfrom the allowlisted origins. It requires the allowlist CORS behavior\nand can't be combined with wildcard origins.",
+                    "type": "boolean"
+                },
+                "cors_allowed_origins": {
+                    "description": "CORSAllowedOrigins replaces the origins allowed to make cross-origin\nrequests to apps when CORSBehavior is allowlist. Origins are exact,\ne.g. https://app.example.com, or match any subdomain, e.g.\nhttps://*.example.com.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "cors_behavior": {
                     "$ref": "#/definitions/codersdk.CORSBehavior"
                 },
@@ -18985,6 +19165,97 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.WorkspaceAppCORSCheckRequest": {
+            "type": "object",
+            "required": [
+                "app_url",
+                "origin"
+            ],
+            "properties": {
+                "app_url": {
+                    "description": "AppURL is the URL of the app, either a subdomain app on the deployment's\nwildcard hostname or a path-based app on the access URL.",
+                    "type": "string"
+                },
+                "method": {
+                    "description": "Method is the method of the request. Defaults to GET.",
+                    "type": "string"
+                },
+                "origin": {
+                    "description": "Origin is the origin the browser would send the request from, e.g.\nhttps://app.example.com.",
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.WorkspaceAppCORSCheckResponse": {
+            "type": "object",
+            "properties": {
+                "cors_behavior": {
+                    "$ref": "#/definitions/codersdk.CORSBehavior"
+                },
+                "message": {
+                    "description": "Message explains the result, e.g. that the app sets its own CORS\nheaders when the behavior is passthru.",
+                    "type": "string"
+                },
+                "preflight": {
+                    "description": "Preflight is the result of the OPTIONS request the browser sends before\nthe request.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.WorkspaceAppCORSCheckResult"
+                        }
+                    ]
+                },
+                "request": {
+                    "description": "Request is the result of the request itself.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.WorkspaceAppCORSCheckResult"
+                        }
+                    ]
+                }
+            }
+        },
+        "codersdk.WorkspaceAppCORSCheckResult": {
+            "type": "object",
+            "properties": {
+                "allowed": {
+                    "description": "Allowed is true if the browser would allow the origin to read the\nresponse.",
+                    "type": "boolean"
+                },
+                "headers": {
+                    "description": "Headers are the CORS headers coderd would send.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "codersdk.WorkspaceAppCORSConfig": {
+            "type": "object",
+            "properties": {
+                "allowed_headers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "allowed_methods": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "exposed_headers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "max_age": {
+                    "type": "integer"
+                }
+            }
+        },
         "codersdk.WorkspaceAppHealth": {
             "type": "string",
             "enum": [
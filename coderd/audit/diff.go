@@ -25,6 +25,9 @@ type Auditable interface {
 		database.OAuth2ProviderApp |
 		database.OAuth2ProviderAppSecret |
 		database.PrebuildsSettings |
+		database.AIRequest |
+		database.OrganizationCORSSettings |
+		database.WorkspaceAppCORSPreflight |
 		database.CustomRole |
 		database.AuditableOrganizationMember |
 		database.Organization |
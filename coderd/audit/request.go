@@ -113,6 +113,12 @@ func ResourceTarget[T Auditable](tgt T) string {
 		return "" // no target?
 	case database.PrebuildsSettings:
 		return "" // no target?
+	case database.AIRequest:
+		return typed.Feature
+	case database.OrganizationCORSSettings:
+		return "Organization CORS Policy"
+	case database.WorkspaceAppCORSPreflight:
+		return typed.App
 	case database.OAuth2ProviderApp:
 		return typed.Name
 	case database.OAuth2ProviderAppSecret:
@@ -175,6 +181,12 @@ func ResourceID[T Auditable](tgt T) uuid.UUID {
 	case database.PrebuildsSettings:
 		// Artificial ID for auditing purposes
 		return typed.ID
+	case database.AIRequest:
+		return typed.ID
+	case database.OrganizationCORSSettings:
+		return noID // Org field on audit log has org id
+	case database.WorkspaceAppCORSPreflight:
+		return typed.ID
 	case database.OAuth2ProviderApp:
 		return typed.ID
 	case database.OAuth2ProviderAppSecret:
@@ -228,6 +240,12 @@ func ResourceType[T Auditable](tgt T) database.ResourceType {
 		return database.ResourceTypeNotificationsSettings
 	case database.PrebuildsSettings:
 		return database.ResourceTypePrebuildsSettings
+	case database.AIRequest:
+		return database.ResourceTypeAiRequest
+	case database.OrganizationCORSSettings:
+		return database.ResourceTypeOrganizationCorsSettings
+	case database.WorkspaceAppCORSPreflight:
+		return database.ResourceTypeWorkspaceAppCorsPreflight
 	case database.OAuth2ProviderApp:
 		return database.ResourceTypeOauth2ProviderApp
 	case database.OAuth2ProviderAppSecret:
@@ -284,6 +302,12 @@ func ResourceRequiresOrgID[T Auditable]() bool {
 	case database.PrebuildsSettings:
 		// Artificial ID for auditing purposes
 		return false
+	case database.AIRequest:
+		return false
+	case database.OrganizationCORSSettings:
+		return true
+	case database.WorkspaceAppCORSPreflight:
+		return false
 	case database.OAuth2ProviderApp:
 		return false
 	case database.OAuth2ProviderAppSecret:
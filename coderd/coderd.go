@@ -32,6 +32,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/klauspost/compress/zstd"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/xerrors"
@@ -86,6 +87,7 @@ import (
 	"github.com/coder/coder/v2/coderd/rbac/policy"
 	"github.com/coder/coder/v2/coderd/rbac/rolestore"
 	"github.com/coder/coder/v2/coderd/schedule"
+	"github.com/coder/coder/v2/coderd/taskname"
 	"github.com/coder/coder/v2/coderd/telemetry"
 	"github.com/coder/coder/v2/coderd/tracing"
 	"github.com/coder/coder/v2/coderd/updatecheck"
@@ -222,6 +224,26 @@ type Options struct {
 	APIRateLimit   int
 	LoginRateLimit int
 	FilesRateLimit int
+	// AITaskNameRateLimit is the minutely rate limit per user for generating
+	// AI task names, and AITaskNameDeploymentRateLimit the minutely limit
+	// across the deployment. Generation calls a paid language model.
+	AITaskNameRateLimit           int
+	AITaskNameDeploymentRateLimit int
+	// AITaskNameConcurrency caps the number of concurrent language model
+	// calls made to generate AI task names. Setting it <0 removes the cap.
+	AITaskNameConcurrency int
+	// AITaskNameTokenRateLimit is the number of language model tokens a user
+	// can use per minute to generate AI task names, and
+	// AITaskNameDeploymentTokenRateLimit the number across the deployment.
+	AITaskNameTokenRateLimit           int64
+	AITaskNameDeploymentTokenRateLimit int64
+	// AITaskNameSystemPrompt replaces the built-in system prompt used to
+	// generate AI task names, if set.
+	AITaskNameSystemPrompt string
+	// AITaskPromptModerator checks AI task prompts before they are sent to
+	// a language model, e.g. with a provider's moderation API. If nil, the
+	// deny-list moderation in the deployment values is used.
+	AITaskPromptModerator taskname.Moderator
 
 	MetricsCacheRefreshInterval time.Duration
 	AgentStatsRefreshInterval   time.Duration
@@ -388,6 +410,21 @@ func New(options *Options) *API {
 	if options.FilesRateLimit == 0 {
 		options.FilesRateLimit = 12
 	}
+	if options.AITaskNameRateLimit == 0 {
+		options.AITaskNameRateLimit = 30
+	}
+	if options.AITaskNameDeploymentRateLimit == 0 {
+		options.AITaskNameDeploymentRateLimit = 600
+	}
+	if options.AITaskNameConcurrency == 0 {
+		options.AITaskNameConcurrency = 16
+	}
+	if options.AITaskNameTokenRateLimit == 0 {
+		options.AITaskNameTokenRateLimit = 20000
+	}
+	if options.AITaskNameDeploymentTokenRateLimit == 0 {
+		options.AITaskNameDeploymentTokenRateLimit = 400000
+	}
 	if options.Clock == nil {
 		options.Clock = quartz.NewReal()
 	}
@@ -614,6 +651,26 @@ func New(options *Options) *API {
 		),
 		dbRolluper: options.DatabaseRolluper,
 	}
+	if options.AITaskNameConcurrency > 0 {
+		api.aiTaskNameSem = make(chan struct{}, options.AITaskNameConcurrency)
+	}
+	api.aiTaskNameTokenBudget = taskname.NewTokenBudget(options.Clock, options.AITaskNameTokenRateLimit, options.AITaskNameDeploymentTokenRateLimit, time.Minute)
+	api.aiTaskNameTokens = promauto.With(options.PrometheusRegistry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: "coderd",
+		Subsystem: "ai_task_name",
+		Name:      "tokens_total",
+		Help:      "The total number of language model tokens used to generate AI task names.",
+	}, []string{"model", "type"})
+	api.aiTaskNameProvider = newAITaskNameProvider(ctx, options.Logger, options.DeploymentValues)
+	api.aiTaskPromptModerator = options.AITaskPromptModerator
+	if api.aiTaskPromptModerator == nil {
+		api.aiTaskPromptModerator = newAITaskPromptModerator(ctx, options.Logger, options.DeploymentValues)
+	}
+	if ttl := options.DeploymentValues.AI.TaskNameCacheTTL.Value(); ttl > 0 {
+		api.aiTaskNameCache = taskname.NewCache(options.PrometheusRegistry, 1024, ttl)
+	}
+	api.aiTaskNameModelPolicy = taskname.NewModelPolicy(options.RuntimeConfig)
+	api.CORSPolicy = workspaceapps.NewCORSPolicy(options.RuntimeConfig)
 	api.WorkspaceAppsProvider = workspaceapps.NewDBTokenProvider(
 		options.Logger.Named("workspaceapps"),
 		options.AccessURL,
@@ -625,6 +682,7 @@ func New(options *Options) *API {
 		options.AgentInactiveDisconnectTimeout,
 		options.WorkspaceAppAuditSessionTimeout,
 		options.AppSigningKeyCache,
+		api.CORSPolicy,
 	)
 
 	f := appearance.NewDefaultFetcher(api.DeploymentValues.DocsURL.String())
@@ -800,6 +858,9 @@ func New(options *Options) *API {
 
 		DisablePathApps:          options.DeploymentValues.DisablePathApps.Value(),
 		Cookies:                  options.DeploymentValues.HTTPCookies,
+		CORS:                     options.DeploymentValues.WorkspaceAppCORS,
+		Auditor:                  &api.Auditor,
+		CORSMetrics:              workspaceapps.NewCORSMetrics(options.PrometheusRegistry),
 		APIKeyEncryptionKeycache: options.AppEncryptionKeyCache,
 	}
 
@@ -1010,6 +1071,28 @@ func New(options *Options) *API {
 		r.Use(apiKeyMiddleware)
 		r.Route("/aitasks", func(r chi.Router) {
 			r.Get("/prompts", api.aiTasksPrompts)
+			// The endpoints that call a language model share one budget.
+			aiTaskNameLimits := []func(http.Handler) http.Handler{
+				httpmw.RateLimitByUser(options.AITaskNameRateLimit, time.Minute),
+				httpmw.RateLimitGlobal(options.AITaskNameDeploymentRateLimit, time.Minute),
+				api.aiTaskNameTokenLimit,
+			}
+			r.Group(func(r chi.Router) {
+				r.Use(aiTaskNameLimits...)
+				r.Post("/name", api.aiTasksName)
+				r.Post("/name/stream", api.aiTasksNameStream)
+				r.Post("/parameters", api.aiTasksParameters)
+			})
+			// Each request in a batch counts against the rate limits.
+			r.With(append([]func(http.Handler) http.Handler{
+				httpmw.RateLimitCost(aiTaskNameBatchCost),
+			}, aiTaskNameLimits...)...).Post("/name/batch", api.aiTasksNameBatch)
+			r.Get("/usage", api.aiTasksUsage)
+			r.Route("/organizations/{organization}/name-models", func(r chi.Router) {
+				r.Use(httpmw.ExtractOrganizationParam(options.Database))
+				r.Get("/", api.aiTaskNameModelSettings)
+				r.Patch("/", api.patchAITaskNameModelSettings)
+			})
 		})
 		r.Route("/tasks", func(r chi.Router) {
 			r.Use(apiRateLimiter)
@@ -1142,6 +1225,10 @@ func New(options *Options) *API {
 						})
 					})
 				})
+				r.Route("/cors-policy", func(r chi.Router) {
+					r.Get("/", api.organizationCORSSettings)
+					r.Patch("/", api.patchOrganizationCORSSettings)
+				})
 				r.Get("/paginated-members", api.paginatedMembers)
 				r.Route("/members", func(r chi.Router) {
 					r.Get("/", api.listMembers)
@@ -1485,6 +1572,10 @@ func New(options *Options) *API {
 				r.Use(apiKeyMiddleware)
 				r.Get("/", api.appHost)
 			})
+			r.Route("/cors-check", func(r chi.Router) {
+				r.Use(apiKeyMiddleware)
+				r.Post("/", api.workspaceAppCORSCheck)
+			})
 			r.Route("/auth-redirect", func(r chi.Router) {
 				// We want to redirect to login if they are not authenticated.
 				r.Use(apiKeyMiddlewareRedirect)
@@ -1738,6 +1829,9 @@ type API struct {
 	WorkspaceAppsProvider workspaceapps.SignedTokenProvider
 	workspaceAppServer    *workspaceapps.Server
 	agentProvider         workspaceapps.AgentProvider
+	// CORSPolicy stores the organizations' default CORS policies for
+	// workspace apps.
+	CORSPolicy *workspaceapps.CORSPolicy
 
 	// Experiments contains the list of experiments currently enabled.
 	// This is used to gate features that are not yet ready for production.
@@ -1752,6 +1846,28 @@ type API struct {
 	// dbRolluper rolls up template usage stats from raw agent and app
 	// stats. This is used to provide insights in the WebUI.
 	dbRolluper *dbrollup.Rolluper
+
+	// aiTaskNameSem bounds the number of concurrent language model calls
+	// made to generate task names. It is nil when there is no bound.
+	aiTaskNameSem chan struct{}
+	// aiTaskNameCache caches generated task names by prompt. It is nil when
+	// caching is disabled.
+	aiTaskNameCache *taskname.Cache
+	// aiTaskNameTokens counts the language model tokens used to generate
+	// task names.
+	aiTaskNameTokens *prometheus.CounterVec
+	// aiTaskNameProvider is the language model provider used to generate
+	// task names. It is nil when none is configured.
+	aiTaskNameProvider taskname.Provider
+	// aiTaskPromptModerator checks prompts before they are sent to the
+	// language model. It is nil when moderation is off.
+	aiTaskPromptModerator taskname.Moderator
+	// aiTaskNameModelPolicy stores the organizations' overrides of the models
+	// used to generate task names.
+	aiTaskNameModelPolicy *taskname.ModelPolicy
+	// aiTaskNameTokenBudget limits the language model tokens used to
+	// generate task names per minute.
+	aiTaskNameTokenBudget *taskname.TokenBudget
 }
 
 // Close waits for all WebSocket connections to drain before returning.
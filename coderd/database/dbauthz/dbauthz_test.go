@@ -4278,6 +4278,30 @@ func (s *MethodTestSuite) TestUserSecrets() {
 	}))
 }
 
+func (s *MethodTestSuite) TestAIUsage() {
+	s.Run("InsertAIUsage", s.Mocked(func(dbm *dbmock.MockStore, _ *gofakeit.Faker, check *expects) {
+		arg := database.InsertAIUsageParams{
+			ID:        uuid.New(),
+			CreatedAt: dbtime.Now(),
+			UserID:    uuid.New(),
+			Feature:   "task_name",
+			Model:     "claude-3-5-haiku-latest",
+		}
+		dbm.EXPECT().InsertAIUsage(gomock.Any(), arg).Return(nil).AnyTimes()
+		check.Args(arg).Asserts(rbac.ResourceSystem, policy.ActionCreate)
+	}))
+	s.Run("GetAIUsageSummary", s.Mocked(func(dbm *dbmock.MockStore, _ *gofakeit.Faker, check *expects) {
+		arg := database.GetAIUsageSummaryParams{}
+		dbm.EXPECT().GetAIUsageSummary(gomock.Any(), arg).Return([]database.GetAIUsageSummaryRow{}, nil).AnyTimes()
+		check.Args(arg).Asserts(rbac.ResourceTemplate, policy.ActionViewInsights)
+	}))
+	s.Run("OrganizationGetAIUsageSummary", s.Mocked(func(dbm *dbmock.MockStore, _ *gofakeit.Faker, check *expects) {
+		arg := database.GetAIUsageSummaryParams{OrganizationID: uuid.New()}
+		dbm.EXPECT().GetAIUsageSummary(gomock.Any(), arg).Return([]database.GetAIUsageSummaryRow{}, nil).AnyTimes()
+		check.Args(arg).Asserts(rbac.ResourceTemplate.InOrg(arg.OrganizationID), policy.ActionViewInsights)
+	}))
+}
+
 func (s *MethodTestSuite) TestUsageEvents() {
 	s.Run("InsertUsageEvent", s.Mocked(func(db *dbmock.MockStore, faker *gofakeit.Faker, check *expects) {
 		params := database.InsertUsageEventParams{
@@ -149,6 +149,8 @@ func Template(t testing.TB, db database.Store, seed database.Template) database.
 		MaxPortSharingLevel:          takeFirst(seed.MaxPortSharingLevel, database.AppSharingLevelOwner),
 		UseClassicParameterFlow:      takeFirst(seed.UseClassicParameterFlow, false),
 		CorsBehavior:                 takeFirst(seed.CorsBehavior, database.CorsBehaviorSimple),
+		CorsAllowedOrigins:           takeFirstSlice(seed.CorsAllowedOrigins, []string{}),
+		CorsAllowCredentials:         seed.CorsAllowCredentials,
 	})
 	require.NoError(t, err, "insert template")
 
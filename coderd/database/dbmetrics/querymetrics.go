@@ -586,6 +586,13 @@ func (m queryMetricsStore) FindMatchingPresetID(ctx context.Context, arg databas
 	return r0, r1
 }
 
+func (m queryMetricsStore) GetAIUsageSummary(ctx context.Context, arg database.GetAIUsageSummaryParams) ([]database.GetAIUsageSummaryRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetAIUsageSummary(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetAIUsageSummary").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m queryMetricsStore) GetAPIKeyByID(ctx context.Context, id string) (database.APIKey, error) {
 	start := time.Now()
 	apiKey, err := m.s.GetAPIKeyByID(ctx, id)
@@ -2168,6 +2175,13 @@ func (m queryMetricsStore) GetWorkspacesEligibleForTransition(ctx context.Contex
 	return workspaces, err
 }
 
+func (m queryMetricsStore) InsertAIUsage(ctx context.Context, arg database.InsertAIUsageParams) error {
+	start := time.Now()
+	r0 := m.s.InsertAIUsage(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertAIUsage").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m queryMetricsStore) InsertAPIKey(ctx context.Context, arg database.InsertAPIKeyParams) (database.APIKey, error) {
 	start := time.Now()
 	key, err := m.s.InsertAPIKey(ctx, arg)
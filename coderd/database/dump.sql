@@ -75,7 +75,8 @@ CREATE TYPE connection_type AS ENUM (
 
 CREATE TYPE cors_behavior AS ENUM (
     'simple',
-    'passthru'
+    'passthru',
+    'allowlist'
 );
 
 CREATE TYPE crypto_key_feature AS ENUM (
@@ -270,7 +271,10 @@ CREATE TYPE resource_type AS ENUM (
     'idp_sync_settings_role',
     'workspace_agent',
     'workspace_app',
-    'prebuilds_settings'
+    'prebuilds_settings',
+    'ai_request',
+    'organization_cors_settings',
+    'workspace_app_cors_preflight'
 );
 
 CREATE TYPE startup_script_behavior AS ENUM (
@@ -847,6 +851,23 @@ BEGIN
 END;
 $$;
 
+CREATE TABLE ai_usage (
+    id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    user_id uuid NOT NULL,
+    organization_id uuid,
+    feature text NOT NULL,
+    model text NOT NULL,
+    prompt_tokens bigint NOT NULL,
+    completion_tokens bigint NOT NULL
+);
+
+COMMENT ON TABLE ai_usage IS 'ai_usage records the language model tokens used by each call coderd makes on behalf of a user, so that AI spend can be attributed.';
+
+COMMENT ON COLUMN ai_usage.organization_id IS 'The organization the call was made for, or NULL if the request was not tied to one.';
+
+COMMENT ON COLUMN ai_usage.feature IS 'The feature that made the call, e.g. task_name or task_parameters.';
+
 CREATE TABLE api_keys (
     id text NOT NULL,
     hashed_secret bytea NOT NULL,
@@ -1822,7 +1843,9 @@ CREATE TABLE templates (
     activity_bump bigint DEFAULT '3600000000000'::bigint NOT NULL,
     max_port_sharing_level app_sharing_level DEFAULT 'owner'::app_sharing_level NOT NULL,
     use_classic_parameter_flow boolean DEFAULT false NOT NULL,
-    cors_behavior cors_behavior DEFAULT 'simple'::cors_behavior NOT NULL
+    cors_behavior cors_behavior DEFAULT 'simple'::cors_behavior NOT NULL,
+    cors_allowed_origins text[] DEFAULT '{}'::text[] NOT NULL,
+    cors_allow_credentials boolean DEFAULT false NOT NULL
 );
 
 COMMENT ON COLUMN templates.default_ttl IS 'The default duration for autostop for workspaces created from this template.';
@@ -1845,6 +1868,10 @@ COMMENT ON COLUMN templates.deprecated IS 'If set to a non empty string, the tem
 
 COMMENT ON COLUMN templates.use_classic_parameter_flow IS 'Determines whether to default to the dynamic parameter creation flow for this template or continue using the legacy classic parameter creation flow.This is a template wide setting, the template admin can revert to the classic flow if there are any issues. An escape hatch is required, as workspace creation is a core workflow and cannot break. This column will be removed when the dynamic parameter creation flow is stable.';
 
+COMMENT ON COLUMN templates.cors_allowed_origins IS 'The origins allowed to make cross-origin requests to the workspace apps of the template, when cors_behavior is allowlist.';
+
+COMMENT ON COLUMN templates.cors_allow_credentials IS 'Whether workspace apps of the template allow credentialed cross-origin requests from the allowlisted origins, when cors_behavior is allowlist.';
+
 CREATE VIEW template_with_names AS
  SELECT templates.id,
     templates.created_at,
@@ -1876,6 +1903,8 @@ CREATE VIEW template_with_names AS
     templates.max_port_sharing_level,
     templates.use_classic_parameter_flow,
     templates.cors_behavior,
+    templates.cors_allowed_origins,
+    templates.cors_allow_credentials,
     COALESCE(visible_users.avatar_url, ''::text) AS created_by_avatar_url,
     COALESCE(visible_users.username, ''::text) AS created_by_username,
     COALESCE(visible_users.name, ''::text) AS created_by_name,
@@ -2597,6 +2626,9 @@ ALTER TABLE ONLY workspace_resource_metadata ALTER COLUMN id SET DEFAULT nextval
 ALTER TABLE ONLY workspace_agent_stats
     ADD CONSTRAINT agent_stats_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY ai_usage
+    ADD CONSTRAINT ai_usage_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY api_keys
     ADD CONSTRAINT api_keys_pkey PRIMARY KEY (id);
 
@@ -2896,6 +2928,8 @@ CREATE INDEX idx_agent_stats_created_at ON workspace_agent_stats USING btree (cr
 
 CREATE INDEX idx_agent_stats_user_id ON workspace_agent_stats USING btree (user_id);
 
+CREATE INDEX idx_ai_usage_created_at ON ai_usage USING btree (created_at);
+
 CREATE UNIQUE INDEX idx_api_key_name ON api_keys USING btree (user_id, token_name) WHERE (login_type = 'token'::login_type);
 
 CREATE INDEX idx_api_keys_user ON api_keys USING btree (user_id);
@@ -3140,6 +3174,12 @@ COMMENT ON TRIGGER workspace_agent_name_unique_trigger ON workspace_agents IS 'U
 the uniqueness requirement. A trigger allows us to enforce uniqueness going
 forward without requiring a migration to clean up historical data.';
 
+ALTER TABLE ONLY ai_usage
+    ADD CONSTRAINT ai_usage_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY ai_usage
+    ADD CONSTRAINT ai_usage_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY api_keys
     ADD CONSTRAINT api_keys_user_id_uuid_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
 
@@ -0,0 +1,4 @@
+ALTER TYPE resource_type
+	ADD VALUE IF NOT EXISTS 'organization_cors_settings';
+ALTER TYPE resource_type
+	ADD VALUE IF NOT EXISTS 'workspace_app_cors_preflight';
@@ -0,0 +1,19 @@
+CREATE TABLE ai_usage (
+	id uuid NOT NULL,
+	created_at timestamp with time zone NOT NULL,
+	user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	organization_id uuid REFERENCES organizations (id) ON DELETE CASCADE,
+	feature text NOT NULL,
+	model text NOT NULL,
+	prompt_tokens bigint NOT NULL,
+	completion_tokens bigint NOT NULL,
+	PRIMARY KEY (id)
+);
+
+COMMENT ON TABLE ai_usage IS 'ai_usage records the language model tokens used by each call coderd makes on behalf of a user, so that AI spend can be attributed.';
+
+COMMENT ON COLUMN ai_usage.organization_id IS 'The organization the call was made for, or NULL if the request was not tied to one.';
+
+COMMENT ON COLUMN ai_usage.feature IS 'The feature that made the call, e.g. task_name or task_parameters.';
+
+CREATE INDEX idx_ai_usage_created_at ON ai_usage USING btree (created_at);
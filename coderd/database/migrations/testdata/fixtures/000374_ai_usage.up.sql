@@ -0,0 +1,20 @@
+INSERT INTO ai_usage (
+	id,
+	created_at,
+	user_id,
+	organization_id,
+	feature,
+	model,
+	prompt_tokens,
+	completion_tokens
+)
+VALUES (
+	'0b1a2f0e-5b1e-4d7c-9e3a-6c1f0d8a2b47',
+	'2025-09-01 12:00:00+00',
+	'30095c71-380b-457a-8995-97b8ee6e5307',
+	'bb640d07-ca8a-4869-b6bc-ae61ebb2fda1',
+	'task_name',
+	'claude-3-5-haiku-latest',
+	120,
+	12
+);
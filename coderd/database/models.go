@@ -562,8 +562,9 @@ func AllConnectionTypeValues() []ConnectionType {
 type CorsBehavior string
 
 const (
-	CorsBehaviorSimple   CorsBehavior = "simple"
-	CorsBehaviorPassthru CorsBehavior = "passthru"
+	CorsBehaviorSimple    CorsBehavior = "simple"
+	CorsBehaviorPassthru  CorsBehavior = "passthru"
+	CorsBehaviorAllowlist CorsBehavior = "allowlist"
 )
 
 func (e *CorsBehavior) Scan(src interface{}) error {
@@ -604,7 +605,8 @@ func (ns NullCorsBehavior) Value() (driver.Value, error) {
 func (e CorsBehavior) Valid() bool {
 	switch e {
 	case CorsBehaviorSimple,
-		CorsBehaviorPassthru:
+		CorsBehaviorPassthru,
+		CorsBehaviorAllowlist:
 		return true
 	}
 	return false
@@ -614,6 +616,7 @@ func AllCorsBehaviorValues() []CorsBehavior {
 	return []CorsBehavior{
 		CorsBehaviorSimple,
 		CorsBehaviorPassthru,
+		CorsBehaviorAllowlist,
 	}
 }
 
@@ -2100,6 +2103,9 @@ const (
 	ResourceTypeWorkspaceAgent              ResourceType = "workspace_agent"
 	ResourceTypeWorkspaceApp                ResourceType = "workspace_app"
 	ResourceTypePrebuildsSettings           ResourceType = "prebuilds_settings"
+	ResourceTypeAiRequest                   ResourceType = "ai_request"
+	ResourceTypeOrganizationCorsSettings    ResourceType = "organization_cors_settings"
+	ResourceTypeWorkspaceAppCorsPreflight   ResourceType = "workspace_app_cors_preflight"
 )
 
 func (e *ResourceType) Scan(src interface{}) error {
@@ -2163,7 +2169,10 @@ func (e ResourceType) Valid() bool {
 		ResourceTypeIdpSyncSettingsRole,
 		ResourceTypeWorkspaceAgent,
 		ResourceTypeWorkspaceApp,
-		ResourceTypePrebuildsSettings:
+		ResourceTypePrebuildsSettings,
+		ResourceTypeAiRequest,
+		ResourceTypeOrganizationCorsSettings,
+		ResourceTypeWorkspaceAppCorsPreflight:
 		return true
 	}
 	return false
@@ -2196,6 +2205,9 @@ func AllResourceTypeValues() []ResourceType {
 		ResourceTypeWorkspaceAgent,
 		ResourceTypeWorkspaceApp,
 		ResourceTypePrebuildsSettings,
+		ResourceTypeAiRequest,
+		ResourceTypeOrganizationCorsSettings,
+		ResourceTypeWorkspaceAppCorsPreflight,
 	}
 }
 
@@ -2955,6 +2967,20 @@ func AllWorkspaceTransitionValues() []WorkspaceTransition {
 	}
 }
 
+// ai_usage records the language model tokens used by each call coderd makes on behalf of a user, so that AI spend can be attributed.
+type AIUsage struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	// The organization the call was made for, or NULL if the request was not tied to one.
+	OrganizationID uuid.NullUUID `db:"organization_id" json:"organization_id"`
+	// The feature that made the call, e.g. task_name or task_parameters.
+	Feature          string `db:"feature" json:"feature"`
+	Model            string `db:"model" json:"model"`
+	PromptTokens     int64  `db:"prompt_tokens" json:"prompt_tokens"`
+	CompletionTokens int64  `db:"completion_tokens" json:"completion_tokens"`
+}
+
 type APIKey struct {
 	ID string `db:"id" json:"id"`
 	// hashed_secret contains a SHA256 hash of the key secret. This is considered a secret and MUST NOT be returned from the API as it is used for API key encryption in app proxying code.
@@ -3562,6 +3588,8 @@ type Template struct {
 	MaxPortSharingLevel           AppSharingLevel `db:"max_port_sharing_level" json:"max_port_sharing_level"`
 	UseClassicParameterFlow       bool            `db:"use_classic_parameter_flow" json:"use_classic_parameter_flow"`
 	CorsBehavior                  CorsBehavior    `db:"cors_behavior" json:"cors_behavior"`
+	CorsAllowedOrigins            []string        `db:"cors_allowed_origins" json:"cors_allowed_origins"`
+	CorsAllowCredentials          bool            `db:"cors_allow_credentials" json:"cors_allow_credentials"`
 	CreatedByAvatarURL            string          `db:"created_by_avatar_url" json:"created_by_avatar_url"`
 	CreatedByUsername             string          `db:"created_by_username" json:"created_by_username"`
 	CreatedByName                 string          `db:"created_by_name" json:"created_by_name"`
@@ -3611,6 +3639,10 @@ type TemplateTable struct {
 	// Determines whether to default to the dynamic parameter creation flow for this template or continue using the legacy classic parameter creation flow.This is a template wide setting, the template admin can revert to the classic flow if there are any issues. An escape hatch is required, as workspace creation is a core workflow and cannot break. This column will be removed when the dynamic parameter creation flow is stable.
 	UseClassicParameterFlow bool         `db:"use_classic_parameter_flow" json:"use_classic_parameter_flow"`
 	CorsBehavior            CorsBehavior `db:"cors_behavior" json:"cors_behavior"`
+	// The origins allowed to make cross-origin requests to the workspace apps of the template, when cors_behavior is allowlist.
+	CorsAllowedOrigins []string `db:"cors_allowed_origins" json:"cors_allowed_origins"`
+	// Whether workspace apps of the template allow credentialed cross-origin requests from the allowlisted origins, when cors_behavior is allowlist.
+	CorsAllowCredentials bool `db:"cors_allow_credentials" json:"cors_allow_credentials"`
 }
 
 // Records aggregated usage statistics for templates/users. All usage is rounded up to the nearest minute.
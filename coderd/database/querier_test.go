@@ -6780,3 +6780,58 @@ func TestUsageEventsTrigger(t *testing.T) {
 		require.Len(t, rows, 0)
 	})
 }
+
+func TestGetAIUsageSummary(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	db, _ := dbtestutil.NewDB(t)
+	org := dbgen.Organization(t, db, database.Organization{})
+	alice := dbgen.User(t, db, database.User{})
+	bob := dbgen.User(t, db, database.User{})
+
+	now := dbtime.Now()
+	insert := func(userID uuid.UUID, orgID uuid.NullUUID, feature string, createdAt time.Time, prompt, completion int64) {
+		t.Helper()
+		err := db.InsertAIUsage(ctx, database.InsertAIUsageParams{
+			ID:               uuid.New(),
+			CreatedAt:        createdAt,
+			UserID:           userID,
+			OrganizationID:   orgID,
+			Feature:          feature,
+			Model:            "claude-3-5-haiku-latest",
+			PromptTokens:     prompt,
+			CompletionTokens: completion,
+		})
+		require.NoError(t, err)
+	}
+	inOrg := uuid.NullUUID{UUID: org.ID, Valid: true}
+	insert(alice.ID, inOrg, "task_name", now, 100, 10)
+	insert(alice.ID, inOrg, "task_name", now, 50, 5)
+	insert(alice.ID, uuid.NullUUID{}, "task_name", now, 20, 2)
+	insert(bob.ID, inOrg, "task_parameters", now, 300, 30)
+	// Outside of the window.
+	insert(bob.ID, inOrg, "task_parameters", now.Add(-48*time.Hour), 1000, 100)
+
+	rows, err := db.GetAIUsageSummary(ctx, database.GetAIUsageSummaryParams{
+		StartTime:      now.Add(-time.Hour),
+		EndTime:        now.Add(time.Hour),
+		OrganizationID: org.ID,
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []database.GetAIUsageSummaryRow{
+		{UserID: alice.ID, OrganizationID: inOrg, Feature: "task_name", Model: "claude-3-5-haiku-latest", Calls: 2, PromptTokens: 150, CompletionTokens: 15},
+		{UserID: bob.ID, OrganizationID: inOrg, Feature: "task_parameters", Model: "claude-3-5-haiku-latest", Calls: 1, PromptTokens: 300, CompletionTokens: 30},
+	}, rows)
+
+	rows, err = db.GetAIUsageSummary(ctx, database.GetAIUsageSummaryParams{
+		StartTime: now.Add(-time.Hour),
+		EndTime:   now.Add(time.Hour),
+		UserID:    alice.ID,
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []database.GetAIUsageSummaryRow{
+		{UserID: alice.ID, OrganizationID: inOrg, Feature: "task_name", Model: "claude-3-5-haiku-latest", Calls: 2, PromptTokens: 150, CompletionTokens: 15},
+		{UserID: alice.ID, Feature: "task_name", Model: "claude-3-5-haiku-latest", Calls: 1, PromptTokens: 20, CompletionTokens: 2},
+	}, rows)
+}
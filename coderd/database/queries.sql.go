@@ -111,6 +111,131 @@ func (q *sqlQuerier) ActivityBumpWorkspace(ctx context.Context, arg ActivityBump
 	return err
 }
 
+const getAIUsageSummary = `-- name: GetAIUsageSummary :many
+SELECT
+    user_id,
+    organization_id,
+    feature,
+    model,
+    COUNT(*)::bigint AS calls,
+    COALESCE(SUM(prompt_tokens), 0)::bigint AS prompt_tokens,
+    COALESCE(SUM(completion_tokens), 0)::bigint AS completion_tokens
+FROM
+    ai_usage
+WHERE
+    created_at >= $1::timestamptz
+    AND created_at < $2::timestamptz
+    AND CASE
+        WHEN $3::uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN organization_id = $3
+        ELSE true
+    END
+    AND CASE
+        WHEN $4::uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN user_id = $4
+        ELSE true
+    END
+GROUP BY
+    user_id, organization_id, feature, model
+ORDER BY
+    user_id, organization_id, feature, model
+`
+
+type GetAIUsageSummaryParams struct {
+	StartTime      time.Time `db:"start_time" json:"start_time"`
+	EndTime        time.Time `db:"end_time" json:"end_time"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	UserID         uuid.UUID `db:"user_id" json:"user_id"`
+}
+
+type GetAIUsageSummaryRow struct {
+	UserID           uuid.UUID     `db:"user_id" json:"user_id"`
+	OrganizationID   uuid.NullUUID `db:"organization_id" json:"organization_id"`
+	Feature          string        `db:"feature" json:"feature"`
+	Model            string        `db:"model" json:"model"`
+	Calls            int64         `db:"calls" json:"calls"`
+	PromptTokens     int64         `db:"prompt_tokens" json:"prompt_tokens"`
+	CompletionTokens int64         `db:"completion_tokens" json:"completion_tokens"`
+}
+
+// GetAIUsageSummary sums the language model tokens used between start_time
+// and end_time by user, organization, feature and model. The organization and
+// user filters are ignored when they are the nil UUID.
+func (q *sqlQuerier) GetAIUsageSummary(ctx context.Context, arg GetAIUsageSummaryParams) ([]GetAIUsageSummaryRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAIUsageSummary,
+		arg.StartTime,
+		arg.EndTime,
+		arg.OrganizationID,
+		arg.UserID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAIUsageSummaryRow
+	for rows.Next() {
+		var i GetAIUsageSummaryRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.OrganizationID,
+			&i.Feature,
+			&i.Model,
+			&i.Calls,
+			&i.PromptTokens,
+			&i.CompletionTokens,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertAIUsage = `-- name: InsertAIUsage :exec
+INSERT INTO
+    ai_usage (
+        id,
+        created_at,
+        user_id,
+        organization_id,
+        feature,
+        model,
+        prompt_tokens,
+        completion_tokens
+    )
+VALUES
+    ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+type InsertAIUsageParams struct {
+	ID               uuid.UUID     `db:"id" json:"id"`
+	CreatedAt        time.Time     `db:"created_at" json:"created_at"`
+	UserID           uuid.UUID     `db:"user_id" json:"user_id"`
+	OrganizationID   uuid.NullUUID `db:"organization_id" json:"organization_id"`
+	Feature          string        `db:"feature" json:"feature"`
+	Model            string        `db:"model" json:"model"`
+	PromptTokens     int64         `db:"prompt_tokens" json:"prompt_tokens"`
+	CompletionTokens int64         `db:"completion_tokens" json:"completion_tokens"`
+}
+
+func (q *sqlQuerier) InsertAIUsage(ctx context.Context, arg InsertAIUsageParams) error {
+	_, err := q.db.ExecContext(ctx, insertAIUsage,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UserID,
+		arg.OrganizationID,
+		arg.Feature,
+		arg.Model,
+		arg.PromptTokens,
+		arg.CompletionTokens,
+	)
+	return err
+}
+
 const deleteAPIKeyByID = `-- name: DeleteAPIKeyByID :exec
 DELETE FROM
 	api_keys
@@ -12027,7 +12152,7 @@ func (q *sqlQuerier) GetTemplateAverageBuildTime(ctx context.Context, templateID
 
 const getTemplateByID = `-- name: GetTemplateByID :one
 SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, use_classic_parameter_flow, cors_behavior, created_by_avatar_url, created_by_username, created_by_name, organization_name, organization_display_name, organization_icon
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, use_classic_parameter_flow, cors_behavior, cors_allowed_origins, cors_allow_credentials, created_by_avatar_url, created_by_username, created_by_name, organization_name, organization_display_name, organization_icon
 FROM
 	template_with_names
 WHERE
@@ -12070,6 +12195,8 @@ func (q *sqlQuerier) GetTemplateByID(ctx context.Context, id uuid.UUID) (Templat
 		&i.MaxPortSharingLevel,
 		&i.UseClassicParameterFlow,
 		&i.CorsBehavior,
+		pq.Array(&i.CorsAllowedOrigins),
+		&i.CorsAllowCredentials,
 		&i.CreatedByAvatarURL,
 		&i.CreatedByUsername,
 		&i.CreatedByName,
@@ -12082,7 +12209,7 @@ func (q *sqlQuerier) GetTemplateByID(ctx context.Context, id uuid.UUID) (Templat
 
 const getTemplateByOrganizationAndName = `-- name: GetTemplateByOrganizationAndName :one
 SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, use_classic_parameter_flow, cors_behavior, created_by_avatar_url, created_by_username, created_by_name, organization_name, organization_display_name, organization_icon
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, use_classic_parameter_flow, cors_behavior, cors_allowed_origins, cors_allow_credentials, created_by_avatar_url, created_by_username, created_by_name, organization_name, organization_display_name, organization_icon
 FROM
 	template_with_names AS templates
 WHERE
@@ -12133,6 +12260,8 @@ func (q *sqlQuerier) GetTemplateByOrganizationAndName(ctx context.Context, arg G
 		&i.MaxPortSharingLevel,
 		&i.UseClassicParameterFlow,
 		&i.CorsBehavior,
+		pq.Array(&i.CorsAllowedOrigins),
+		&i.CorsAllowCredentials,
 		&i.CreatedByAvatarURL,
 		&i.CreatedByUsername,
 		&i.CreatedByName,
@@ -12144,7 +12273,7 @@ func (q *sqlQuerier) GetTemplateByOrganizationAndName(ctx context.Context, arg G
 }
 
 const getTemplates = `-- name: GetTemplates :many
-SELECT id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, use_classic_parameter_flow, cors_behavior, created_by_avatar_url, created_by_username, created_by_name, organization_name, organization_display_name, organization_icon FROM template_with_names AS templates
+SELECT id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, use_classic_parameter_flow, cors_behavior, cors_allowed_origins, cors_allow_credentials, created_by_avatar_url, created_by_username, created_by_name, organization_name, organization_display_name, organization_icon FROM template_with_names AS templates
 ORDER BY (name, id) ASC
 `
 
@@ -12188,6 +12317,8 @@ func (q *sqlQuerier) GetTemplates(ctx context.Context) ([]Template, error) {
 			&i.MaxPortSharingLevel,
 			&i.UseClassicParameterFlow,
 			&i.CorsBehavior,
+			pq.Array(&i.CorsAllowedOrigins),
+			&i.CorsAllowCredentials,
 			&i.CreatedByAvatarURL,
 			&i.CreatedByUsername,
 			&i.CreatedByName,
@@ -12210,7 +12341,7 @@ func (q *sqlQuerier) GetTemplates(ctx context.Context) ([]Template, error) {
 
 const getTemplatesWithFilter = `-- name: GetTemplatesWithFilter :many
 SELECT
-	t.id, t.created_at, t.updated_at, t.organization_id, t.deleted, t.name, t.provisioner, t.active_version_id, t.description, t.default_ttl, t.created_by, t.icon, t.user_acl, t.group_acl, t.display_name, t.allow_user_cancel_workspace_jobs, t.allow_user_autostart, t.allow_user_autostop, t.failure_ttl, t.time_til_dormant, t.time_til_dormant_autodelete, t.autostop_requirement_days_of_week, t.autostop_requirement_weeks, t.autostart_block_days_of_week, t.require_active_version, t.deprecated, t.activity_bump, t.max_port_sharing_level, t.use_classic_parameter_flow, t.cors_behavior, t.created_by_avatar_url, t.created_by_username, t.created_by_name, t.organization_name, t.organization_display_name, t.organization_icon
+	t.id, t.created_at, t.updated_at, t.organization_id, t.deleted, t.name, t.provisioner, t.active_version_id, t.description, t.default_ttl, t.created_by, t.icon, t.user_acl, t.group_acl, t.display_name, t.allow_user_cancel_workspace_jobs, t.allow_user_autostart, t.allow_user_autostop, t.failure_ttl, t.time_til_dormant, t.time_til_dormant_autodelete, t.autostop_requirement_days_of_week, t.autostop_requirement_weeks, t.autostart_block_days_of_week, t.require_active_version, t.deprecated, t.activity_bump, t.max_port_sharing_level, t.use_classic_parameter_flow, t.cors_behavior, t.cors_allowed_origins, t.cors_allow_credentials, t.created_by_avatar_url, t.created_by_username, t.created_by_name, t.organization_name, t.organization_display_name, t.organization_icon
 FROM
 	template_with_names AS t
 LEFT JOIN
@@ -12369,6 +12500,8 @@ func (q *sqlQuerier) GetTemplatesWithFilter(ctx context.Context, arg GetTemplate
 			&i.MaxPortSharingLevel,
 			&i.UseClassicParameterFlow,
 			&i.CorsBehavior,
+			pq.Array(&i.CorsAllowedOrigins),
+			&i.CorsAllowCredentials,
 			&i.CreatedByAvatarURL,
 			&i.CreatedByUsername,
 			&i.CreatedByName,
@@ -12408,10 +12541,12 @@ INSERT INTO
 		allow_user_cancel_workspace_jobs,
 		max_port_sharing_level,
 		use_classic_parameter_flow,
-		cors_behavior
+		cors_behavior,
+		cors_allowed_origins,
+		cors_allow_credentials
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 `
 
 type InsertTemplateParams struct {
@@ -12432,6 +12567,8 @@ type InsertTemplateParams struct {
 	MaxPortSharingLevel          AppSharingLevel `db:"max_port_sharing_level" json:"max_port_sharing_level"`
 	UseClassicParameterFlow      bool            `db:"use_classic_parameter_flow" json:"use_classic_parameter_flow"`
 	CorsBehavior                 CorsBehavior    `db:"cors_behavior" json:"cors_behavior"`
+	CorsAllowedOrigins           []string        `db:"cors_allowed_origins" json:"cors_allowed_origins"`
+	CorsAllowCredentials         bool            `db:"cors_allow_credentials" json:"cors_allow_credentials"`
 }
 
 func (q *sqlQuerier) InsertTemplate(ctx context.Context, arg InsertTemplateParams) error {
@@ -12453,6 +12590,8 @@ func (q *sqlQuerier) InsertTemplate(ctx context.Context, arg InsertTemplateParam
 		arg.MaxPortSharingLevel,
 		arg.UseClassicParameterFlow,
 		arg.CorsBehavior,
+		pq.Array(arg.CorsAllowedOrigins),
+		arg.CorsAllowCredentials,
 	)
 	return err
 }
@@ -12554,7 +12693,9 @@ SET
 	group_acl = $8,
 	max_port_sharing_level = $9,
 	use_classic_parameter_flow = $10,
-	cors_behavior = $11
+	cors_behavior = $11,
+	cors_allowed_origins = $12,
+	cors_allow_credentials = $13
 WHERE
 	id = $1
 `
@@ -12571,6 +12712,8 @@ type UpdateTemplateMetaByIDParams struct {
 	MaxPortSharingLevel          AppSharingLevel `db:"max_port_sharing_level" json:"max_port_sharing_level"`
 	UseClassicParameterFlow      bool            `db:"use_classic_parameter_flow" json:"use_classic_parameter_flow"`
 	CorsBehavior                 CorsBehavior    `db:"cors_behavior" json:"cors_behavior"`
+	CorsAllowedOrigins           []string        `db:"cors_allowed_origins" json:"cors_allowed_origins"`
+	CorsAllowCredentials         bool            `db:"cors_allow_credentials" json:"cors_allow_credentials"`
 }
 
 func (q *sqlQuerier) UpdateTemplateMetaByID(ctx context.Context, arg UpdateTemplateMetaByIDParams) error {
@@ -12586,6 +12729,8 @@ func (q *sqlQuerier) UpdateTemplateMetaByID(ctx context.Context, arg UpdateTempl
 		arg.MaxPortSharingLevel,
 		arg.UseClassicParameterFlow,
 		arg.CorsBehavior,
+		pq.Array(arg.CorsAllowedOrigins),
+		arg.CorsAllowCredentials,
 	)
 	return err
 }
@@ -20741,7 +20886,7 @@ LEFT JOIN LATERAL (
 ) latest_build ON TRUE
 LEFT JOIN LATERAL (
 	SELECT
-		id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, use_classic_parameter_flow, cors_behavior
+		id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, use_classic_parameter_flow, cors_behavior, cors_allowed_origins, cors_allow_credentials
 	FROM
 		templates
 	WHERE
@@ -0,0 +1,44 @@
+-- name: InsertAIUsage :exec
+INSERT INTO
+    ai_usage (
+        id,
+        created_at,
+        user_id,
+        organization_id,
+        feature,
+        model,
+        prompt_tokens,
+        completion_tokens
+    )
+VALUES
+    (@id, @created_at, @user_id, @organization_id, @feature, @model, @prompt_tokens, @completion_tokens);
+
+-- name: GetAIUsageSummary :many
+-- GetAIUsageSummary sums the language model tokens used between start_time
+-- and end_time by user, organization, feature and model. The organization and
+-- user filters are ignored when they are the nil UUID.
+SELECT
+    user_id,
+    organization_id,
+    feature,
+    model,
+    COUNT(*)::bigint AS calls,
+    COALESCE(SUM(prompt_tokens), 0)::bigint AS prompt_tokens,
+    COALESCE(SUM(completion_tokens), 0)::bigint AS completion_tokens
+FROM
+    ai_usage
+WHERE
+    created_at >= @start_time::timestamptz
+    AND created_at < @end_time::timestamptz
+    AND CASE
+        WHEN @organization_id::uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN organization_id = @organization_id
+        ELSE true
+    END
+    AND CASE
+        WHEN @user_id::uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN user_id = @user_id
+        ELSE true
+    END
+GROUP BY
+    user_id, organization_id, feature, model
+ORDER BY
+    user_id, organization_id, feature, model;
@@ -42,6 +42,45 @@ type PrebuildsSettings struct {
 	ReconciliationPaused bool      `db:"reconciliation_paused" json:"reconciliation_paused"`
 }
 
+// AIRequest is a call to a language model made on behalf of a user, e.g. to
+// generate a task name. It is only used for auditing.
+type AIRequest struct {
+	ID uuid.UUID `json:"id"`
+	// Feature is the AI feature the request was made for, e.g. "task_name".
+	Feature string `json:"feature"`
+	Model   string `json:"model"`
+	// PromptHash identifies the prompt without retaining it.
+	PromptHash string `json:"prompt_hash"`
+	// Prompt is the start of the prompt. It is empty if prompt retention is
+	// disabled.
+	Prompt    string `json:"prompt"`
+	Outcome   string `json:"outcome"`
+	LatencyMS int64  `json:"latency_ms"`
+}
+
+// OrganizationCORSSettings is an organization's default CORS policy for
+// workspace apps. It is only used for auditing.
+type OrganizationCORSSettings struct {
+	DefaultBehavior      CorsBehavior `json:"default_behavior"`
+	AllowLooserTemplates bool         `json:"allow_looser_templates"`
+}
+
+// WorkspaceAppCORSPreflight is a CORS preflight request served for a workspace
+// app with the allowlist or passthru CORS behavior. It is only used for
+// auditing.
+type WorkspaceAppCORSPreflight struct {
+	ID          uuid.UUID `json:"id"`
+	WorkspaceID uuid.UUID `json:"workspace_id"`
+	AgentID     uuid.UUID `json:"agent_id"`
+	// App is the slug or port of the app.
+	App      string       `json:"app"`
+	Behavior CorsBehavior `json:"cors_behavior"`
+	Origin   string       `json:"origin"`
+	// Method is the method the preflight asked to use.
+	Method  string `json:"method"`
+	Allowed bool   `json:"allowed"`
+}
+
 type Actions []policy.Action
 
 func (a *Actions) Scan(src interface{}) error {
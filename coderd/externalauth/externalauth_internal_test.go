@@ -183,3 +183,74 @@ func Test_bitbucketServerConfigDefaults(t *testing.T) {
 		})
 	}
 }
+
+func TestIssueAPIURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		typ      codersdk.EnhancedExternalAuthProvider
+		issueURL string
+		expected string
+	}{
+		{
+			name:     "GitHubIssue",
+			typ:      codersdk.EnhancedExternalAuthProviderGitHub,
+			issueURL: "https://github.com/coder/coder/issues/123",
+			expected: "https://api.github.com/repos/coder/coder/issues/123",
+		},
+		{
+			name:     "GitHubPull",
+			typ:      codersdk.EnhancedExternalAuthProviderGitHub,
+			issueURL: "https://github.com/coder/coder/pull/456/files",
+			expected: "https://api.github.com/repos/coder/coder/issues/456",
+		},
+		{
+			name:     "GitHubEnterprise",
+			typ:      codersdk.EnhancedExternalAuthProviderGitHub,
+			issueURL: "https://github.example.com/coder/coder/issues/1",
+			expected: "https://github.example.com/api/v3/repos/coder/coder/issues/1",
+		},
+		{
+			name:     "GitHubRepository",
+			typ:      codersdk.EnhancedExternalAuthProviderGitHub,
+			issueURL: "https://github.com/coder/coder",
+		},
+		{
+			name:     "GitHubNotNumber",
+			typ:      codersdk.EnhancedExternalAuthProviderGitHub,
+			issueURL: "https://github.com/coder/coder/issues/new",
+		},
+		{
+			name:     "GitLabIssue",
+			typ:      codersdk.EnhancedExternalAuthProviderGitLab,
+			issueURL: "https://gitlab.com/group/subgroup/project/-/issues/7",
+			expected: "https://gitlab.com/api/v4/projects/group%2Fsubgroup%2Fproject/issues/7",
+		},
+		{
+			name:     "GitLabMergeRequest",
+			typ:      codersdk.EnhancedExternalAuthProviderGitLab,
+			issueURL: "https://gitlab.example.com/group/project/-/merge_requests/8",
+			expected: "https://gitlab.example.com/api/v4/projects/group%2Fproject/merge_requests/8",
+		},
+		{
+			name:     "GitLabPipeline",
+			typ:      codersdk.EnhancedExternalAuthProviderGitLab,
+			issueURL: "https://gitlab.com/group/project/-/pipelines/9",
+		},
+		{
+			name:     "Unsupported",
+			typ:      codersdk.EnhancedExternalAuthProviderBitBucketCloud,
+			issueURL: "https://bitbucket.org/coder/coder/issues/1",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			apiURL, ok := issueAPIURL(tc.typ, tc.issueURL)
+			require.Equal(t, tc.expected != "", ok)
+			require.Equal(t, tc.expected, apiURL)
+		})
+	}
+}
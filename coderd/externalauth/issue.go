@@ -0,0 +1,125 @@
+package externalauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/v2/coderd/promoauth"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+// Issue is an issue, pull request or merge request on a Git provider.
+type Issue struct {
+	Title  string
+	Labels []string
+}
+
+// Issue fetches the issue, pull request or merge request at issueURL with the
+// given access token. It returns false if issueURL does not point to one on
+// this provider. Only GitHub and GitLab are supported.
+func (c *Config) Issue(ctx context.Context, token string, issueURL string) (*Issue, bool, error) {
+	if c.Regex == nil || !c.Regex.MatchString(issueURL) {
+		return nil, false, nil
+	}
+	apiURL, ok := issueAPIURL(codersdk.EnhancedExternalAuthProvider(c.Type), issueURL)
+	if !ok {
+		return nil, false, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	res, err := c.InstrumentedOAuth2Config.Do(ctx, promoauth.SourceGitAPIIssue, req)
+	if err != nil {
+		return nil, true, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(res.Body, failureReasonLimit))
+		return nil, true, xerrors.Errorf("status %d: body: %s", res.StatusCode, data)
+	}
+
+	issue := &Issue{}
+	switch codersdk.EnhancedExternalAuthProvider(c.Type) {
+	case codersdk.EnhancedExternalAuthProviderGitHub:
+		var ghIssue struct {
+			Title  string `json:"title"`
+			Labels []struct {
+				Name string `json:"name"`
+			} `json:"labels"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&ghIssue); err != nil {
+			return nil, true, xerrors.Errorf("decode issue: %w", err)
+		}
+		issue.Title = ghIssue.Title
+		for _, label := range ghIssue.Labels {
+			issue.Labels = append(issue.Labels, label.Name)
+		}
+	case codersdk.EnhancedExternalAuthProviderGitLab:
+		var glIssue struct {
+			Title  string   `json:"title"`
+			Labels []string `json:"labels"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&glIssue); err != nil {
+			return nil, true, xerrors.Errorf("decode issue: %w", err)
+		}
+		issue.Title = glIssue.Title
+		issue.Labels = glIssue.Labels
+	}
+	return issue, true, nil
+}
+
+// issueAPIURL returns the REST API URL of the issue, pull request or merge
+// request at issueURL, e.g.
+//
+//	https://github.com/coder/coder/issues/1 -> https://api.github.com/repos/coder/coder/issues/1
+//	https://gitlab.com/group/project/-/merge_requests/1 -> https://gitlab.com/api/v4/projects/group%2Fproject/merge_requests/1
+func issueAPIURL(typ codersdk.EnhancedExternalAuthProvider, issueURL string) (string, bool) {
+	u, err := url.Parse(issueURL)
+	if err != nil || u.Host == "" {
+		return "", false
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	switch typ {
+	case codersdk.EnhancedExternalAuthProviderGitHub:
+		// /{owner}/{repo}/{issues,pull}/{number}
+		if len(parts) < 4 || (parts[2] != "issues" && parts[2] != "pull") || !isNumber(parts[3]) {
+			return "", false
+		}
+		// Pull requests are issues as far as the API is concerned.
+		path := fmt.Sprintf("repos/%s/%s/issues/%s", parts[0], parts[1], parts[3])
+		if u.Host == "github.com" {
+			return "https://api.github.com/" + path, true
+		}
+		return fmt.Sprintf("%s://%s/api/v3/%s", u.Scheme, u.Host, path), true
+	case codersdk.EnhancedExternalAuthProviderGitLab:
+		// /{namespace...}/{project}/-/{issues,merge_requests}/{iid}
+		for i, part := range parts {
+			if part != "-" {
+				continue
+			}
+			if i == 0 || len(parts) < i+3 || (parts[i+1] != "issues" && parts[i+1] != "merge_requests") || !isNumber(parts[i+2]) {
+				return "", false
+			}
+			project := url.PathEscape(strings.Join(parts[:i], "/"))
+			return fmt.Sprintf("%s://%s/api/v4/projects/%s/%s/%s", u.Scheme, u.Host, project, parts[i+1], parts[i+2]), true
+		}
+	}
+	return "", false
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseUint(s, 10, 64)
+	return err == nil
+}
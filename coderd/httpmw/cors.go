@@ -9,6 +9,7 @@ import (
 	"github.com/go-chi/cors"
 
 	"github.com/coder/coder/v2/coderd/workspaceapps/appurl"
+	appcors "github.com/coder/coder/v2/coderd/workspaceapps/cors"
 )
 
 const (
@@ -91,32 +92,23 @@ func Cors(allowAll bool, origins ...string) func(next http.Handler) http.Handler
 	}
 }
 
-func WorkspaceAppCors(regex *regexp.Regexp, app appurl.ApplicationURL) func(next http.Handler) http.Handler {
-	return cors.Handler(cors.Options{
-		AllowOriginFunc: func(_ *http.Request, rawOrigin string) bool {
-			origin, err := url.Parse(rawOrigin)
-			if rawOrigin == "" || origin.Host == "" || err != nil {
-				return false
-			}
-			subdomain, ok := appurl.ExecuteHostnamePattern(regex, origin.Host)
-			if !ok {
-				return false
-			}
-			originApp, err := appurl.ParseSubdomainAppURL(subdomain)
-			if err != nil {
-				return false
-			}
-			return ok && originApp.Username == app.Username
-		},
-		AllowedMethods: []string{
-			http.MethodHead,
-			http.MethodGet,
-			http.MethodPost,
-			http.MethodPut,
-			http.MethodPatch,
-			http.MethodDelete,
-		},
-		AllowedHeaders:   []string{"*"},
-		AllowCredentials: true,
-	})
+func WorkspaceAppCors(regex *regexp.Regexp, app appurl.ApplicationURL, opts appcors.Options) func(next http.Handler) http.Handler {
+	handlerOpts := opts.HandlerOptions()
+	handlerOpts.AllowOriginFunc = func(_ *http.Request, rawOrigin string) bool {
+		origin, err := url.Parse(rawOrigin)
+		if rawOrigin == "" || origin.Host == "" || err != nil {
+			return false
+		}
+		subdomain, ok := appurl.ExecuteHostnamePattern(regex, origin.Host)
+		if !ok {
+			return false
+		}
+		originApp, err := appurl.ParseSubdomainAppURL(subdomain)
+		if err != nil {
+			return false
+		}
+		return ok && originApp.Username == app.Username
+	}
+	handlerOpts.AllowCredentials = true
+	return cors.Handler(handlerOpts)
 }
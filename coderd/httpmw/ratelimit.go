@@ -29,44 +29,109 @@ func RateLimit(count int, window time.Duration) func(http.Handler) http.Handler
 	return httprate.Limit(
 		count,
 		window,
-		httprate.WithKeyFuncs(func(r *http.Request) (string, error) {
-			// Prioritize by user, but fallback to IP.
-			apiKey, ok := r.Context().Value(apiKeyContextKey{}).(database.APIKey)
-			if !ok {
-				return httprate.KeyByIP(r)
-			}
+		httprate.WithKeyFuncs(rateLimitUserKey, httprate.KeyByEndpoint),
+		httprate.WithLimitHandler(func(w http.ResponseWriter, r *http.Request) {
+			httpapi.Write(r.Context(), w, http.StatusTooManyRequests, codersdk.Response{
+				Message: fmt.Sprintf("You've been rate limited for sending more than %v requests in %v.", count, window),
+			})
+		}),
+	)
+}
 
-			if ok, _ := strconv.ParseBool(r.Header.Get(codersdk.BypassRatelimitHeader)); !ok {
-				// No bypass attempt, just ratelimit.
-				return apiKey.UserID.String(), nil
-			}
+// RateLimitByUser is like RateLimit, but every endpoint it wraps shares one
+// limit per user. Use a single instance for a group of endpoints that draw on
+// the same budget.
+func RateLimitByUser(count int, window time.Duration) func(http.Handler) http.Handler {
+	// -1 is no rate limit
+	if count <= 0 {
+		return func(handler http.Handler) http.Handler {
+			return handler
+		}
+	}
 
-			// Allow Owner to bypass rate limiting for load tests
-			// and automation.
-			auth := UserAuthorization(r.Context())
+	return httprate.Limit(
+		count,
+		window,
+		httprate.WithKeyFuncs(rateLimitUserKey),
+		httprate.WithLimitHandler(func(w http.ResponseWriter, r *http.Request) {
+			httpapi.Write(r.Context(), w, http.StatusTooManyRequests, codersdk.Response{
+				Message: fmt.Sprintf("You've been rate limited for sending more than %v requests in %v.", count, window),
+			})
+		}),
+	)
+}
 
-			// We avoid using rbac.Authorizer since rego is CPU-intensive
-			// and undermines the DoS-prevention goal of the rate limiter.
-			for _, role := range auth.SafeRoleNames() {
-				if role == rbac.RoleOwner() {
-					// HACK: use a random key each time to
-					// de facto disable rate limiting. The
-					// `httprate` package has no
-					// support for selectively changing the limit
-					// for particular keys.
-					return cryptorand.String(16)
-				}
-			}
+// RateLimitGlobal returns a handler that limits requests per-minute across all
+// users and IPs. Every endpoint it wraps shares the one limit, so it protects
+// endpoints backed by an expensive shared resource.
+func RateLimitGlobal(count int, window time.Duration) func(http.Handler) http.Handler {
+	// -1 is no rate limit
+	if count <= 0 {
+		return func(handler http.Handler) http.Handler {
+			return handler
+		}
+	}
 
-			return apiKey.UserID.String(), xerrors.Errorf(
-				"%q provided but user is not %v",
-				codersdk.BypassRatelimitHeader, rbac.RoleOwner(),
-			)
-		}, httprate.KeyByEndpoint),
+	return httprate.Limit(
+		count,
+		window,
+		httprate.WithKeyFuncs(func(*http.Request) (string, error) {
+			return "global", nil
+		}),
 		httprate.WithLimitHandler(func(w http.ResponseWriter, r *http.Request) {
 			httpapi.Write(r.Context(), w, http.StatusTooManyRequests, codersdk.Response{
-				Message: fmt.Sprintf("You've been rate limited for sending more than %v requests in %v.", count, window),
+				Message: fmt.Sprintf("This deployment has received more than %v of these requests in %v. Try again later.", count, window),
 			})
 		}),
 	)
 }
+
+// rateLimitUserKey keys rate limits by user, falling back to IP.
+func rateLimitUserKey(r *http.Request) (string, error) {
+	// Prioritize by user, but fallback to IP.
+	apiKey, ok := r.Context().Value(apiKeyContextKey{}).(database.APIKey)
+	if !ok {
+		return httprate.KeyByIP(r)
+	}
+
+	if ok, _ := strconv.ParseBool(r.Header.Get(codersdk.BypassRatelimitHeader)); !ok {
+		// No bypass attempt, just ratelimit.
+		return apiKey.UserID.String(), nil
+	}
+
+	// Allow Owner to bypass rate limiting for load tests
+	// and automation.
+	auth := UserAuthorization(r.Context())
+
+	// We avoid using rbac.Authorizer since rego is CPU-intensive
+	// and undermines the DoS-prevention goal of the rate limiter.
+	for _, role := range auth.SafeRoleNames() {
+		if role == rbac.RoleOwner() {
+			// HACK: use a random key each time to
+			// de facto disable rate limiting. The
+			// `httprate` package has no
+			// support for selectively changing the limit
+			// for particular keys.
+			return cryptorand.String(16)
+		}
+	}
+
+	return apiKey.UserID.String(), xerrors.Errorf(
+		"%q provided but user is not %v",
+		codersdk.BypassRatelimitHeader, rbac.RoleOwner(),
+	)
+}
+
+// RateLimitCost returns a handler that makes a request count as cost(r)
+// requests against the rate limits that follow it. It is for endpoints that
+// do the work of several requests at once.
+func RateLimitCost(cost func(r *http.Request) int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			if n := cost(r); n > 1 {
+				r = r.WithContext(httprate.WithIncrement(r.Context(), n))
+			}
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
@@ -145,3 +146,98 @@ func TestRateLimit(t *testing.T) {
 		}
 	})
 }
+
+func TestRateLimitByUser(t *testing.T) {
+	t.Parallel()
+
+	rtr := chi.NewRouter()
+	limit := httpmw.RateLimitByUser(2, time.Minute)
+	rtr.With(limit).Get("/a", func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+	rtr.With(limit).Get("/b", func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	serve := func(path, remoteAddr string) int {
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		rtr.ServeHTTP(rec, req)
+		resp := rec.Result()
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// Both endpoints draw on the same limit.
+	addr := randRemoteAddr()
+	require.Equal(t, http.StatusOK, serve("/a", addr))
+	require.Equal(t, http.StatusOK, serve("/b", addr))
+	require.Equal(t, http.StatusTooManyRequests, serve("/a", addr))
+	require.Equal(t, http.StatusTooManyRequests, serve("/b", addr))
+	// Other callers have their own limit.
+	require.Equal(t, http.StatusOK, serve("/a", randRemoteAddr()))
+}
+
+func TestRateLimitGlobal(t *testing.T) {
+	t.Parallel()
+
+	rtr := chi.NewRouter()
+	limit := httpmw.RateLimitGlobal(2, time.Minute)
+	rtr.With(limit).Get("/a", func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+	rtr.With(limit).Get("/b", func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	// The limit is shared by all callers and endpoints, so neither random
+	// IPs nor other endpoints help.
+	for i := 0; i < 5; i++ {
+		req := httptest.NewRequest("GET", []string{"/a", "/b"}[i%2], nil)
+		req.RemoteAddr = randRemoteAddr()
+		rec := httptest.NewRecorder()
+		rtr.ServeHTTP(rec, req)
+		resp := rec.Result()
+		_ = resp.Body.Close()
+		require.Equal(t, i >= 2, resp.StatusCode == http.StatusTooManyRequests)
+		if i >= 2 {
+			require.NotEmpty(t, resp.Header.Get("Retry-After"))
+		}
+	}
+}
+
+func TestRateLimitCost(t *testing.T) {
+	t.Parallel()
+
+	rtr := chi.NewRouter()
+	rtr.Use(
+		httpmw.RateLimitCost(func(r *http.Request) int {
+			n, _ := strconv.Atoi(r.URL.Query().Get("cost"))
+			return n
+		}),
+		httpmw.RateLimitGlobal(5, time.Minute),
+	)
+	rtr.Get("/", func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	for _, tc := range []struct {
+		cost    int
+		limited bool
+	}{
+		{cost: 3},
+		// Only 2 requests are left, so this one is over the limit.
+		{cost: 3, limited: true},
+		{cost: 0},
+		{cost: 1},
+		{cost: 1, limited: true},
+	} {
+		req := httptest.NewRequest("GET", fmt.Sprintf("/?cost=%d", tc.cost), nil)
+		rec := httptest.NewRecorder()
+		rtr.ServeHTTP(rec, req)
+		resp := rec.Result()
+		_ = resp.Body.Close()
+		require.Equal(t, tc.limited, resp.StatusCode == http.StatusTooManyRequests, "cost %d", tc.cost)
+	}
+}
@@ -1,12 +1,18 @@
 package coderd
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 
+	"github.com/coder/coder/v2/coderd/audit"
 	"github.com/coder/coder/v2/coderd/database"
 	"github.com/coder/coder/v2/coderd/database/db2sdk"
+	"github.com/coder/coder/v2/coderd/database/dbauthz"
 	"github.com/coder/coder/v2/coderd/httpapi"
 	"github.com/coder/coder/v2/coderd/httpmw"
+	"github.com/coder/coder/v2/coderd/rbac/policy"
+	"github.com/coder/coder/v2/coderd/util/slice"
 	"github.com/coder/coder/v2/codersdk"
 )
 
@@ -49,3 +55,100 @@ func (*API) organization(rw http.ResponseWriter, r *http.Request) {
 
 	httpapi.Write(ctx, rw, http.StatusOK, db2sdk.Organization(organization))
 }
+
+// @Summary Get organization CORS policy for workspace apps
+// @ID get-organization-cors-policy-for-workspace-apps
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Organizations
+// @Param organization path string true "Organization ID" format(uuid)
+// @Success 200 {object} codersdk.OrganizationCORSSettings
+// @Router /organizations/{organization}/cors-policy [get]
+func (api *API) organizationCORSSettings(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	organization := httpmw.OrganizationParam(r)
+
+	//nolint:gocritic // Requires system context to read runtime config
+	settings, err := api.CORSPolicy.OrganizationSettings(dbauthz.AsSystemRestricted(ctx), api.Database, organization.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, settings)
+}
+
+// @Summary Update organization CORS policy for workspace apps
+// @ID update-organization-cors-policy-for-workspace-apps
+// @Security CoderSessionToken
+// @Produce json
+// @Accept json
+// @Tags Organizations
+// @Param organization path string true "Organization ID" format(uuid)
+// @Param request body codersdk.OrganizationCORSSettings true "New settings"
+// @Success 200 {object} codersdk.OrganizationCORSSettings
+// @Router /organizations/{organization}/cors-policy [patch]
+func (api *API) patchOrganizationCORSSettings(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	organization := httpmw.OrganizationParam(r)
+	auditor := *api.Auditor.Load()
+	aReq, commitAudit := audit.InitRequest[database.OrganizationCORSSettings](rw, &audit.RequestParams{
+		Audit:          auditor,
+		Log:            api.Logger,
+		Request:        r,
+		Action:         database.AuditActionWrite,
+		OrganizationID: organization.ID,
+	})
+	defer commitAudit()
+
+	if !api.Authorize(r, policy.ActionUpdate, organization) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	var req codersdk.OrganizationCORSSettings
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if !database.CorsBehavior(req.DefaultBehavior).Valid() {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid request to update organization CORS policy.",
+			Validations: []codersdk.ValidationError{{
+				Field:  "default_behavior",
+				Detail: fmt.Sprintf("Invalid CORS behavior %q. Must be one of [%s]", req.DefaultBehavior, strings.Join(slice.ToStrings(database.AllCorsBehaviorValues()), ", ")),
+			}},
+		})
+		return
+	}
+
+	//nolint:gocritic // Requires system context to update runtime config
+	sysCtx := dbauthz.AsSystemRestricted(ctx)
+	existing, err := api.CORSPolicy.OrganizationSettings(sysCtx, api.Database, organization.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	aReq.Old = auditableCORSSettings(existing)
+
+	err = api.CORSPolicy.UpdateOrganizationSettings(sysCtx, api.Database, organization.ID, req)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	settings, err := api.CORSPolicy.OrganizationSettings(sysCtx, api.Database, organization.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	aReq.New = auditableCORSSettings(settings)
+
+	httpapi.Write(ctx, rw, http.StatusOK, settings)
+}
+
+func auditableCORSSettings(settings codersdk.OrganizationCORSSettings) database.OrganizationCORSSettings {
+	return database.OrganizationCORSSettings{
+		DefaultBehavior:      database.CorsBehavior(settings.DefaultBehavior),
+		AllowLooserTemplates: settings.AllowLooserTemplates,
+	}
+}
@@ -24,6 +24,7 @@ const (
 	SourceGitAPIListEmails      Oauth2Source = "GitAPIListEmails"
 	SourceGitAPIOrgMemberships  Oauth2Source = "GitAPIOrgMemberships"
 	SourceGitAPITeamMemberships Oauth2Source = "GitAPITeamMemberships"
+	SourceGitAPIIssue           Oauth2Source = "GitAPIIssue"
 )
 
 // OAuth2Config exposes a subset of *oauth2.Config functions for easier testing.
@@ -0,0 +1,85 @@
+package taskname
+
+import (
+	"crypto/sha256"
+	"strings"
+	"time"
+
+	"github.com/ammario/tlru"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Cache remembers the names generated for recent prompts so that repeated
+// requests, such as a UI regenerating a name on every pause in typing, skip
+// the language model call. It stores the names before their random suffix
+// is added, so every hit still returns fresh workspace names.
+//
+// Cache is safe for concurrent use.
+type Cache struct {
+	cache *tlru.Cache[[32]byte, []string]
+	ttl   time.Duration
+
+	hits   prometheus.Counter
+	misses prometheus.Counter
+}
+
+// NewCache returns a cache holding up to size prompts, each for ttl.
+func NewCache(registerer prometheus.Registerer, size int, ttl time.Duration) *Cache {
+	f := promauto.With(registerer)
+	return &Cache{
+		cache: tlru.New[[32]byte](tlru.ConstantCost[[]string], size),
+		ttl:   ttl,
+		hits: f.NewCounter(prometheus.CounterOpts{
+			Namespace: "coderd",
+			Subsystem: "ai_task_name_cache",
+			Name:      "hits_total",
+			Help:      "The total number of task name generations served from the cache.",
+		}),
+		misses: f.NewCounter(prometheus.CounterOpts{
+			Namespace: "coderd",
+			Subsystem: "ai_task_name_cache",
+			Name:      "misses_total",
+			Help:      "The total number of task name generations that called the language model.",
+		}),
+	}
+}
+
+// get returns the cached base names for key if there are at least count.
+func (c *Cache) get(key [32]byte, count int) ([]string, bool) {
+	bases, _, ok := c.cache.Get(key)
+	if !ok || len(bases) < count {
+		c.misses.Inc()
+		return nil, false
+	}
+	c.hits.Inc()
+	return bases, true
+}
+
+func (c *Cache) set(key [32]byte, bases []string) {
+	c.cache.Set(key, bases, c.ttl)
+}
+
+// cacheKey hashes everything that influences the names the model generates.
+// Prompts are normalized so that differences in case and whitespace share
+// an entry.
+func cacheKey(o options, prompt string) [32]byte {
+	h := sha256.New()
+	write := func(s string) {
+		_, _ = h.Write([]byte(s))
+		_, _ = h.Write([]byte{0})
+	}
+	write(strings.Join(strings.Fields(strings.ToLower(prompt)), " "))
+	write(o.templateName)
+	write(o.repositoryURL)
+	write(o.issueTitle)
+	write(strings.Join(o.issueLabels, ","))
+	write(o.system())
+	for _, model := range o.models {
+		write(string(model))
+	}
+
+	var key [32]byte
+	h.Sum(key[:0])
+	return key
+}
@@ -0,0 +1,63 @@
+package taskname
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/xerrors"
+)
+
+// WithDenyList rejects generated names containing any of the given words,
+// e.g. profanity or internal project names. Words are matched
+// case-insensitively against the hyphen-separated parts of a name.
+func WithDenyList(words ...string) Option {
+	return func(o *options) {
+		if o.denyList == nil {
+			o.denyList = make(map[string]struct{}, len(words))
+		}
+		for _, word := range words {
+			word = strings.ToLower(strings.TrimSpace(word))
+			if word != "" {
+				o.denyList[word] = struct{}{}
+			}
+		}
+	}
+}
+
+// sanitize strips characters the model may wrap a name in, or that it should
+// never produce, from a raw model response.
+func sanitize(taskName string) string {
+	taskName = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, taskName)
+	return strings.Trim(strings.TrimSpace(taskName), "\"'`")
+}
+
+// checkDenied returns an error if the name contains a denied word.
+func (o options) checkDenied(taskName string) error {
+	if len(o.denyList) == 0 {
+		return nil
+	}
+	for _, word := range strings.Split(strings.ToLower(taskName), "-") {
+		if _, ok := o.denyList[word]; ok {
+			return xerrors.Errorf("generated name %v contains the disallowed word %q", taskName, word)
+		}
+	}
+	return nil
+}
+
+// truncate shortens s to at most n bytes without splitting a UTF-8 encoded
+// rune.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,88 @@
+package taskname
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/runtimeconfig"
+	"github.com/coder/coder/v2/coderd/util/ptr"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+// ModelPolicy stores each organization's override of the models used to
+// generate task names in runtime config.
+type ModelPolicy struct {
+	Manager  *runtimeconfig.Manager
+	Settings runtimeconfig.RuntimeEntry[*OrganizationModelSettings]
+}
+
+func NewModelPolicy(manager *runtimeconfig.Manager) *ModelPolicy {
+	return &ModelPolicy{
+		Manager:  manager,
+		Settings: runtimeconfig.MustNew[*OrganizationModelSettings]("ai-task-name-model-settings"),
+	}
+}
+
+// OrganizationSettings returns the organization's model settings. They are
+// empty if it hasn't configured any.
+func (p *ModelPolicy) OrganizationSettings(ctx context.Context, db database.Store, orgID uuid.UUID) (codersdk.AITaskNameModelSettings, error) {
+	settings, err := p.Settings.Resolve(ctx, p.Manager.OrganizationResolver(db, orgID))
+	if err != nil {
+		if xerrors.Is(err, runtimeconfig.ErrEntryNotFound) {
+			return codersdk.AITaskNameModelSettings{Models: []string{}}, nil
+		}
+		return codersdk.AITaskNameModelSettings{}, xerrors.Errorf("resolve organization task name model settings: %w", err)
+	}
+	if settings.Models == nil {
+		settings.Models = []string{}
+	}
+	return codersdk.AITaskNameModelSettings(*settings), nil
+}
+
+func (p *ModelPolicy) UpdateOrganizationSettings(ctx context.Context, db database.Store, orgID uuid.UUID, settings codersdk.AITaskNameModelSettings) error {
+	err := p.Settings.SetRuntimeValue(ctx, p.Manager.OrganizationResolver(db, orgID), ptr.Ref(OrganizationModelSettings(settings)))
+	if err != nil {
+		return xerrors.Errorf("update organization task name model settings: %w", err)
+	}
+	return nil
+}
+
+// Models returns the fallback chain of models to generate task names with
+// for the organization: its override if it has one, or else the chain
+// configured for the deployment with GetModelsFromEnv.
+func (p *ModelPolicy) Models(ctx context.Context, db database.Store, orgID uuid.UUID) ([]string, error) {
+	settings, err := p.OrganizationSettings(ctx, db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return OrganizationModels(settings), nil
+}
+
+// OrganizationModels returns the models in settings, or the deployment's
+// models from GetModelsFromEnv if there are none.
+func OrganizationModels(settings codersdk.AITaskNameModelSettings) []string {
+	var models []string
+	for _, model := range settings.Models {
+		if model != "" {
+			models = append(models, model)
+		}
+	}
+	if len(models) == 0 {
+		return GetModelsFromEnv()
+	}
+	return models
+}
+
+type OrganizationModelSettings codersdk.AITaskNameModelSettings
+
+func (s *OrganizationModelSettings) Set(v string) error {
+	return json.Unmarshal([]byte(v), s)
+}
+
+func (s *OrganizationModelSettings) String() string {
+	return runtimeconfig.JSONString(s)
+}
@@ -0,0 +1,53 @@
+package taskname_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbgen"
+	"github.com/coder/coder/v2/coderd/database/dbtestutil"
+	"github.com/coder/coder/v2/coderd/runtimeconfig"
+	"github.com/coder/coder/v2/coderd/taskname"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestModelPolicy(t *testing.T) {
+	t.Setenv(taskname.TaskNameModelEnv, "claude-deployment")
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	db, _ := dbtestutil.NewDB(t)
+	org := dbgen.Organization(t, db, database.Organization{})
+	other := dbgen.Organization(t, db, database.Organization{})
+	policy := taskname.NewModelPolicy(runtimeconfig.NewManager())
+
+	// Without an override, the deployment's models are used.
+	settings, err := policy.OrganizationSettings(ctx, db, org.ID)
+	require.NoError(t, err)
+	require.Empty(t, settings.Models)
+	models, err := policy.Models(ctx, db, org.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"claude-deployment"}, models)
+
+	err = policy.UpdateOrganizationSettings(ctx, db, org.ID, codersdk.AITaskNameModelSettings{
+		Models: []string{"claude-a", "claude-b"},
+	})
+	require.NoError(t, err)
+	models, err = policy.Models(ctx, db, org.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"claude-a", "claude-b"}, models)
+
+	// Other organizations are unaffected.
+	models, err = policy.Models(ctx, db, other.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"claude-deployment"}, models)
+
+	// Clearing the override restores the deployment's models.
+	err = policy.UpdateOrganizationSettings(ctx, db, org.ID, codersdk.AITaskNameModelSettings{})
+	require.NoError(t, err)
+	models, err = policy.Models(ctx, db, org.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"claude-deployment"}, models)
+}
@@ -0,0 +1,102 @@
+package taskname
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// ErrPromptRejected is returned when moderation rejects a task prompt.
+var ErrPromptRejected = xerrors.New("prompt rejected by moderation")
+
+// Moderator checks a task prompt before it is sent to the language model. It
+// returns the prompt to use, which may be sanitized, or an error wrapping
+// ErrPromptRejected if the prompt must not be used at all.
+type Moderator func(ctx context.Context, prompt string) (string, error)
+
+// WithModerator runs prompts through m before any language model call. Each
+// moderator is run in the order given, on the output of the previous one.
+func WithModerator(m Moderator) Option {
+	return func(o *options) {
+		if m != nil {
+			o.moderators = append(o.moderators, m)
+		}
+	}
+}
+
+// ModerationAction is what a deny-list moderator does with a prompt that
+// matches one of its patterns.
+type ModerationAction string
+
+const (
+	ModerationOff      ModerationAction = "off"
+	ModerationSanitize ModerationAction = "sanitize"
+	ModerationReject   ModerationAction = "reject"
+)
+
+// promptInjectionPatterns match common attempts to override the instructions
+// given to the model. They are always part of a deny-list moderator.
+var promptInjectionPatterns = []string{
+	`\b(ignore|disregard|forget|override)\b[^.\n]{0,40}\b(instructions|rules|prompts?|directions)\b`,
+	`\byou are (now|no longer)\b`,
+	`<\|?(system|im_start|im_end)\|?>`,
+}
+
+// NewDenyListModerator returns a moderator that matches prompts against the
+// built-in prompt injection patterns and the given regular expressions, which
+// are case-insensitive. Matching prompts are rejected, or have the matches
+// removed, depending on action. It returns nil if action is ModerationOff.
+func NewDenyListModerator(patterns []string, action ModerationAction) (Moderator, error) {
+	switch action {
+	case ModerationOff:
+		return nil, nil
+	case ModerationSanitize, ModerationReject:
+	default:
+		return nil, xerrors.Errorf("unknown moderation action %q", action)
+	}
+
+	denied := make([]*regexp.Regexp, 0, len(promptInjectionPatterns)+len(patterns))
+	for _, pattern := range slices.Concat(promptInjectionPatterns, patterns) {
+		if strings.TrimSpace(pattern) == "" {
+			continue
+		}
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, xerrors.Errorf("compile deny-list pattern %q: %w", pattern, err)
+		}
+		denied = append(denied, re)
+	}
+
+	return func(_ context.Context, prompt string) (string, error) {
+		sanitized := false
+		for _, re := range denied {
+			if !re.MatchString(prompt) {
+				continue
+			}
+			if action == ModerationReject {
+				return "", xerrors.Errorf("prompt matches %q: %w", re.String(), ErrPromptRejected)
+			}
+			prompt = re.ReplaceAllString(prompt, "")
+			sanitized = true
+		}
+		if sanitized && strings.TrimSpace(prompt) == "" {
+			return "", xerrors.Errorf("nothing left after sanitizing: %w", ErrPromptRejected)
+		}
+		return prompt, nil
+	}, nil
+}
+
+// moderate runs the prompt through the configured moderators.
+func (o options) moderate(ctx context.Context, prompt string) (string, error) {
+	for _, m := range o.moderators {
+		var err error
+		prompt, err = m(ctx, prompt)
+		if err != nil {
+			return "", err
+		}
+	}
+	return prompt, nil
+}
@@ -0,0 +1,216 @@
+package taskname
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/aisdk-go"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+const parametersSystemPrompt = `Suggest values for the parameters of a workspace that will run an AI task.
+
+You are given the task prompt and the workspace template's parameters as JSON.
+Only suggest a value for a parameter if the prompt clearly implies it, e.g. a
+repository URL, a region or an instance size. When a parameter has options,
+the value must be one of the option values.
+
+Respond with a single JSON object that maps parameter names to values, and
+nothing else. Respond with {} if no values are implied.`
+
+// maxParametersTokens bounds the response when suggesting parameter values.
+const maxParametersTokens = 1024
+
+// SuggestParameters suggests values for the rich parameters of a template from
+// an AI task prompt. Only values that pass the parameter's validation are
+// returned, keyed by parameter name. The prompt parameter is never suggested,
+// as it is set from the prompt itself.
+//
+// Naming options, such as WithAvoidNames, are ignored. Moderators are run on
+// the prompt.
+func SuggestParameters(ctx context.Context, prompt string, params []codersdk.TemplateVersionParameter, opts ...Option) (map[string]string, error) {
+	o, provider, err := newOptions(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	// Suggestions are parsed as a whole, so there is nothing to stream.
+	o.streamFn = nil
+	prompt, err = o.moderate(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]codersdk.TemplateVersionParameter, 0, len(params))
+	for _, param := range params {
+		if param.Name == codersdk.AITaskPromptParameterName {
+			continue
+		}
+		candidates = append(candidates, param)
+	}
+	if len(candidates) == 0 {
+		return map[string]string{}, nil
+	}
+
+	conversation, err := parametersConversation(prompt, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, model := range o.models {
+		response, err := call(ctx, provider, model, o, conversation, maxParametersTokens)
+		if err == nil {
+			return parseSuggestions(response, candidates)
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		errs = append(errs, xerrors.Errorf("model %q: %w", model, err))
+	}
+	return nil, errors.Join(errs...)
+}
+
+// parametersConversation describes the parameters to the model. Only the
+// fields that help the model pick a value are included.
+func parametersConversation(prompt string, params []codersdk.TemplateVersionParameter) ([]aisdk.Message, error) {
+	type option struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	type parameter struct {
+		Name         string   `json:"name"`
+		DisplayName  string   `json:"display_name,omitempty"`
+		Description  string   `json:"description,omitempty"`
+		Type         string   `json:"type"`
+		DefaultValue string   `json:"default_value,omitempty"`
+		Options      []option `json:"options,omitempty"`
+	}
+	described := make([]parameter, 0, len(params))
+	for _, param := range params {
+		p := parameter{
+			Name:         param.Name,
+			DisplayName:  param.DisplayName,
+			Description:  param.DescriptionPlaintext,
+			Type:         param.Type,
+			DefaultValue: param.DefaultValue,
+		}
+		if p.Description == "" {
+			p.Description = param.Description
+		}
+		for _, opt := range param.Options {
+			p.Options = append(p.Options, option{Name: opt.Name, Value: opt.Value})
+		}
+		described = append(described, p)
+	}
+	data, err := json.Marshal(described)
+	if err != nil {
+		return nil, xerrors.Errorf("marshal parameters: %w", err)
+	}
+
+	return []aisdk.Message{
+		{
+			Role: "system",
+			Parts: []aisdk.Part{{
+				Type: aisdk.PartTypeText,
+				Text: parametersSystemPrompt,
+			}},
+		},
+		{
+			Role: "user",
+			Parts: []aisdk.Part{{
+				Type: aisdk.PartTypeText,
+				Text: fmt.Sprintf("Task prompt:\n%s\n\nParameters:\n%s", prompt, data),
+			}},
+		},
+	}, nil
+}
+
+// parseSuggestions extracts the JSON object from the model's response and
+// keeps the values that are valid for their parameter. Models sometimes wrap
+// the object in prose or code fences, so everything outside it is ignored.
+func parseSuggestions(response string, params []codersdk.TemplateVersionParameter) (map[string]string, error) {
+	start, end := strings.Index(response, "{"), strings.LastIndex(response, "}")
+	if start < 0 || end < start {
+		return nil, xerrors.Errorf("no json object in response %q", response)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(response[start:end+1]), &raw); err != nil {
+		return nil, xerrors.Errorf("decode suggestions: %w", err)
+	}
+
+	suggestions := make(map[string]string, len(raw))
+	for _, param := range params {
+		value, ok := raw[param.Name]
+		if !ok {
+			continue
+		}
+		str, ok := suggestionValue(value)
+		if !ok || str == "" || !validType(param.Type, str) {
+			continue
+		}
+		err := codersdk.ValidateWorkspaceBuildParameter(param, &codersdk.WorkspaceBuildParameter{
+			Name:  param.Name,
+			Value: str,
+		}, nil)
+		if err != nil {
+			continue
+		}
+		suggestions[param.Name] = str
+	}
+	return suggestions, nil
+}
+
+// suggestionValue converts a suggested JSON value to a parameter value.
+// Strings are used as is, numbers and booleans are formatted, and lists are
+// encoded as the JSON array that list(string) parameters expect.
+func suggestionValue(value json.RawMessage) (string, bool) {
+	var v any
+	if err := json.Unmarshal(value, &v); err != nil {
+		return "", false
+	}
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case []any:
+		for _, elem := range v {
+			if _, ok := elem.(string); !ok {
+				return "", false
+			}
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", false
+		}
+		return string(data), true
+	default:
+		return "", false
+	}
+}
+
+// validType reports whether value is of the parameter type. Parameter
+// validation only checks the type when validation rules are set.
+func validType(typ, value string) bool {
+	switch typ {
+	case "number":
+		_, err := strconv.ParseFloat(value, 64)
+		return err == nil
+	case "bool":
+		_, err := strconv.ParseBool(value)
+		return err == nil
+	case "list(string)":
+		var list []string
+		return json.Unmarshal([]byte(value), &list) == nil
+	default:
+		return true
+	}
+}
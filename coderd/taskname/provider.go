@@ -0,0 +1,262 @@
+package taskname
+
+import (
+	"context"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/bedrock"
+	anthropicoption "github.com/anthropics/anthropic-sdk-go/option"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/azure"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/aisdk-go"
+)
+
+// ProviderType identifies a language model provider.
+type ProviderType string
+
+const (
+	ProviderAnthropic   ProviderType = "anthropic"
+	ProviderBedrock     ProviderType = "bedrock"
+	ProviderAzureOpenAI ProviderType = "azure-openai"
+)
+
+const (
+	// bedrockDefaultModel is the Bedrock ID of defaultModel.
+	bedrockDefaultModel = "anthropic.claude-3-5-haiku-20241022-v1:0"
+	// azureDefaultAPIVersion is the Azure OpenAI REST API version used when
+	// none is configured.
+	azureDefaultAPIVersion = "2024-06-01"
+)
+
+// ProviderConfig configures the language model provider used to generate
+// task names.
+type ProviderConfig struct {
+	Type ProviderType
+	// APIKey authenticates with Anthropic or Azure OpenAI. Bedrock uses the
+	// default AWS credential chain instead.
+	APIKey string
+	// BaseURL overrides the provider endpoint. It is required for Azure
+	// OpenAI, where it is the resource endpoint, e.g.
+	// https://my-resource.openai.azure.com.
+	BaseURL string
+	// Region is the AWS region for Bedrock. It defaults to the region of the
+	// AWS configuration.
+	Region string
+	// APIVersion is the Azure OpenAI REST API version.
+	APIVersion string
+	// ModelAliases maps the model names used in configuration, such as
+	// CODER_AI_TASK_NAME_MODEL, to provider-specific model IDs. For Azure
+	// OpenAI, models are deployment names.
+	ModelAliases map[string]string
+}
+
+// Provider sends a conversation to a language model.
+type Provider interface {
+	// Complete returns the model's text response and the tokens it used.
+	// Usage may be reported alongside an error. If onText is not nil, it is
+	// called with the response text as it arrives.
+	Complete(ctx context.Context, model string, conversation []aisdk.Message, maxTokens int64, onText func(delta string)) (string, Usage, error)
+	// DefaultModel is the model used when none is configured. It is empty
+	// if the provider has no default.
+	DefaultModel() string
+}
+
+// NewProvider returns the provider for the given configuration.
+func NewProvider(ctx context.Context, cfg ProviderConfig) (Provider, error) {
+	switch cfg.Type {
+	case "", ProviderAnthropic:
+		if cfg.APIKey == "" {
+			return nil, ErrNoAPIKey
+		}
+		opts := []anthropicoption.RequestOption{anthropicoption.WithAPIKey(cfg.APIKey)}
+		if cfg.BaseURL != "" {
+			opts = append(opts, anthropicoption.WithBaseURL(cfg.BaseURL))
+		}
+		return &anthropicProvider{
+			client:       anthropic.NewClient(opts...),
+			defaultModel: defaultModel,
+			aliases:      cfg.ModelAliases,
+		}, nil
+	case ProviderBedrock:
+		var loadOpts []func(*awsconfig.LoadOptions) error
+		if cfg.Region != "" {
+			loadOpts = append(loadOpts, awsconfig.WithRegion(cfg.Region))
+		}
+		awsCfg, err := awsconfig.LoadDefaultConfig(ctx, loadOpts...)
+		if err != nil {
+			return nil, xerrors.Errorf("load aws config: %w", err)
+		}
+		opts := []anthropicoption.RequestOption{bedrock.WithConfig(awsCfg)}
+		if cfg.BaseURL != "" {
+			opts = append(opts, anthropicoption.WithBaseURL(cfg.BaseURL))
+		}
+		return &anthropicProvider{
+			client:       anthropic.NewClient(opts...),
+			defaultModel: bedrockDefaultModel,
+			aliases:      cfg.ModelAliases,
+		}, nil
+	case ProviderAzureOpenAI:
+		if cfg.APIKey == "" {
+			return nil, ErrNoAPIKey
+		}
+		if cfg.BaseURL == "" {
+			return nil, xerrors.New("azure openai requires an endpoint url")
+		}
+		apiVersion := cfg.APIVersion
+		if apiVersion == "" {
+			apiVersion = azureDefaultAPIVersion
+		}
+		// The service is built without openai.NewClient, which would also
+		// pick up OPENAI_API_KEY and OPENAI_BASE_URL from the environment
+		// and send them to Azure.
+		return &azureOpenAIProvider{
+			completions: openai.NewChatCompletionService(
+				azure.WithEndpoint(cfg.BaseURL, apiVersion),
+				azure.WithAPIKey(cfg.APIKey),
+			),
+			aliases: cfg.ModelAliases,
+		}, nil
+	default:
+		return nil, xerrors.Errorf("unknown language model provider %q", cfg.Type)
+	}
+}
+
+// ParseModelAliases parses `alias=model` pairs.
+func ParseModelAliases(pairs []string) (map[string]string, error) {
+	aliases := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		alias, model, ok := strings.Cut(pair, "=")
+		alias, model = strings.TrimSpace(alias), strings.TrimSpace(model)
+		if !ok || alias == "" || model == "" {
+			return nil, xerrors.Errorf("invalid model alias %q, expected alias=model", pair)
+		}
+		aliases[alias] = model
+	}
+	return aliases, nil
+}
+
+func resolveModel(aliases map[string]string, model string) string {
+	if target, ok := aliases[string(model)]; ok {
+		return target
+	}
+	return model
+}
+
+// anthropicProvider talks to the Anthropic Messages API, either directly or
+// through Amazon Bedrock.
+type anthropicProvider struct {
+	client       anthropic.Client
+	defaultModel string
+	aliases      map[string]string
+}
+
+func (p *anthropicProvider) DefaultModel() string {
+	return p.defaultModel
+}
+
+func (p *anthropicProvider) Complete(ctx context.Context, model string, conversation []aisdk.Message, maxTokens int64, onText func(delta string)) (string, Usage, error) {
+	messages, system, err := aisdk.MessagesToAnthropic(conversation)
+	if err != nil {
+		return "", Usage{}, xerrors.Errorf("convert messages to anthropic format: %w", err)
+	}
+
+	stream := p.client.Messages.NewStreaming(ctx, anthropic.MessageNewParams{
+		Model:     anthropic.Model(resolveModel(p.aliases, model)),
+		MaxTokens: maxTokens,
+		System:    system,
+		Messages:  messages,
+	})
+	defer stream.Close()
+
+	var message anthropic.Message
+	for stream.Next() {
+		event := stream.Current()
+		if err := message.Accumulate(event); err != nil {
+			return "", Usage{}, xerrors.Errorf("accumulate message: %w", err)
+		}
+		if onText == nil {
+			continue
+		}
+		if delta, ok := event.AsAny().(anthropic.ContentBlockDeltaEvent); ok {
+			if text, ok := delta.Delta.AsAny().(anthropic.TextDelta); ok && text.Text != "" {
+				onText(text.Text)
+			}
+		}
+	}
+	usage := Usage{
+		Model:            model,
+		PromptTokens:     message.Usage.InputTokens,
+		CompletionTokens: message.Usage.OutputTokens,
+	}
+	if err := stream.Err(); err != nil {
+		return "", usage, xerrors.Errorf("stream message: %w", err)
+	}
+
+	var text strings.Builder
+	for _, block := range message.Content {
+		if block.Type == "text" {
+			_, _ = text.WriteString(block.Text)
+		}
+	}
+	return text.String(), usage, nil
+}
+
+// azureOpenAIProvider talks to the Chat Completions API of an Azure OpenAI
+// resource. Models are deployment names.
+type azureOpenAIProvider struct {
+	completions openai.ChatCompletionService
+	aliases     map[string]string
+}
+
+func (*azureOpenAIProvider) DefaultModel() string {
+	return ""
+}
+
+func (p *azureOpenAIProvider) Complete(ctx context.Context, model string, conversation []aisdk.Message, maxTokens int64, onText func(delta string)) (string, Usage, error) {
+	deployment := resolveModel(p.aliases, model)
+	messages := make([]openai.ChatCompletionMessageParamUnion, 0, len(conversation))
+	for _, message := range conversation {
+		var text strings.Builder
+		for _, part := range message.Parts {
+			if part.Type == aisdk.PartTypeText {
+				_, _ = text.WriteString(part.Text)
+			}
+		}
+		switch message.Role {
+		case "system":
+			messages = append(messages, openai.SystemMessage(text.String()))
+		case "assistant":
+			messages = append(messages, openai.AssistantMessage(text.String()))
+		default:
+			messages = append(messages, openai.UserMessage(text.String()))
+		}
+	}
+
+	completion, err := p.completions.New(ctx, openai.ChatCompletionNewParams{
+		Model:     deployment,
+		MaxTokens: openai.Int(maxTokens),
+		Messages:  messages,
+	})
+	if err != nil {
+		return "", Usage{}, xerrors.Errorf("create chat completion: %w", err)
+	}
+	usage := Usage{
+		Model:            model,
+		PromptTokens:     completion.Usage.PromptTokens,
+		CompletionTokens: completion.Usage.CompletionTokens,
+	}
+	if len(completion.Choices) == 0 {
+		return "", usage, nil
+	}
+	// Names are short, so the response is sent as a single delta rather
+	// than streamed.
+	text := completion.Choices[0].Message.Content
+	if onText != nil && text != "" {
+		onText(text)
+	}
+	return text, usage, nil
+}
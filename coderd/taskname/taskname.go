@@ -2,14 +2,14 @@ package taskname
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"io"
 	"math/rand/v2"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/anthropics/anthropic-sdk-go"
-	anthropicoption "github.com/anthropics/anthropic-sdk-go/option"
 	"github.com/moby/moby/pkg/namesgenerator"
 	"golang.org/x/xerrors"
 
@@ -18,7 +18,7 @@ import (
 )
 
 const (
-	defaultModel = anthropic.ModelClaude3_5HaikuLatest
+	defaultModel = string(anthropic.ModelClaude3_5HaikuLatest)
 	systemPrompt = `Generate a short workspace name from this AI task prompt.
 
 Requirements:
@@ -37,14 +37,60 @@ If you cannot create a suitable name:
 - Respond with "task-unnamed"`
 )
 
+const (
+	// TaskNameModelEnv configures the models used to generate task names, as
+	// a comma-separated list tried in order. It takes precedence over
+	// ANTHROPIC_MODEL.
+	TaskNameModelEnv = "CODER_AI_TASK_NAME_MODEL"
+)
+
 var (
 	ErrNoAPIKey        = xerrors.New("no api key provided")
 	ErrNoNameGenerated = xerrors.New("no task name generated")
+	// ErrTimeout is returned when a language model call exceeds the timeout
+	// set with WithCallTimeout.
+	ErrTimeout = xerrors.New("language model call timed out")
+)
+
+const (
+	// MaxCandidates is the maximum number of names GenerateCandidates
+	// returns.
+	MaxCandidates = 5
+	// maxSuffixAttempts bounds how many random suffixes are tried to avoid
+	// colliding with an existing workspace name.
+	maxSuffixAttempts = 5
+	// maxGenerateAttempts bounds how many times the model is asked for a
+	// name when it returns one that cannot be used.
+	maxGenerateAttempts = 3
+	// suffixLen is the length of the `-ffff` suffix appended to generated
+	// names.
+	suffixLen = 5
 )
 
 type options struct {
-	apiKey string
-	model  anthropic.Model
+	apiKey        string
+	provider      Provider
+	models        []string
+	templateName  string
+	repositoryURL string
+	issueTitle    string
+	issueLabels   []string
+	avoidNames    map[string]struct{}
+	cache         *Cache
+	usageFn       func(Usage)
+	streamFn      func(delta string)
+	systemPrompt  string
+	instructions  string
+	callTimeout   time.Duration
+	denyList      map[string]struct{}
+	moderators    []Moderator
+}
+
+// Usage is the number of tokens used by a single language model call.
+type Usage struct {
+	Model            string
+	PromptTokens     int64
+	CompletionTokens int64
 }
 
 type Option func(o *options)
@@ -55,9 +101,133 @@ func WithAPIKey(apiKey string) Option {
 	}
 }
 
-func WithModel(model anthropic.Model) Option {
+// WithProvider sets the language model provider, replacing the Anthropic
+// provider configured by WithAPIKey.
+func WithProvider(provider Provider) Option {
+	return func(o *options) {
+		o.provider = provider
+	}
+}
+
+// WithModel sets the model used to generate the task name. An empty model
+// selects the default model.
+func WithModel(model string) Option {
+	return func(o *options) {
+		o.models = nil
+		if model != "" {
+			o.models = []string{model}
+		}
+	}
+}
+
+// WithModels sets a fallback chain of models. Each model is tried in order
+// until one generates a name. Empty entries are ignored, and an empty chain
+// selects the default model.
+func WithModels(models ...string) Option {
+	return func(o *options) {
+		o.models = nil
+		for _, model := range models {
+			if model != "" {
+				o.models = append(o.models, model)
+			}
+		}
+	}
+}
+
+// WithTemplateName gives the model the name of the template the task will be
+// created from.
+func WithTemplateName(name string) Option {
+	return func(o *options) {
+		o.templateName = name
+	}
+}
+
+// WithRepositoryURL gives the model the URL of the repository the task will
+// work on.
+func WithRepositoryURL(url string) Option {
+	return func(o *options) {
+		o.repositoryURL = url
+	}
+}
+
+// WithIssue gives the model the title and labels of the issue or pull request
+// linked in the prompt.
+func WithIssue(title string, labels ...string) Option {
 	return func(o *options) {
-		o.model = model
+		o.issueTitle = title
+		o.issueLabels = labels
+	}
+}
+
+// WithAvoidNames lists existing workspace names that the generated name must
+// not collide with.
+func WithAvoidNames(names ...string) Option {
+	return func(o *options) {
+		if o.avoidNames == nil {
+			o.avoidNames = make(map[string]struct{}, len(names))
+		}
+		for _, name := range names {
+			o.avoidNames[name] = struct{}{}
+		}
+	}
+}
+
+// WithCache serves repeated prompts from the given cache.
+func WithCache(cache *Cache) Option {
+	return func(o *options) {
+		o.cache = cache
+	}
+}
+
+// WithSystemPrompt replaces the default system prompt, including its
+// examples. An empty prompt selects the default.
+func WithSystemPrompt(prompt string) Option {
+	return func(o *options) {
+		o.systemPrompt = prompt
+	}
+}
+
+// WithInstructions appends deployment-specific naming instructions, such as
+// required prefixes, to the system prompt.
+func WithInstructions(instructions string) Option {
+	return func(o *options) {
+		o.instructions = instructions
+	}
+}
+
+// WithCallTimeout bounds the duration of each language model call, including
+// streaming its response. A call that times out counts as a failure of its
+// model, so the next model is tried.
+func WithCallTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.callTimeout = timeout
+	}
+}
+
+// WithUsageCallback calls fn with the tokens used by every language model
+// call, including calls whose response is rejected and retried. If given more
+// than once, every callback is called.
+func WithUsageCallback(fn func(Usage)) Option {
+	return func(o *options) {
+		prev := o.usageFn
+		if prev == nil {
+			o.usageFn = fn
+			return
+		}
+		o.usageFn = func(usage Usage) {
+			prev(usage)
+			fn(usage)
+		}
+	}
+}
+
+// WithStream sets a function called with the model's response text as it
+// arrives, so callers can show names as they are generated. The streamed text
+// is raw model output: the names returned by Generate and GenerateCandidates
+// are validated and may differ. Nothing is streamed for cached names.
+func WithStream(fn func(delta string)) Option {
+	return func(o *options) {
+		o.streamFn = fn
 	}
 }
 
@@ -65,8 +235,29 @@ func GetAnthropicAPIKeyFromEnv() string {
 	return os.Getenv("ANTHROPIC_API_KEY")
 }
 
-func GetAnthropicModelFromEnv() anthropic.Model {
-	return anthropic.Model(os.Getenv("ANTHROPIC_MODEL"))
+func GetAnthropicModelFromEnv() string {
+	return os.Getenv("ANTHROPIC_MODEL")
+}
+
+// GetModelsFromEnv returns the fallback chain of models configured via
+// CODER_AI_TASK_NAME_MODEL, or the single model in ANTHROPIC_MODEL if it is
+// unset. The result is empty if neither is set.
+func GetModelsFromEnv() []string {
+	return parseModels(os.Getenv(TaskNameModelEnv), GetAnthropicModelFromEnv())
+}
+
+func parseModels(list string, fallback string) []string {
+	var models []string
+	for _, model := range strings.Split(list, ",") {
+		model = strings.TrimSpace(model)
+		if model != "" {
+			models = append(models, model)
+		}
+	}
+	if len(models) == 0 && fallback != "" {
+		models = append(models, fallback)
+	}
+	return models
 }
 
 // generateSuffix generates a random hex string between `0000` and `ffff`.
@@ -95,85 +286,305 @@ func GenerateFallback() string {
 	return fmt.Sprintf("task-%s-%s", name, generateSuffix())
 }
 
+// GenerateFallbackAvoiding generates a fallback name that is not one of the
+// given names.
+func GenerateFallbackAvoiding(names ...string) string {
+	avoid := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		avoid[name] = struct{}{}
+	}
+	name := GenerateFallback()
+	for range maxSuffixAttempts {
+		if _, ok := avoid[name]; !ok {
+			break
+		}
+		name = GenerateFallback()
+	}
+	return name
+}
+
 func Generate(ctx context.Context, prompt string, opts ...Option) (string, error) {
+	names, err := GenerateCandidates(ctx, prompt, 1, opts...)
+	if err != nil {
+		return "", err
+	}
+	return names[0], nil
+}
+
+// GenerateCandidates generates up to count distinct workspace names for the
+// prompt, with count capped at MaxCandidates. At least one name is returned on success; fewer than count are
+// returned if the model cannot come up with enough usable names.
+func GenerateCandidates(ctx context.Context, prompt string, count int, opts ...Option) ([]string, error) {
+	o, provider, err := newOptions(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	prompt, err = o.moderate(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+	count = min(max(count, 1), MaxCandidates)
+
+	var key [32]byte
+	if o.cache != nil {
+		key = cacheKey(o, prompt)
+		if bases, ok := o.cache.get(key, count); ok {
+			if names, ok := o.fromBases(bases, count); ok {
+				return names, nil
+			}
+		}
+	}
+
+	var errs []error
+	for _, model := range o.models {
+		names, err := generate(ctx, provider, model, o, prompt, count)
+		if err == nil {
+			if o.cache != nil {
+				bases := make([]string, 0, len(names))
+				for _, name := range names {
+					bases = append(bases, name[:len(name)-suffixLen])
+				}
+				o.cache.set(key, bases)
+			}
+			return names, nil
+		}
+		// Only fall back to the next model if this one failed, not if it
+		// was unable to name the prompt.
+		if ctx.Err() != nil || errors.Is(err, ErrNoNameGenerated) {
+			return nil, err
+		}
+		errs = append(errs, xerrors.Errorf("model %q: %w", model, err))
+	}
+	return nil, errors.Join(errs...)
+}
+
+// newOptions applies opts and resolves the provider and models to use.
+func newOptions(ctx context.Context, opts []Option) (options, Provider, error) {
 	o := options{}
 	for _, opt := range opts {
 		opt(&o)
 	}
 
-	if o.model == "" {
-		o.model = defaultModel
+	provider := o.provider
+	if provider == nil {
+		var err error
+		provider, err = NewProvider(ctx, ProviderConfig{Type: ProviderAnthropic, APIKey: o.apiKey})
+		if err != nil {
+			return o, nil, err
+		}
 	}
-	if o.apiKey == "" {
-		return "", ErrNoAPIKey
+	if len(o.models) == 0 {
+		if provider.DefaultModel() == "" {
+			return o, nil, xerrors.New("no model configured")
+		}
+		o.models = []string{provider.DefaultModel()}
 	}
+	return o, provider, nil
+}
 
+func generate(ctx context.Context, provider Provider, model string, o options, prompt string, count int) ([]string, error) {
+	text := userMessage(o, prompt)
+	if count > 1 {
+		text = fmt.Sprintf("%s\n\nRespond with %d different names, one per line.", text, count)
+	}
 	conversation := []aisdk.Message{
 		{
 			Role: "system",
 			Parts: []aisdk.Part{{
 				Type: aisdk.PartTypeText,
-				Text: systemPrompt,
+				Text: o.system(),
 			}},
 		},
 		{
 			Role: "user",
 			Parts: []aisdk.Part{{
 				Type: aisdk.PartTypeText,
-				Text: prompt,
+				Text: text,
 			}},
 		},
 	}
 
-	anthropicOptions := anthropic.DefaultClientOptions()
-	anthropicOptions = append(anthropicOptions, anthropicoption.WithAPIKey(o.apiKey))
-	anthropicClient := anthropic.NewClient(anthropicOptions...)
+	// Models occasionally return names that break the workspace naming
+	// rules, so we tell the model what was wrong and ask again a bounded
+	// number of times.
+	var (
+		names []string
+		seen  = make(map[string]struct{})
+		err   error
+	)
+	for range maxGenerateAttempts {
+		var response string
+		response, err = complete(ctx, provider, model, o, conversation, count)
+		if err != nil {
+			return nil, err
+		}
 
-	stream, err := anthropicDataStream(ctx, anthropicClient, o.model, conversation)
-	if err != nil {
-		return "", xerrors.Errorf("create anthropic data stream: %w", err)
-	}
+		var problems []string
+		for _, taskName := range strings.Split(response, "\n") {
+			taskName = strings.TrimSpace(taskName)
+			if taskName == "" || taskName == "task-unnamed" {
+				continue
+			}
+			if _, ok := seen[taskName]; ok {
+				continue
+			}
+			seen[taskName] = struct{}{}
 
-	var acc aisdk.DataStreamAccumulator
-	stream = stream.WithAccumulator(&acc)
+			var name string
+			name, err = o.validName(taskName)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("- %s: %s", taskName, err))
+				continue
+			}
+			names = append(names, name)
+			// Generated names get a random suffix, so the model can't
+			// repeat them; avoid them in later attempts all the same.
+			WithAvoidNames(name)(&o)
+			if len(names) == count {
+				return names, nil
+			}
+		}
+		if len(problems) == 0 && len(names) == 0 {
+			return nil, ErrNoNameGenerated
+		}
+		if len(problems) == 0 {
+			break
+		}
 
-	if err := stream.Pipe(io.Discard); err != nil {
-		return "", xerrors.Errorf("pipe data stream")
+		conversation = append(conversation,
+			aisdk.Message{
+				Role: "assistant",
+				Parts: []aisdk.Part{{
+					Type: aisdk.PartTypeText,
+					Text: response,
+				}},
+			},
+			aisdk.Message{
+				Role: "user",
+				Parts: []aisdk.Part{{
+					Type: aisdk.PartTypeText,
+					Text: fmt.Sprintf("These names cannot be used:\n%s\n\nRespond with %d different names, one per line.", strings.Join(problems, "\n"), count-len(names)),
+				}},
+			},
+		)
 	}
 
-	if len(acc.Messages()) == 0 {
-		return "", ErrNoNameGenerated
+	if len(names) > 0 {
+		return names, nil
 	}
+	return nil, xerrors.Errorf("no usable name after %d attempts: %w", maxGenerateAttempts, err)
+}
 
-	taskName := acc.Messages()[0].Content
-	if taskName == "task-unnamed" {
+// complete sends the conversation to the model and returns its response.
+func complete(ctx context.Context, provider Provider, model string, o options, conversation []aisdk.Message, count int) (string, error) {
+	text, err := call(ctx, provider, model, o, conversation, int64(24*count))
+	if err != nil {
+		return "", err
+	}
+	if text == "" {
 		return "", ErrNoNameGenerated
 	}
+	return text, nil
+}
+
+// call sends the conversation to the model, applying the call timeout and
+// reporting usage.
+func call(ctx context.Context, provider Provider, model string, o options, conversation []aisdk.Message, maxTokens int64) (string, error) {
+	if o.callTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, o.callTimeout, ErrTimeout)
+		defer cancel()
+	}
+
+	text, usage, err := provider.Complete(ctx, model, conversation, maxTokens, o.streamFn)
+	// Tokens are billed even if the call fails part way through.
+	if o.usageFn != nil && (usage.PromptTokens > 0 || usage.CompletionTokens > 0) {
+		o.usageFn(usage)
+	}
+	if err != nil {
+		if errors.Is(context.Cause(ctx), ErrTimeout) {
+			return "", xerrors.Errorf("after %s: %w", o.callTimeout, ErrTimeout)
+		}
+		return "", err
+	}
+	return text, nil
+}
 
+// fromBases suffixes cached base names, returning false if count usable
+// names can't be made from them.
+func (o options) fromBases(bases []string, count int) ([]string, bool) {
+	names := make([]string, 0, count)
+	for _, base := range bases {
+		name, err := o.validName(base)
+		if err != nil {
+			continue
+		}
+		names = append(names, name)
+		if len(names) == count {
+			return names, true
+		}
+	}
+	return nil, false
+}
+
+// validName turns the name returned by the model into a workspace name that
+// satisfies the naming rules and does not collide with any of the names to
+// avoid.
+func (o options) validName(taskName string) (string, error) {
 	// We append a suffix to the end of the task name to reduce
 	// the chance of collisions. We truncate the task name to
 	// to a maximum of 27 bytes, so that when we append the
 	// 5 byte suffix (`-` and 4 byte hex slug), it should
 	// remain within the 32 byte workspace name limit.
-	taskName = taskName[:min(len(taskName), 27)]
-	taskName = fmt.Sprintf("%s-%s", taskName, generateSuffix())
-	if err := codersdk.NameValid(taskName); err != nil {
-		return "", xerrors.Errorf("generated name %v not valid: %w", taskName, err)
+	// Truncation can leave a trailing hyphen, which would produce an
+	// invalid double hyphen before the suffix.
+	taskName = strings.TrimRight(truncate(sanitize(taskName), 27), "-")
+	if err := o.checkDenied(taskName); err != nil {
+		return "", err
 	}
-
-	return taskName, nil
+	var name string
+	for range maxSuffixAttempts {
+		name = fmt.Sprintf("%s-%s", taskName, generateSuffix())
+		if err := codersdk.NameValid(name); err != nil {
+			return "", xerrors.Errorf("generated name %v not valid: %w", name, err)
+		}
+		if _, ok := o.avoidNames[name]; !ok {
+			return name, nil
+		}
+	}
+	return "", xerrors.Errorf("generated name %v collides with an existing workspace", name)
 }
 
-func anthropicDataStream(ctx context.Context, client anthropic.Client, model anthropic.Model, input []aisdk.Message) (aisdk.DataStream, error) {
-	messages, system, err := aisdk.MessagesToAnthropic(input)
-	if err != nil {
-		return nil, xerrors.Errorf("convert messages to anthropic format: %w", err)
+// system returns the system prompt with any additional instructions.
+func (o options) system() string {
+	prompt := o.systemPrompt
+	if strings.TrimSpace(prompt) == "" {
+		prompt = systemPrompt
+	}
+	if instructions := strings.TrimSpace(o.instructions); instructions != "" {
+		prompt = fmt.Sprintf("%s\n\nAdditional instructions:\n%s", prompt, instructions)
 	}
+	return prompt
+}
 
-	return aisdk.AnthropicToDataStream(client.Messages.NewStreaming(ctx, anthropic.MessageNewParams{
-		Model:     model,
-		MaxTokens: 24,
-		System:    system,
-		Messages:  messages,
-	})), nil
+// userMessage builds the message sent to the model from the prompt and any
+// additional context about the task.
+func userMessage(o options, prompt string) string {
+	var extra []string
+	if o.templateName != "" {
+		extra = append(extra, fmt.Sprintf("- Template: %s", o.templateName))
+	}
+	if o.repositoryURL != "" {
+		extra = append(extra, fmt.Sprintf("- Repository: %s", o.repositoryURL))
+	}
+	if o.issueTitle != "" {
+		extra = append(extra, fmt.Sprintf("- Issue: %s", o.issueTitle))
+	}
+	if len(o.issueLabels) > 0 {
+		extra = append(extra, fmt.Sprintf("- Issue labels: %s", strings.Join(o.issueLabels, ", ")))
+	}
+	if len(extra) == 0 {
+		return prompt
+	}
+	return fmt.Sprintf("%s\n\nAdditional context:\n%s", prompt, strings.Join(extra, "\n"))
 }
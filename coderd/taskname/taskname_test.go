@@ -3,12 +3,14 @@ package taskname_test
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/coder/coder/v2/coderd/taskname"
 	"github.com/coder/coder/v2/codersdk"
 	"github.com/coder/coder/v2/testutil"
+	"github.com/coder/quartz"
 )
 
 const (
@@ -36,6 +38,16 @@ func TestGenerateTaskName(t *testing.T) {
 		require.Equal(t, "", name)
 	})
 
+	t.Run("CandidatesFallback", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		names, err := taskname.GenerateCandidates(ctx, "Some random prompt", 3)
+		require.ErrorIs(t, err, taskname.ErrNoAPIKey)
+		require.Empty(t, names)
+	})
+
 	t.Run("Anthropic", func(t *testing.T) {
 		t.Parallel()
 
@@ -53,4 +65,81 @@ func TestGenerateTaskName(t *testing.T) {
 		err = codersdk.NameValid(name)
 		require.NoError(t, err, "name should be valid")
 	})
+
+	t.Run("AnthropicCandidates", func(t *testing.T) {
+		t.Parallel()
+
+		apiKey := os.Getenv(anthropicEnvVar)
+		if apiKey == "" {
+			t.Skipf("Skipping test as %s not set", anthropicEnvVar)
+		}
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		names, err := taskname.GenerateCandidates(ctx, "Create a finance planning app", 3, taskname.WithAPIKey(apiKey))
+		require.NoError(t, err)
+		require.NotEmpty(t, names)
+		for _, name := range names {
+			require.NoError(t, codersdk.NameValid(name), "name should be valid")
+		}
+	})
+}
+
+func TestGetModelsFromEnv(t *testing.T) {
+	t.Setenv(taskname.TaskNameModelEnv, "")
+	t.Setenv("ANTHROPIC_MODEL", "")
+	require.Empty(t, taskname.GetModelsFromEnv())
+
+	t.Setenv("ANTHROPIC_MODEL", "claude-a")
+	require.Equal(t, []string{"claude-a"}, taskname.GetModelsFromEnv())
+
+	// The task name model list takes precedence, in order.
+	t.Setenv(taskname.TaskNameModelEnv, " claude-b, ,claude-c ")
+	require.Equal(t, []string{"claude-b", "claude-c"}, taskname.GetModelsFromEnv())
+}
+
+func TestGenerateFallbackAvoiding(t *testing.T) {
+	t.Parallel()
+
+	existing := taskname.GenerateFallback()
+	name := taskname.GenerateFallbackAvoiding(existing)
+	require.NotEqual(t, existing, name)
+	require.NoError(t, codersdk.NameValid(name))
+}
+
+func TestTokenBudget(t *testing.T) {
+	t.Parallel()
+
+	clock := quartz.NewMock(t)
+	budget := taskname.NewTokenBudget(clock, 100, 150, time.Minute)
+
+	_, ok := budget.Allow("alice")
+	require.True(t, ok)
+	// The call that exhausts the budget is allowed to complete.
+	budget.Charge("alice", 120)
+	retryAfter, ok := budget.Allow("alice")
+	require.False(t, ok)
+	require.Equal(t, time.Minute, retryAfter)
+
+	// Other users share what is left of the deployment's budget.
+	_, ok = budget.Allow("bob")
+	require.True(t, ok)
+	budget.Charge("bob", 40)
+	clock.Advance(20 * time.Second)
+	retryAfter, ok = budget.Allow("bob")
+	require.False(t, ok)
+	require.Equal(t, 40*time.Second, retryAfter)
+
+	// Budgets reset with the window.
+	clock.Advance(40 * time.Second)
+	_, ok = budget.Allow("alice")
+	require.True(t, ok)
+	_, ok = budget.Allow("bob")
+	require.True(t, ok)
+
+	// Limits <= 0 are unlimited.
+	unlimited := taskname.NewTokenBudget(clock, 0, -1, time.Minute)
+	unlimited.Charge("alice", 1_000_000)
+	_, ok = unlimited.Allow("alice")
+	require.True(t, ok)
 }
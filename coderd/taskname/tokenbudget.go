@@ -0,0 +1,72 @@
+package taskname
+
+import (
+	"sync"
+	"time"
+
+	"github.com/coder/quartz"
+)
+
+// TokenBudget limits the language model tokens used per window, both per user
+// and across all users. Tokens are only known once a call completes, so a
+// budget is checked before a call and charged after it: the last call in a
+// window may go over.
+//
+// TokenBudget is safe for concurrent use.
+type TokenBudget struct {
+	clock   quartz.Clock
+	window  time.Duration
+	perUser int64
+	total   int64
+
+	mu          sync.Mutex
+	windowStart time.Time
+	used        map[string]int64
+	usedTotal   int64
+}
+
+// NewTokenBudget returns a budget of perUser tokens per user and total tokens
+// across all users in each window. A limit <= 0 is unlimited.
+func NewTokenBudget(clock quartz.Clock, perUser, total int64, window time.Duration) *TokenBudget {
+	return &TokenBudget{
+		clock:   clock,
+		window:  window,
+		perUser: perUser,
+		total:   total,
+		used:    make(map[string]int64),
+	}
+}
+
+// Allow reports whether user may make another call. If not, it returns how
+// long until the budget resets.
+func (b *TokenBudget) Allow(user string) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.resetLocked()
+
+	if (b.total > 0 && b.usedTotal >= b.total) || (b.perUser > 0 && b.used[user] >= b.perUser) {
+		return b.windowStart.Add(b.window).Sub(b.clock.Now()), false
+	}
+	return 0, true
+}
+
+// Charge records tokens used by user.
+func (b *TokenBudget) Charge(user string, tokens int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.resetLocked()
+
+	b.used[user] += tokens
+	b.usedTotal += tokens
+}
+
+// resetLocked starts a new window if the current one has ended.
+func (b *TokenBudget) resetLocked() {
+	now := b.clock.Now()
+	if now.Sub(b.windowStart) < b.window {
+		return
+	}
+	b.windowStart = now
+	b.usedTotal = 0
+	clear(b.used)
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -31,6 +32,8 @@ import (
 	"github.com/coder/coder/v2/coderd/telemetry"
 	"github.com/coder/coder/v2/coderd/util/ptr"
 	"github.com/coder/coder/v2/coderd/util/slice"
+	"github.com/coder/coder/v2/coderd/workspaceapps"
+	"github.com/coder/coder/v2/coderd/workspaceapps/cors"
 	"github.com/coder/coder/v2/coderd/workspacestats"
 	"github.com/coder/coder/v2/codersdk"
 	"github.com/coder/coder/v2/examples"
@@ -325,6 +328,8 @@ func (api *API) postTemplateByOrganization(rw http.ResponseWriter, r *http.Reque
 		autostartRequirementDaysOfWeekParsed uint8
 		maxPortShareLevel                    = database.AppSharingLevelOwner // default
 		corsBehavior                         = database.CorsBehaviorSimple   // default
+		corsAllowedOrigins                   = []string{}
+		corsAllowCredentials                 = createTemplate.CORSAllowCredentials
 	)
 	if defaultTTL < 0 {
 		validErrs = append(validErrs, codersdk.ValidationError{Field: "default_ttl_ms", Detail: "Must be a positive integer."})
@@ -354,8 +359,16 @@ func (api *API) postTemplateByOrganization(rw http.ResponseWriter, r *http.Reque
 		}
 	}
 
-	// Default the CORS behavior here to Simple so we don't break all existing templates.
-	val := database.CorsBehaviorSimple
+	//nolint:gocritic // Requires system context to read runtime config
+	corsSettings, err := api.CORSPolicy.OrganizationSettings(dbauthz.AsSystemRestricted(ctx), api.Database, organization.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	// Default the CORS behavior to the organization's default, which is
+	// Simple unless configured, so we don't break all existing templates.
+	val := database.CorsBehavior(corsSettings.DefaultBehavior)
 	if createTemplate.CORSBehavior != nil {
 		val = database.CorsBehavior(*createTemplate.CORSBehavior)
 	}
@@ -364,9 +377,22 @@ func (api *API) postTemplateByOrganization(rw http.ResponseWriter, r *http.Reque
 			Field:  "cors_behavior",
 			Detail: fmt.Sprintf("Invalid CORS behavior %q. Must be one of [%s]", *createTemplate.CORSBehavior, strings.Join(slice.ToStrings(database.AllCorsBehaviorValues()), ", ")),
 		})
+	} else if err := workspaceapps.CheckTemplateCORSBehavior(corsSettings, codersdk.CORSBehavior(val)); err != nil {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "cors_behavior", Detail: err.Error()})
 	} else {
 		corsBehavior = val
 	}
+	if createTemplate.CORSAllowedOrigins != nil {
+		originErrs := validateCORSAllowedOrigins(createTemplate.CORSAllowedOrigins)
+		if len(originErrs) > 0 {
+			validErrs = append(validErrs, originErrs...)
+		} else {
+			corsAllowedOrigins = createTemplate.CORSAllowedOrigins
+		}
+	}
+	if corsAllowCredentials {
+		validErrs = append(validErrs, validateCORSAllowCredentials(corsBehavior, corsAllowedOrigins)...)
+	}
 
 	if autostopRequirementWeeks < 0 {
 		validErrs = append(validErrs, codersdk.ValidationError{Field: "autostop_requirement.weeks", Detail: "Must be a positive integer."})
@@ -427,6 +453,8 @@ func (api *API) postTemplateByOrganization(rw http.ResponseWriter, r *http.Reque
 			MaxPortSharingLevel:          maxPortShareLevel,
 			UseClassicParameterFlow:      useClassicParameterFlow,
 			CorsBehavior:                 corsBehavior,
+			CorsAllowedOrigins:           corsAllowedOrigins,
+			CorsAllowCredentials:         corsAllowCredentials,
 		})
 		if err != nil {
 			return xerrors.Errorf("insert template: %s", err)
@@ -758,6 +786,44 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 			corsBehavior = val
 		}
 	}
+	if corsBehavior != template.CorsBehavior {
+		//nolint:gocritic // Requires system context to read runtime config
+		corsSettings, err := api.CORSPolicy.OrganizationSettings(dbauthz.AsSystemRestricted(ctx), api.Database, template.OrganizationID)
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+		if err := workspaceapps.CheckTemplateCORSBehavior(corsSettings, codersdk.CORSBehavior(corsBehavior)); err != nil {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "cors_behavior", Detail: err.Error()})
+		}
+	}
+
+	corsAllowedOrigins := template.CorsAllowedOrigins
+	if corsAllowedOrigins == nil {
+		corsAllowedOrigins = []string{}
+	}
+	if req.CORSAllowedOrigins != nil {
+		originErrs := validateCORSAllowedOrigins(*req.CORSAllowedOrigins)
+		if len(originErrs) > 0 {
+			validErrs = append(validErrs, originErrs...)
+		} else if *req.CORSAllowedOrigins != nil {
+			corsAllowedOrigins = *req.CORSAllowedOrigins
+		} else {
+			corsAllowedOrigins = []string{}
+		}
+	}
+
+	corsAllowCredentials := template.CorsAllowCredentials
+	if req.CORSAllowCredentials != nil {
+		corsAllowCredentials = *req.CORSAllowCredentials
+	} else if corsBehavior != database.CorsBehaviorAllowlist {
+		// Credentials are only allowed with the allowlist, so moving off it
+		// turns them off rather than failing the update.
+		corsAllowCredentials = false
+	}
+	if corsAllowCredentials {
+		validErrs = append(validErrs, validateCORSAllowCredentials(corsBehavior, corsAllowedOrigins)...)
+	}
 
 	if len(validErrs) > 0 {
 		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
@@ -798,7 +864,9 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 			(deprecationMessage == template.Deprecated) &&
 			(classicTemplateFlow == template.UseClassicParameterFlow) &&
 			maxPortShareLevel == template.MaxPortSharingLevel &&
-			corsBehavior == template.CorsBehavior {
+			corsBehavior == template.CorsBehavior &&
+			slices.Equal(corsAllowedOrigins, template.CorsAllowedOrigins) &&
+			corsAllowCredentials == template.CorsAllowCredentials {
 			return nil
 		}
 
@@ -841,6 +909,8 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 			MaxPortSharingLevel:          maxPortShareLevel,
 			UseClassicParameterFlow:      classicTemplateFlow,
 			CorsBehavior:                 corsBehavior,
+			CorsAllowedOrigins:           corsAllowedOrigins,
+			CorsAllowCredentials:         corsAllowCredentials,
 		})
 		if err != nil {
 			return xerrors.Errorf("update template metadata: %w", err)
@@ -1125,6 +1195,8 @@ func (api *API) convertTemplate(
 		MaxPortShareLevel:       maxPortShareLevel,
 		UseClassicParameterFlow: template.UseClassicParameterFlow,
 		CORSBehavior:            codersdk.CORSBehavior(template.CorsBehavior),
+		CORSAllowedOrigins:      template.CorsAllowedOrigins,
+		CORSAllowCredentials:    template.CorsAllowCredentials,
 	}
 }
 
@@ -1146,3 +1218,36 @@ func findTemplateAdmins(ctx context.Context, store database.Store) ([]database.G
 	}
 	return append(owners, templateAdmins...), nil
 }
+
+// validateCORSAllowedOrigins returns a validation error for every origin in
+// the allowlist that is not a valid CORS origin.
+func validateCORSAllowedOrigins(origins []string) []codersdk.ValidationError {
+	var validErrs []codersdk.ValidationError
+	for i, origin := range origins {
+		if err := cors.ValidateOrigin(origin); err != nil {
+			validErrs = append(validErrs, codersdk.ValidationError{
+				Field:  fmt.Sprintf("cors_allowed_origins[%d]", i),
+				Detail: err.Error(),
+			})
+		}
+	}
+	return validErrs
+}
+
+// validateCORSAllowCredentials returns a validation error if credentials can't
+// be allowed for the CORS behavior and allowed origins.
+func validateCORSAllowCredentials(behavior database.CorsBehavior, origins []string) []codersdk.ValidationError {
+	if behavior != database.CorsBehaviorAllowlist {
+		return []codersdk.ValidationError{{
+			Field:  "cors_allow_credentials",
+			Detail: fmt.Sprintf("Credentials can only be allowed with the %q CORS behavior.", database.CorsBehaviorAllowlist),
+		}}
+	}
+	if err := cors.ValidateAllowCredentials(origins); err != nil {
+		return []codersdk.ValidationError{{
+			Field:  "cors_allow_credentials",
+			Detail: err.Error(),
+		}}
+	}
+	return nil
+}
@@ -37,6 +37,125 @@ func (api *API) appHost(rw http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// @Summary Check CORS behavior of a workspace app
+// @ID check-cors-behavior-of-a-workspace-app
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Applications
+// @Param request body codersdk.WorkspaceAppCORSCheckRequest true "CORS check request"
+// @Success 200 {object} codersdk.WorkspaceAppCORSCheckResponse
+// @Router /applications/cors-check [post]
+func (api *API) workspaceAppCORSCheck(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req codersdk.WorkspaceAppCORSCheckRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	method := strings.ToUpper(req.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	if origin, err := url.Parse(req.Origin); err != nil || origin.Scheme == "" || origin.Host == "" {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid origin.",
+			Validations: []codersdk.ValidationError{
+				{Field: "origin", Detail: "Must be a scheme and host, e.g. https://app.example.com."},
+			},
+		})
+		return
+	}
+	app, err := api.parseWorkspaceAppURL(req.AppURL)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid app URL.",
+			Validations: []codersdk.ValidationError{
+				{Field: "app_url", Detail: err.Error()},
+			},
+		})
+		return
+	}
+
+	user, err := api.Database.GetUserByEmailOrUsername(ctx, database.GetUserByEmailOrUsernameParams{
+		Username: app.Username,
+	})
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	workspace, err := api.Database.GetWorkspaceByOwnerIDAndName(ctx, database.GetWorkspaceByOwnerIDAndNameParams{
+		OwnerID: user.ID,
+		Name:    app.WorkspaceName,
+	})
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	template, err := api.Database.GetTemplateByID(ctx, workspace.TemplateID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	//nolint:gocritic // Requires system context to read runtime config
+	corsSettings, err := api.CORSPolicy.OrganizationSettings(dbauthz.AsSystemRestricted(ctx), api.Database, template.OrganizationID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	// Check the behavior apps are actually served with.
+	template.CorsBehavior = database.CorsBehavior(workspaceapps.EffectiveCORSBehavior(corsSettings, codersdk.CORSBehavior(template.CorsBehavior)))
+
+	resp, err := api.workspaceAppServer.CORSCheck(ctx, app, template, req.Origin, method)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+// parseWorkspaceAppURL parses a subdomain app URL on the primary wildcard
+// hostname or a path-based app URL on the access URL.
+func (api *API) parseWorkspaceAppURL(rawURL string) (appurl.ApplicationURL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return appurl.ApplicationURL{}, xerrors.Errorf("parse URL: %w", err)
+	}
+	if api.AppHostnameRegex != nil {
+		if subdomain, ok := appurl.ExecuteHostnamePattern(api.AppHostnameRegex, u.Host); ok {
+			return appurl.ParseSubdomainAppURL(subdomain)
+		}
+	}
+	if u.Host != api.AccessURL.Host {
+		return appurl.ApplicationURL{}, xerrors.New("must be on the wildcard app hostname or the access URL")
+	}
+
+	// Path-based apps are served at /@<user>/<workspace>[.<agent>]/apps/<app>.
+	parts := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
+	if len(parts) < 4 || !strings.HasPrefix(parts[0], "@") || parts[2] != "apps" || parts[3] == "" {
+		return appurl.ApplicationURL{}, xerrors.New("path must be /@<user>/<workspace>[.<agent>]/apps/<app>")
+	}
+	workspaceName, agentName, _ := strings.Cut(parts[1], ".")
+	return appurl.ApplicationURL{
+		AppSlugOrPort: parts[3],
+		AgentName:     agentName,
+		WorkspaceName: workspaceName,
+		Username:      strings.TrimPrefix(parts[0], "@"),
+	}, nil
+}
+
 // workspaceApplicationAuth is an endpoint on the main router that handles
 // redirects from the subdomain handler.
 //
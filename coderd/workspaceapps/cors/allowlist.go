@@ -0,0 +1,53 @@
+package cors
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	chicors "github.com/go-chi/cors"
+	"golang.org/x/xerrors"
+)
+
+// Allowlist returns a middleware that allows cross-origin requests to an app
+// from the given origins only. See OriginPattern for how origins are matched;
+// origins that fail to parse are ignored. Credentials are only allowed if
+// allowCredentials is set, see ValidateAllowCredentials.
+func Allowlist(origins []string, allowCredentials bool, opts Options) func(next http.Handler) http.Handler {
+	patterns := make([]OriginPattern, 0, len(origins))
+	for _, origin := range origins {
+		pattern, err := ParseOriginPattern(origin)
+		if err != nil {
+			continue
+		}
+		patterns = append(patterns, pattern)
+	}
+	handlerOpts := opts.HandlerOptions()
+	// Setting AllowOriginFunc also stops the CORS middleware from allowing
+	// every origin when the allowlist is empty.
+	handlerOpts.AllowOriginFunc = func(_ *http.Request, origin string) bool {
+		return slices.ContainsFunc(patterns, func(pattern OriginPattern) bool {
+			return pattern.Match(origin)
+		})
+	}
+	handlerOpts.AllowCredentials = allowCredentials
+	return chicors.Handler(handlerOpts)
+}
+
+// ValidateOrigin returns an error if origin can't be used in an allowlist.
+func ValidateOrigin(origin string) error {
+	_, err := ParseOriginPattern(origin)
+	return err
+}
+
+// ValidateAllowCredentials returns an error if credentials can't be allowed
+// for the origins. Credentialed requests are only allowed from exact origins,
+// since a wildcard would share cookies with every matching subdomain.
+func ValidateAllowCredentials(origins []string) error {
+	for _, origin := range origins {
+		if strings.Contains(origin, "*") {
+			return xerrors.Errorf("credentials can't be allowed for wildcard origin %q", origin)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package cors_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/coderd/workspaceapps/cors"
+)
+
+func TestAllowlist(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name    string
+		origins []string
+		origin  string
+		allowed bool
+	}{
+		{name: "Exact", origins: []string{"https://app.example.com"}, origin: "https://app.example.com", allowed: true},
+		{name: "ExactMismatch", origins: []string{"https://app.example.com"}, origin: "https://other.example.com"},
+		{name: "Scheme", origins: []string{"https://app.example.com"}, origin: "http://app.example.com"},
+		{name: "Wildcard", origins: []string{"https://*.example.com"}, origin: "https://preview-12.example.com", allowed: true},
+		{name: "WildcardApex", origins: []string{"https://*.example.com"}, origin: "https://example.com"},
+		{name: "WildcardNested", origins: []string{"https://*.example.com"}, origin: "https://a.b.example.com", allowed: true},
+		{name: "WildcardPartialSuffix", origins: []string{"https://*.example.com"}, origin: "https://badexample.com"},
+		{name: "WildcardOtherDomain", origins: []string{"https://*.example.com"}, origin: "https://example.com.evil.com"},
+		{name: "WildcardPort", origins: []string{"https://*.example.com"}, origin: "https://app.example.com:8443"},
+		{name: "DefaultPort", origins: []string{"https://app.example.com:443"}, origin: "https://app.example.com", allowed: true},
+		{name: "Case", origins: []string{"https://App.Example.com"}, origin: "https://app.example.com", allowed: true},
+		{name: "TrailingSlash", origins: []string{"https://app.example.com/"}, origin: "https://app.example.com", allowed: true},
+		{name: "Null", origins: []string{"https://app.example.com"}, origin: "null"},
+		{name: "Empty", origins: nil, origin: "https://app.example.com"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := cors.Allowlist(tc.origins, false, cors.Options{})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			req := httptest.NewRequest(http.MethodGet, "https://app--agent--workspace--user.apps.coder.com/", nil)
+			req.Header.Set("Origin", tc.origin)
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			if tc.allowed {
+				require.Equal(t, tc.origin, rw.Header().Get("Access-Control-Allow-Origin"))
+			} else {
+				require.Empty(t, rw.Header().Get("Access-Control-Allow-Origin"))
+			}
+			require.Empty(t, rw.Header().Get("Access-Control-Allow-Credentials"))
+		})
+	}
+}
+
+func TestValidateOrigin(t *testing.T) {
+	t.Parallel()
+
+	for _, origin := range []string{
+		"https://app.example.com",
+		"http://localhost:3000",
+		"https://*.example.com",
+	} {
+		require.NoError(t, cors.ValidateOrigin(origin), origin)
+	}
+	for _, origin := range []string{
+		"*",
+		"app.example.com",
+		"ftp://app.example.com",
+		"https://app.example.com/path",
+		"https://*example.com",
+		"https://app.*.example.com",
+		"https://*.",
+	} {
+		require.Error(t, cors.ValidateOrigin(origin), origin)
+	}
+}
+
+func TestAllowlistOptions(t *testing.T) {
+	t.Parallel()
+
+	const origin = "https://app.example.com"
+	handler := cors.Allowlist([]string{origin}, false, cors.Options{
+		MaxAge:         10 * time.Minute,
+		AllowedMethods: []string{http.MethodGet},
+		AllowedHeaders: []string{"X-Custom-Auth"},
+		ExposedHeaders: []string{"X-Request-Id"},
+	})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	preflight := func(method, header string) http.Header {
+		req := httptest.NewRequest(http.MethodOptions, "https://app--agent--workspace--user.apps.coder.com/", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", method)
+		if header != "" {
+			req.Header.Set("Access-Control-Request-Headers", header)
+		}
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, req)
+		return rw.Header()
+	}
+
+	allowed := preflight(http.MethodGet, "X-Custom-Auth")
+	require.Equal(t, origin, allowed.Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "600", allowed.Get("Access-Control-Max-Age"))
+	require.Equal(t, "X-Custom-Auth", allowed.Get("Access-Control-Allow-Headers"))
+
+	require.Empty(t, preflight(http.MethodDelete, "").Get("Access-Control-Allow-Origin"))
+	require.Empty(t, preflight(http.MethodGet, "X-Other").Get("Access-Control-Allow-Origin"))
+
+	req := httptest.NewRequest(http.MethodGet, "https://app--agent--workspace--user.apps.coder.com/", nil)
+	req.Header.Set("Origin", origin)
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, req)
+	require.Equal(t, "X-Request-Id", rw.Header().Get("Access-Control-Expose-Headers"))
+}
+
+func TestAllowlistCredentials(t *testing.T) {
+	t.Parallel()
+
+	const origin = "https://app.example.com"
+	handler := cors.Allowlist([]string{origin}, true, cors.Options{})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	req := httptest.NewRequest(http.MethodGet, "https://app--agent--workspace--user.apps.coder.com/", nil)
+	req.Header.Set("Origin", origin)
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, req)
+	require.Equal(t, origin, rw.Header().Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "true", rw.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestValidateAllowCredentials(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, cors.ValidateAllowCredentials(nil))
+	require.NoError(t, cors.ValidateAllowCredentials([]string{"https://app.example.com", "http://localhost:3000"}))
+	require.Error(t, cors.ValidateAllowCredentials([]string{"https://app.example.com", "https://*.example.com"}))
+}
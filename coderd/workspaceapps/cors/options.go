@@ -0,0 +1,64 @@
+package cors
+
+import (
+	"net/http"
+	"time"
+
+	chicors "github.com/go-chi/cors"
+
+	"github.com/coder/coder/v2/codersdk"
+)
+
+// DefaultAllowedMethods are the methods allowed in cross-origin requests to
+// workspace apps when none are configured.
+var DefaultAllowedMethods = []string{
+	http.MethodHead,
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// Options configures the CORS headers coderd sets on workspace app responses
+// for the simple and allowlist behaviors. The zero value allows
+// DefaultAllowedMethods and all request headers, exposes no response headers
+// and doesn't let browsers cache preflight responses.
+type Options struct {
+	// MaxAge is sent as Access-Control-Max-Age on preflight responses. It is
+	// truncated to whole seconds and omitted when zero.
+	MaxAge         time.Duration
+	AllowedMethods []string
+	AllowedHeaders []string
+	ExposedHeaders []string
+}
+
+// OptionsFromConfig returns the options for a deployment's workspace app CORS
+// config.
+func OptionsFromConfig(cfg codersdk.WorkspaceAppCORSConfig) Options {
+	return Options{
+		MaxAge:         cfg.MaxAge.Value(),
+		AllowedMethods: cfg.AllowedMethods.Value(),
+		AllowedHeaders: cfg.AllowedHeaders.Value(),
+		ExposedHeaders: cfg.ExposedHeaders.Value(),
+	}
+}
+
+// HandlerOptions returns the go-chi CORS options for o. Callers set the
+// allowed origins and credentials.
+func (o Options) HandlerOptions() chicors.Options {
+	methods := o.AllowedMethods
+	if len(methods) == 0 {
+		methods = DefaultAllowedMethods
+	}
+	headers := o.AllowedHeaders
+	if len(headers) == 0 {
+		headers = []string{"*"}
+	}
+	return chicors.Options{
+		AllowedMethods: methods,
+		AllowedHeaders: headers,
+		ExposedHeaders: o.ExposedHeaders,
+		MaxAge:         int(o.MaxAge.Seconds()),
+	}
+}
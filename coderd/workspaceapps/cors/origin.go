@@ -0,0 +1,87 @@
+package cors
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// OriginPattern is an origin in an allowlist. It either matches one origin
+// exactly, such as https://app.example.com, or every subdomain of a host, such
+// as https://*.example.com. Schemes and hosts are compared case-insensitively
+// and default ports are ignored, so https://App.example.com:443 and
+// https://app.example.com are the same origin.
+type OriginPattern struct {
+	Scheme string
+	// Host is the lowercase hostname, without the wildcard.
+	Host string
+	// Port is empty if it is the default port of the scheme.
+	Port string
+	// Wildcard is set if the pattern matches subdomains of Host rather than
+	// Host itself.
+	Wildcard bool
+}
+
+// ParseOriginPattern parses an allowlist origin. It must be an http or https
+// URL without a path, and may only contain a wildcard as its leftmost
+// subdomain.
+func ParseOriginPattern(origin string) (OriginPattern, error) {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return OriginPattern{}, xerrors.Errorf("invalid origin %q: %w", origin, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return OriginPattern{}, xerrors.Errorf("origin %q must use http or https", origin)
+	}
+	if u.Host == "" || u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return OriginPattern{}, xerrors.Errorf("origin %q must only have a scheme, host and optional port", origin)
+	}
+
+	pattern := OriginPattern{
+		Scheme: u.Scheme,
+		Host:   strings.ToLower(u.Hostname()),
+		Port:   normalizePort(u.Scheme, u.Port()),
+	}
+	if host, ok := strings.CutPrefix(pattern.Host, "*."); ok {
+		pattern.Host = host
+		pattern.Wildcard = true
+	}
+	if pattern.Host == "" || strings.Contains(pattern.Host, "*") {
+		return OriginPattern{}, xerrors.Errorf("origin %q may only have a wildcard as its leftmost subdomain, e.g. https://*.example.com", origin)
+	}
+	if slices.Contains(strings.Split(pattern.Host, "."), "") {
+		return OriginPattern{}, xerrors.Errorf("origin %q has an empty domain label", origin)
+	}
+	return pattern, nil
+}
+
+// Match reports whether the origin a browser sent is allowed by p. A wildcard
+// only matches whole subdomains: https://*.example.com matches
+// https://a.example.com and https://a.b.example.com, but not
+// https://example.com or https://badexample.com.
+func (p OriginPattern) Match(origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Scheme != p.Scheme || u.User != nil || u.Path != "" || u.RawQuery != "" || u.Fragment != "" {
+		return false
+	}
+	if normalizePort(u.Scheme, u.Port()) != p.Port {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if !p.Wildcard {
+		return host == p.Host
+	}
+	sub, ok := strings.CutSuffix(host, "."+p.Host)
+	return ok && sub != "" && !slices.Contains(strings.Split(sub, "."), "")
+}
+
+// normalizePort returns port, or an empty string if it is the default port of
+// scheme.
+func normalizePort(scheme, port string) string {
+	if (scheme == "http" && port == "80") || (scheme == "https" && port == "443") {
+		return ""
+	}
+	return port
+}
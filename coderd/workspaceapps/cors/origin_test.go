@@ -0,0 +1,71 @@
+package cors_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/coderd/workspaceapps/cors"
+)
+
+func TestParseOriginPattern(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		origin  string
+		pattern cors.OriginPattern
+	}{
+		{origin: "https://app.example.com", pattern: cors.OriginPattern{Scheme: "https", Host: "app.example.com"}},
+		{origin: "HTTPS://App.Example.COM/", pattern: cors.OriginPattern{Scheme: "https", Host: "app.example.com"}},
+		{origin: "https://app.example.com:443", pattern: cors.OriginPattern{Scheme: "https", Host: "app.example.com"}},
+		{origin: "http://app.example.com:80", pattern: cors.OriginPattern{Scheme: "http", Host: "app.example.com"}},
+		{origin: "http://localhost:443", pattern: cors.OriginPattern{Scheme: "http", Host: "localhost", Port: "443"}},
+		{origin: "https://*.internal.example.com:8443", pattern: cors.OriginPattern{Scheme: "https", Host: "internal.example.com", Port: "8443", Wildcard: true}},
+		{origin: "http://[::1]:3000", pattern: cors.OriginPattern{Scheme: "http", Host: "::1", Port: "3000"}},
+	} {
+		pattern, err := cors.ParseOriginPattern(tc.origin)
+		require.NoError(t, err, tc.origin)
+		require.Equal(t, tc.pattern, pattern, tc.origin)
+	}
+
+	for _, origin := range []string{
+		"https://*",
+		"https://*.*.example.com",
+		"https://app..example.com",
+		"https://*.example.com?query",
+		"https://user@app.example.com",
+	} {
+		_, err := cors.ParseOriginPattern(origin)
+		require.Error(t, err, origin)
+	}
+}
+
+func TestOriginPatternMatch(t *testing.T) {
+	t.Parallel()
+
+	pattern, err := cors.ParseOriginPattern("https://*.internal.example.com")
+	require.NoError(t, err)
+
+	for _, origin := range []string{
+		"https://pr-123.internal.example.com",
+		"https://PR-123.Internal.Example.com",
+		"https://a.pr-123.internal.example.com",
+		"https://pr-123.internal.example.com:443",
+	} {
+		require.True(t, pattern.Match(origin), origin)
+	}
+	for _, origin := range []string{
+		"https://internal.example.com",
+		"https://.internal.example.com",
+		"https://a..internal.example.com",
+		"https://evilinternal.example.com",
+		"https://pr-123.internal.example.com.evil.com",
+		"https://pr-123.internal.example.com:8443",
+		"http://pr-123.internal.example.com",
+		"https://pr-123.internal.example.com/path",
+		"null",
+		"",
+	} {
+		require.False(t, pattern.Match(origin), origin)
+	}
+}
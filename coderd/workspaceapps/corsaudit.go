@@ -0,0 +1,117 @@
+package workspaceapps
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/coder/coder/v2/coderd/audit"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/httpmw"
+	"github.com/coder/coder/v2/coderd/tracing"
+	"github.com/coder/coder/v2/coderd/workspaceapps/appurl"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+// corsPreflightAuditInterval is how often a preflight from the same origin to
+// the same app is audited. Browsers send a preflight before most
+// cross-origin requests, so only the first one per interval is recorded.
+const corsPreflightAuditInterval = 10 * time.Minute
+
+// auditCORSPreflight records CORS preflights to apps with the allowlist or
+// passthru behavior in the audit log, so security reviews can see which apps
+// relax cross-origin protections and which origins use them. Preflights to
+// apps with the simple behavior are not recorded.
+func (s *Server) auditCORSPreflight(token *SignedToken, app appurl.ApplicationURL) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get(httpmw.OriginHeader)
+			method := r.Header.Get("Access-Control-Request-Method")
+			if s.Auditor == nil || token == nil || r.Method != http.MethodOptions || origin == "" || method == "" {
+				next.ServeHTTP(rw, r)
+				return
+			}
+			if token.CORSBehavior != codersdk.CORSBehaviorAllowlist && token.CORSBehavior != codersdk.CORSBehaviorPassthru {
+				next.ServeHTTP(rw, r)
+				return
+			}
+
+			next.ServeHTTP(rw, r)
+
+			key := corsPreflightKey{
+				agentID:  token.AgentID,
+				app:      app.AppSlugOrPort,
+				origin:   origin,
+				behavior: token.CORSBehavior,
+			}
+			if !s.corsPreflights.sample(key, time.Now()) {
+				return
+			}
+
+			status := http.StatusOK
+			if sw, ok := rw.(*tracing.StatusWriter); ok && sw.Status != 0 {
+				status = sw.Status
+			}
+			// The entry is written even if the client has gone away.
+			audit.BackgroundAudit(context.WithoutCancel(r.Context()), &audit.BackgroundAuditParams[database.WorkspaceAppCORSPreflight]{
+				Audit:     *s.Auditor.Load(),
+				Log:       s.Logger,
+				UserID:    token.UserID,
+				RequestID: httpmw.RequestID(r),
+				IP:        r.RemoteAddr,
+				UserAgent: r.UserAgent(),
+				Status:    status,
+				Action:    database.AuditActionCreate,
+				New: database.WorkspaceAppCORSPreflight{
+					ID:          uuid.New(),
+					WorkspaceID: token.WorkspaceID,
+					AgentID:     token.AgentID,
+					App:         app.AppSlugOrPort,
+					Behavior:    database.CorsBehavior(token.CORSBehavior),
+					Origin:      origin,
+					Method:      method,
+					Allowed:     rw.Header().Get(httpmw.AccessControlAllowOriginHeader) != "",
+				},
+			})
+		})
+	}
+}
+
+type corsPreflightKey struct {
+	agentID  uuid.UUID
+	app      string
+	origin   string
+	behavior codersdk.CORSBehavior
+}
+
+// corsPreflightSampler decides which CORS preflights are audited. The zero
+// value is ready to use.
+type corsPreflightSampler struct {
+	mu   sync.Mutex
+	last map[corsPreflightKey]time.Time
+}
+
+// sample reports whether a preflight for key at now should be audited, which
+// is the case if none was in the last corsPreflightAuditInterval.
+func (s *corsPreflightSampler) sample(key corsPreflightKey, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if last, ok := s.last[key]; ok && now.Sub(last) < corsPreflightAuditInterval {
+		return false
+	}
+	if s.last == nil {
+		s.last = make(map[corsPreflightKey]time.Time)
+	}
+	// Forget expired keys so the map only holds recently seen origins.
+	for k, last := range s.last {
+		if now.Sub(last) >= corsPreflightAuditInterval {
+			delete(s.last, k)
+		}
+	}
+	s.last[key] = now
+	return true
+}
@@ -0,0 +1,85 @@
+package workspaceapps
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/coderd/audit"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/httpmw"
+	"github.com/coder/coder/v2/coderd/tracing"
+	"github.com/coder/coder/v2/coderd/workspaceapps/appurl"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+func TestCORSPreflightSampler(t *testing.T) {
+	t.Parallel()
+
+	var sampler corsPreflightSampler
+	now := time.Now()
+	key := corsPreflightKey{agentID: uuid.New(), app: "app", origin: "https://example.com", behavior: codersdk.CORSBehaviorAllowlist}
+	other := key
+	other.origin = "https://other.example.com"
+
+	require.True(t, sampler.sample(key, now))
+	require.False(t, sampler.sample(key, now.Add(time.Minute)))
+	require.True(t, sampler.sample(other, now.Add(time.Minute)))
+	require.True(t, sampler.sample(key, now.Add(corsPreflightAuditInterval)))
+}
+
+func TestAuditCORSPreflight(t *testing.T) {
+	t.Parallel()
+
+	preflight := func(t *testing.T, s *Server, token *SignedToken) {
+		t.Helper()
+		handler := httpmw.AttachRequestID(tracing.StatusWriterMiddleware(
+			s.auditCORSPreflight(token, appurl.ApplicationURL{AppSlugOrPort: "app"})(
+				http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+					rw.Header().Set(httpmw.AccessControlAllowOriginHeader, "https://example.com")
+					rw.WriteHeader(http.StatusNoContent)
+				}),
+			),
+		))
+		r := httptest.NewRequest(http.MethodOptions, "/", nil)
+		r.Header.Set(httpmw.OriginHeader, "https://example.com")
+		r.Header.Set("Access-Control-Request-Method", http.MethodPut)
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+	newServer := func() (*Server, *audit.MockAuditor) {
+		mock := audit.NewMock()
+		var auditor atomic.Pointer[audit.Auditor]
+		var a audit.Auditor = mock
+		auditor.Store(&a)
+		return &Server{Auditor: &auditor}, mock
+	}
+
+	t.Run("Allowlist", func(t *testing.T) {
+		t.Parallel()
+
+		s, mock := newServer()
+		token := &SignedToken{UserID: uuid.New(), AgentID: uuid.New(), CORSBehavior: codersdk.CORSBehaviorAllowlist}
+		preflight(t, s, token)
+		preflight(t, s, token)
+
+		logs := mock.AuditLogs()
+		require.Len(t, logs, 1)
+		require.Equal(t, database.ResourceTypeWorkspaceAppCorsPreflight, logs[0].ResourceType)
+		require.Equal(t, token.UserID, logs[0].UserID)
+		require.Equal(t, "app", logs[0].ResourceTarget)
+		require.EqualValues(t, http.StatusNoContent, logs[0].StatusCode)
+	})
+
+	t.Run("Simple", func(t *testing.T) {
+		t.Parallel()
+
+		s, mock := newServer()
+		preflight(t, s, &SignedToken{CORSBehavior: codersdk.CORSBehaviorSimple})
+		require.Empty(t, mock.AuditLogs())
+	})
+}
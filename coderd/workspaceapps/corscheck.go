@@ -0,0 +1,90 @@
+package workspaceapps
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/httpmw"
+	"github.com/coder/coder/v2/coderd/workspaceapps/appurl"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+// CORSCheck runs a preflight and a request from origin through the same CORS
+// middleware the proxy uses for app with the template's CORS settings, and
+// reports the headers it would send. The requests never reach the app.
+func (s *Server) CORSCheck(ctx context.Context, app appurl.ApplicationURL, tmpl database.Template, origin, method string) (codersdk.WorkspaceAppCORSCheckResponse, error) {
+	behavior := codersdk.CORSBehavior(tmpl.CorsBehavior)
+	resp := codersdk.WorkspaceAppCORSCheckResponse{
+		CORSBehavior: behavior,
+	}
+	if behavior == codersdk.CORSBehaviorPassthru {
+		resp.Message = "The app sets its own CORS headers. coderd does not add or remove any."
+		resp.Preflight.Headers = map[string]string{}
+		resp.Request.Headers = map[string]string{}
+		return resp, nil
+	}
+
+	token := &SignedToken{
+		CORSBehavior:         behavior,
+		CORSAllowedOrigins:   tmpl.CorsAllowedOrigins,
+		CORSAllowCredentials: tmpl.CorsAllowCredentials,
+	}
+	handler := s.determineCORSBehavior(token, app)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	var err error
+	resp.Preflight, err = checkCORS(ctx, handler, http.MethodOptions, origin, method)
+	if err != nil {
+		return codersdk.WorkspaceAppCORSCheckResponse{}, err
+	}
+	resp.Request, err = checkCORS(ctx, handler, method, origin, "")
+	if err != nil {
+		return codersdk.WorkspaceAppCORSCheckResponse{}, err
+	}
+	if !resp.Preflight.Allowed && !resp.Request.Allowed {
+		switch behavior {
+		case codersdk.CORSBehaviorAllowlist:
+			resp.Message = "The origin is not in the template's CORS allowlist, or the method or headers are not allowed."
+		default:
+			resp.Message = "Only apps owned by the same user on the wildcard app hostname may make cross-origin requests to this app."
+		}
+	}
+	return resp, nil
+}
+
+func checkCORS(ctx context.Context, handler http.Handler, method, origin, requestMethod string) (codersdk.WorkspaceAppCORSCheckResult, error) {
+	req, err := http.NewRequestWithContext(ctx, method, "/", nil)
+	if err != nil {
+		return codersdk.WorkspaceAppCORSCheckResult{}, err
+	}
+	req.Header.Set(httpmw.OriginHeader, origin)
+	if requestMethod != "" {
+		req.Header.Set("Access-Control-Request-Method", requestMethod)
+	}
+
+	rw := &headerRecorder{header: http.Header{}}
+	handler.ServeHTTP(rw, req)
+
+	result := codersdk.WorkspaceAppCORSCheckResult{
+		Allowed: rw.header.Get(httpmw.AccessControlAllowOriginHeader) != "",
+		Headers: map[string]string{},
+	}
+	for key, values := range rw.header {
+		if strings.HasPrefix(key, "Access-Control-") || key == httpmw.VaryHeader {
+			result.Headers[key] = strings.Join(values, ", ")
+		}
+	}
+	return result, nil
+}
+
+// headerRecorder is a response writer that only keeps the headers.
+type headerRecorder struct {
+	header http.Header
+}
+
+func (r *headerRecorder) Header() http.Header { return r.header }
+
+func (*headerRecorder) Write(b []byte) (int, error) { return len(b), nil }
+
+func (*headerRecorder) WriteHeader(int) {}
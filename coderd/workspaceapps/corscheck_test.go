@@ -0,0 +1,95 @@
+package workspaceapps_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/workspaceapps"
+	"github.com/coder/coder/v2/coderd/workspaceapps/appurl"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestCORSCheck(t *testing.T) {
+	t.Parallel()
+
+	regex, err := appurl.CompileHostnamePattern("*--apps.coder.com")
+	require.NoError(t, err)
+	server := &workspaceapps.Server{HostnameRegex: regex}
+	app := appurl.ApplicationURL{
+		AppSlugOrPort: "app",
+		AgentName:     "agent",
+		WorkspaceName: "workspace",
+		Username:      "user",
+	}
+
+	t.Run("Simple", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		origin := "https://other--agent--workspace--user--apps.coder.com"
+		resp, err := server.CORSCheck(ctx, app, database.Template{CorsBehavior: database.CorsBehaviorSimple}, origin, http.MethodPut)
+		require.NoError(t, err)
+		require.True(t, resp.Preflight.Allowed)
+		require.Equal(t, origin, resp.Preflight.Headers["Access-Control-Allow-Origin"])
+		require.Equal(t, http.MethodPut, resp.Preflight.Headers["Access-Control-Allow-Methods"])
+		require.True(t, resp.Request.Allowed)
+		require.Equal(t, "true", resp.Request.Headers["Access-Control-Allow-Credentials"])
+
+		resp, err = server.CORSCheck(ctx, app, database.Template{CorsBehavior: database.CorsBehaviorSimple}, "https://other--agent--workspace--someone--apps.coder.com", http.MethodGet)
+		require.NoError(t, err)
+		require.False(t, resp.Preflight.Allowed)
+		require.False(t, resp.Request.Allowed)
+		require.NotEmpty(t, resp.Message)
+	})
+
+	t.Run("Allowlist", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		tmpl := database.Template{
+			CorsBehavior:       database.CorsBehaviorAllowlist,
+			CorsAllowedOrigins: []string{"https://*.example.com"},
+		}
+		resp, err := server.CORSCheck(ctx, app, tmpl, "https://preview.example.com", http.MethodGet)
+		require.NoError(t, err)
+		require.True(t, resp.Preflight.Allowed)
+		require.True(t, resp.Request.Allowed)
+		require.Empty(t, resp.Request.Headers["Access-Control-Allow-Credentials"])
+
+		resp, err = server.CORSCheck(ctx, app, tmpl, "https://example.org", http.MethodGet)
+		require.NoError(t, err)
+		require.False(t, resp.Preflight.Allowed)
+		require.False(t, resp.Request.Allowed)
+	})
+
+	t.Run("AllowlistCredentials", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		tmpl := database.Template{
+			CorsBehavior:         database.CorsBehaviorAllowlist,
+			CorsAllowedOrigins:   []string{"https://app.example.com"},
+			CorsAllowCredentials: true,
+		}
+		resp, err := server.CORSCheck(ctx, app, tmpl, "https://app.example.com", http.MethodGet)
+		require.NoError(t, err)
+		require.True(t, resp.Request.Allowed)
+		require.Equal(t, "true", resp.Request.Headers["Access-Control-Allow-Credentials"])
+	})
+
+	t.Run("Passthru", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		resp, err := server.CORSCheck(ctx, app, database.Template{CorsBehavior: database.CorsBehaviorPassthru}, "https://example.com", http.MethodGet)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.CORSBehaviorPassthru, resp.CORSBehavior)
+		require.Empty(t, resp.Preflight.Headers)
+		require.Empty(t, resp.Request.Headers)
+		require.NotEmpty(t, resp.Message)
+	})
+}
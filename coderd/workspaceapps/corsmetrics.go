@@ -0,0 +1,88 @@
+package workspaceapps
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/coder/coder/v2/coderd/httpmw"
+	"github.com/coder/coder/v2/coderd/workspaceapps/appurl"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+const (
+	corsResultAllowed = "allowed"
+	corsResultDenied  = "denied"
+)
+
+// CORSMetrics counts the cross-origin preflights and requests to subdomain
+// apps, by CORS behavior, app and whether they were allowed.
+type CORSMetrics struct {
+	Preflights *prometheus.CounterVec
+	Requests   *prometheus.CounterVec
+}
+
+func NewCORSMetrics(reg prometheus.Registerer) *CORSMetrics {
+	labels := []string{"behavior", "app", "result"}
+	return &CORSMetrics{
+		Preflights: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "coderd",
+			Subsystem: "workspace_apps",
+			Name:      "cors_preflights_total",
+			Help:      "The number of CORS preflights to workspace apps by CORS behavior, app, and result.",
+		}, labels),
+		Requests: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "coderd",
+			Subsystem: "workspace_apps",
+			Name:      "cors_requests_total",
+			Help:      "The number of cross-origin requests to workspace apps by CORS behavior, app, and result.",
+		}, labels),
+	}
+}
+
+// recordCORSDecision counts whether cross-origin preflights and requests to
+// the app were allowed, which is the case if the response allows the origin.
+// Same-origin requests are not counted.
+func (s *Server) recordCORSDecision(token *SignedToken, app appurl.ApplicationURL) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get(httpmw.OriginHeader)
+			if s.CORSMetrics == nil || origin == "" || sameOrigin(origin, r.Host) {
+				next.ServeHTTP(rw, r)
+				return
+			}
+
+			next.ServeHTTP(rw, r)
+
+			behavior := codersdk.CORSBehaviorSimple
+			if token != nil && token.CORSBehavior != "" {
+				behavior = token.CORSBehavior
+			}
+			// Ports are grouped together to keep the number of series bounded.
+			appLabel := app.AppSlugOrPort
+			if _, _, isPort := app.PortInfo(); isPort {
+				appLabel = "port"
+			}
+			result := corsResultDenied
+			if rw.Header().Get(httpmw.AccessControlAllowOriginHeader) != "" {
+				result = corsResultAllowed
+			}
+
+			counter := s.CORSMetrics.Requests
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				counter = s.CORSMetrics.Preflights
+			}
+			counter.WithLabelValues(string(behavior), appLabel, result).Inc()
+		})
+	}
+}
+
+// sameOrigin reports whether origin refers to host. Browsers send an Origin
+// header on some same-origin requests, which CORS doesn't apply to.
+func sameOrigin(origin, host string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, host)
+}
@@ -0,0 +1,56 @@
+package workspaceapps
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	promtest "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/coderd/httpmw"
+	"github.com/coder/coder/v2/coderd/workspaceapps/appurl"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+func TestRecordCORSDecision(t *testing.T) {
+	t.Parallel()
+
+	metrics := NewCORSMetrics(prometheus.NewRegistry())
+	s := &Server{CORSMetrics: metrics}
+	token := &SignedToken{CORSBehavior: codersdk.CORSBehaviorAllowlist}
+
+	serve := func(app, method, origin string, preflight, allow bool) {
+		handler := s.recordCORSDecision(token, appurl.ApplicationURL{AppSlugOrPort: app})(
+			http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+				if allow {
+					rw.Header().Set(httpmw.AccessControlAllowOriginHeader, origin)
+				}
+				rw.WriteHeader(http.StatusNoContent)
+			}),
+		)
+		r := httptest.NewRequest(method, "https://app--agent--workspace--user.apps.coder.com/", nil)
+		r.Header.Set(httpmw.OriginHeader, origin)
+		if preflight {
+			r.Header.Set("Access-Control-Request-Method", http.MethodPut)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	serve("app", http.MethodOptions, "https://example.com", true, true)
+	serve("app", http.MethodOptions, "https://evil.com", true, false)
+	serve("app", http.MethodGet, "https://example.com", false, true)
+	serve("app", http.MethodGet, "https://evil.com", false, false)
+	serve("app", http.MethodGet, "https://evil.com", false, false)
+	serve("8080", http.MethodGet, "https://example.com", false, true)
+	// Same-origin requests aren't counted.
+	serve("app", http.MethodPost, "https://app--agent--workspace--user.apps.coder.com", false, false)
+
+	allowlist := string(codersdk.CORSBehaviorAllowlist)
+	require.Equal(t, 1.0, promtest.ToFloat64(metrics.Preflights.WithLabelValues(allowlist, "app", corsResultAllowed)))
+	require.Equal(t, 1.0, promtest.ToFloat64(metrics.Preflights.WithLabelValues(allowlist, "app", corsResultDenied)))
+	require.Equal(t, 1.0, promtest.ToFloat64(metrics.Requests.WithLabelValues(allowlist, "app", corsResultAllowed)))
+	require.Equal(t, 2.0, promtest.ToFloat64(metrics.Requests.WithLabelValues(allowlist, "app", corsResultDenied)))
+	require.Equal(t, 1.0, promtest.ToFloat64(metrics.Requests.WithLabelValues(allowlist, "port", corsResultAllowed)))
+}
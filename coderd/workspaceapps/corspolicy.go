@@ -0,0 +1,85 @@
+package workspaceapps
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/runtimeconfig"
+	"github.com/coder/coder/v2/coderd/util/ptr"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+// DefaultOrganizationCORSSettings are the CORS settings of organizations that
+// haven't configured any. They match the behavior before organization
+// policies existed: templates default to simple and may use any behavior.
+var DefaultOrganizationCORSSettings = codersdk.OrganizationCORSSettings{
+	DefaultBehavior:      codersdk.CORSBehaviorSimple,
+	AllowLooserTemplates: true,
+}
+
+// CORSPolicy stores each organization's default CORS policy for workspace apps
+// in runtime config.
+type CORSPolicy struct {
+	Manager  *runtimeconfig.Manager
+	Settings runtimeconfig.RuntimeEntry[*OrganizationCORSSettings]
+}
+
+func NewCORSPolicy(manager *runtimeconfig.Manager) *CORSPolicy {
+	return &CORSPolicy{
+		Manager:  manager,
+		Settings: runtimeconfig.MustNew[*OrganizationCORSSettings]("workspace-app-cors-settings"),
+	}
+}
+
+// OrganizationSettings returns the organization's CORS settings, or
+// DefaultOrganizationCORSSettings if it hasn't configured any.
+func (p *CORSPolicy) OrganizationSettings(ctx context.Context, db database.Store, orgID uuid.UUID) (codersdk.OrganizationCORSSettings, error) {
+	settings, err := p.Settings.Resolve(ctx, p.Manager.OrganizationResolver(db, orgID))
+	if err != nil {
+		if xerrors.Is(err, runtimeconfig.ErrEntryNotFound) {
+			return DefaultOrganizationCORSSettings, nil
+		}
+		return codersdk.OrganizationCORSSettings{}, xerrors.Errorf("resolve organization cors settings: %w", err)
+	}
+	return codersdk.OrganizationCORSSettings(*settings), nil
+}
+
+func (p *CORSPolicy) UpdateOrganizationSettings(ctx context.Context, db database.Store, orgID uuid.UUID, settings codersdk.OrganizationCORSSettings) error {
+	err := p.Settings.SetRuntimeValue(ctx, p.Manager.OrganizationResolver(db, orgID), ptr.Ref(OrganizationCORSSettings(settings)))
+	if err != nil {
+		return xerrors.Errorf("update organization cors settings: %w", err)
+	}
+	return nil
+}
+
+// CheckTemplateCORSBehavior returns an error if the organization's settings
+// don't allow templates to use behavior.
+func CheckTemplateCORSBehavior(settings codersdk.OrganizationCORSSettings, behavior codersdk.CORSBehavior) error {
+	if !settings.AllowLooserTemplates && behavior.Looser(settings.DefaultBehavior) {
+		return xerrors.Errorf("the organization only allows the %q CORS behavior or a stricter one", settings.DefaultBehavior)
+	}
+	return nil
+}
+
+// EffectiveCORSBehavior returns the behavior apps of a template with behavior
+// are served with under the organization's settings.
+func EffectiveCORSBehavior(settings codersdk.OrganizationCORSSettings, behavior codersdk.CORSBehavior) codersdk.CORSBehavior {
+	if CheckTemplateCORSBehavior(settings, behavior) != nil {
+		return settings.DefaultBehavior
+	}
+	return behavior
+}
+
+type OrganizationCORSSettings codersdk.OrganizationCORSSettings
+
+func (s *OrganizationCORSSettings) Set(v string) error {
+	return json.Unmarshal([]byte(v), s)
+}
+
+func (s *OrganizationCORSSettings) String() string {
+	return runtimeconfig.JSONString(s)
+}
@@ -0,0 +1,50 @@
+package workspaceapps_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/coderd/workspaceapps"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+func TestCORSPolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Default", func(t *testing.T) {
+		t.Parallel()
+
+		settings := workspaceapps.DefaultOrganizationCORSSettings
+		for _, behavior := range []codersdk.CORSBehavior{codersdk.CORSBehaviorSimple, codersdk.CORSBehaviorAllowlist, codersdk.CORSBehaviorPassthru} {
+			require.NoError(t, workspaceapps.CheckTemplateCORSBehavior(settings, behavior))
+			require.Equal(t, behavior, workspaceapps.EffectiveCORSBehavior(settings, behavior))
+		}
+	})
+
+	t.Run("TightenOnly", func(t *testing.T) {
+		t.Parallel()
+
+		settings := codersdk.OrganizationCORSSettings{
+			DefaultBehavior:      codersdk.CORSBehaviorAllowlist,
+			AllowLooserTemplates: false,
+		}
+		require.NoError(t, workspaceapps.CheckTemplateCORSBehavior(settings, codersdk.CORSBehaviorSimple))
+		require.NoError(t, workspaceapps.CheckTemplateCORSBehavior(settings, codersdk.CORSBehaviorAllowlist))
+		require.Error(t, workspaceapps.CheckTemplateCORSBehavior(settings, codersdk.CORSBehaviorPassthru))
+
+		require.Equal(t, codersdk.CORSBehaviorSimple, workspaceapps.EffectiveCORSBehavior(settings, codersdk.CORSBehaviorSimple))
+		require.Equal(t, codersdk.CORSBehaviorAllowlist, workspaceapps.EffectiveCORSBehavior(settings, codersdk.CORSBehaviorPassthru))
+	})
+
+	t.Run("AllowLooser", func(t *testing.T) {
+		t.Parallel()
+
+		settings := codersdk.OrganizationCORSSettings{
+			DefaultBehavior:      codersdk.CORSBehaviorSimple,
+			AllowLooserTemplates: true,
+		}
+		require.NoError(t, workspaceapps.CheckTemplateCORSBehavior(settings, codersdk.CORSBehaviorPassthru))
+		require.Equal(t, codersdk.CORSBehaviorPassthru, workspaceapps.EffectiveCORSBehavior(settings, codersdk.CORSBehaviorPassthru))
+	})
+}
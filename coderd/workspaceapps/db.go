@@ -46,6 +46,7 @@ type DBTokenProvider struct {
 	WorkspaceAgentInactiveTimeout   time.Duration
 	WorkspaceAppAuditSessionTimeout time.Duration
 	Keycache                        cryptokeys.SigningKeycache
+	CORSPolicy                      *CORSPolicy
 }
 
 var _ SignedTokenProvider = &DBTokenProvider{}
@@ -60,6 +61,7 @@ func NewDBTokenProvider(log slog.Logger,
 	workspaceAgentInactiveTimeout time.Duration,
 	workspaceAppAuditSessionTimeout time.Duration,
 	signer cryptokeys.SigningKeycache,
+	corsPolicy *CORSPolicy,
 ) SignedTokenProvider {
 	if workspaceAgentInactiveTimeout == 0 {
 		workspaceAgentInactiveTimeout = 1 * time.Minute
@@ -79,6 +81,7 @@ func NewDBTokenProvider(log slog.Logger,
 		WorkspaceAgentInactiveTimeout:   workspaceAgentInactiveTimeout,
 		WorkspaceAppAuditSessionTimeout: workspaceAppAuditSessionTimeout,
 		Keycache:                        signer,
+		CORSPolicy:                      corsPolicy,
 	}
 }
 
@@ -151,7 +154,16 @@ func (p *DBTokenProvider) Issue(ctx context.Context, rw http.ResponseWriter, r *
 	if dbReq.AppURL != nil {
 		token.AppURL = dbReq.AppURL.String()
 	}
-	token.CORSBehavior = codersdk.CORSBehavior(dbReq.CorsBehavior)
+	corsSettings, err := p.CORSPolicy.OrganizationSettings(dangerousSystemCtx, p.Database, dbReq.Workspace.OrganizationID)
+	if err != nil {
+		WriteWorkspaceApp500(p.Logger, p.DashboardURL, rw, r, &appReq, err, "get organization cors settings")
+		return nil, "", false
+	}
+	token.CORSBehavior = EffectiveCORSBehavior(corsSettings, codersdk.CORSBehavior(dbReq.CorsBehavior))
+	if token.CORSBehavior == codersdk.CORSBehaviorAllowlist {
+		token.CORSAllowedOrigins = dbReq.CorsAllowedOrigins
+		token.CORSAllowCredentials = dbReq.CorsAllowCredentials
+	}
 
 	// Verify the user has access to the app.
 	authed, warnings, err := p.authorizeRequest(r.Context(), authz, dbReq)
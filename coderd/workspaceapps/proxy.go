@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -20,6 +21,7 @@ import (
 
 	"cdr.dev/slog"
 	"github.com/coder/coder/v2/agent/agentssh"
+	"github.com/coder/coder/v2/coderd/audit"
 	"github.com/coder/coder/v2/coderd/cryptokeys"
 	"github.com/coder/coder/v2/coderd/database/dbtime"
 	"github.com/coder/coder/v2/coderd/httpapi"
@@ -113,10 +115,21 @@ type Server struct {
 	// calls to the dashboard are not possible due to CORs.
 	DisablePathApps bool
 	Cookies         codersdk.HTTPCookieConfig
+	// CORS configures the CORS headers set on app responses for the simple
+	// and allowlist behaviors.
+	CORS codersdk.WorkspaceAppCORSConfig
+	// Auditor records CORS preflights to apps with a non-default CORS
+	// behavior. It is optional.
+	Auditor *atomic.Pointer[audit.Auditor]
+	// CORSMetrics counts allowed and denied cross-origin requests to apps. It
+	// is optional.
+	CORSMetrics *CORSMetrics
 
 	AgentProvider  AgentProvider
 	StatsCollector *StatsCollector
 
+	corsPreflights corsPreflightSampler
+
 	websocketWaitMutex sync.Mutex
 	websocketWaitGroup sync.WaitGroup
 }
@@ -329,7 +342,12 @@ func (s *Server) workspaceAppsProxyPath(rw http.ResponseWriter, r *http.Request)
 func (s *Server) determineCORSBehavior(token *SignedToken, app appurl.ApplicationURL) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		// Create the CORS middleware handler upfront.
-		corsHandler := httpmw.WorkspaceAppCors(s.HostnameRegex, app)(next)
+		corsOpts := cors.OptionsFromConfig(s.CORS)
+		corsHandler := httpmw.WorkspaceAppCors(s.HostnameRegex, app, corsOpts)(next)
+		var allowlistHandler http.Handler
+		if token != nil && token.CORSBehavior == codersdk.CORSBehaviorAllowlist {
+			allowlistHandler = cors.Allowlist(token.CORSAllowedOrigins, token.CORSAllowCredentials, corsOpts)(next)
+		}
 
 		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
 			var behavior codersdk.CORSBehavior
@@ -347,6 +365,9 @@ func (s *Server) determineCORSBehavior(token *SignedToken, app appurl.Applicatio
 				// Bypass the CORS middleware.
 				next.ServeHTTP(rw, r)
 				return
+			case codersdk.CORSBehaviorAllowlist:
+				// Only allow the origins configured on the template.
+				allowlistHandler.ServeHTTP(rw, r)
 			default:
 				// Apply the CORS middleware.
 				corsHandler.ServeHTTP(rw, r)
@@ -454,7 +475,7 @@ func (s *Server) HandleSubdomain(middlewares ...func(http.Handler) http.Handler)
 			}
 
 			// Proxy the request (possibly with the CORS middleware).
-			mws := chi.Middlewares(append(middlewares, s.determineCORSBehavior(token, app)))
+			mws := chi.Middlewares(append(middlewares, s.auditCORSPreflight(token, app), s.recordCORSDecision(token, app), s.determineCORSBehavior(token, app)))
 			mws.Handler(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
 				s.proxyWorkspaceApp(rw, r, *token, r.URL.Path, app)
 			})).ServeHTTP(rw, r.WithContext(ctx))
@@ -207,6 +207,12 @@ type databaseRequest struct {
 	// CorsBehavior is set at the template level for all apps/ports in a workspace, and can
 	// either be the current CORS middleware 'simple' or bypass the cors middleware with 'passthru'.
 	CorsBehavior database.CorsBehavior
+	// CorsAllowedOrigins is the template's list of origins that may make
+	// cross-origin requests to apps when CorsBehavior is 'allowlist'.
+	CorsAllowedOrigins []string
+	// CorsAllowCredentials allows credentialed cross-origin requests from
+	// CorsAllowedOrigins.
+	CorsAllowCredentials bool
 }
 
 // getDatabase does queries to get the owner user, workspace and agent
@@ -420,14 +426,16 @@ func (r Request) getDatabase(ctx context.Context, db database.Store) (*databaseR
 	}
 
 	return &databaseRequest{
-		Request:         r,
-		User:            user,
-		Workspace:       workspace,
-		Agent:           agent,
-		App:             app,
-		AppURL:          appURLParsed,
-		AppSharingLevel: appSharingLevel,
-		CorsBehavior:    corsBehavior,
+		Request:              r,
+		User:                 user,
+		Workspace:            workspace,
+		Agent:                agent,
+		App:                  app,
+		AppURL:               appURLParsed,
+		AppSharingLevel:      appSharingLevel,
+		CorsBehavior:         corsBehavior,
+		CorsAllowedOrigins:   tmpl.CorsAllowedOrigins,
+		CorsAllowCredentials: tmpl.CorsAllowCredentials,
 	}, nil
 }
 
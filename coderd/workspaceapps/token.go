@@ -27,6 +27,10 @@ type SignedToken struct {
 	AgentID      uuid.UUID             `json:"agent_id"`
 	AppURL       string                `json:"app_url"`
 	CORSBehavior codersdk.CORSBehavior `json:"cors_behavior"`
+	// CORSAllowedOrigins and CORSAllowCredentials are only set when
+	// CORSBehavior is "allowlist".
+	CORSAllowedOrigins   []string `json:"cors_allowed_origins,omitempty"`
+	CORSAllowCredentials bool     `json:"cors_allow_credentials,omitempty"`
 }
 
 // MatchesRequest returns true if the token matches the request. Any token that
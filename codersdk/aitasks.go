@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -47,6 +48,310 @@ func (c *ExperimentalClient) AITaskPrompts(ctx context.Context, buildIDs []uuid.
 	return prompts, json.NewDecoder(res.Body).Decode(&prompts)
 }
 
+// AITaskNameRequest is the request to generate a workspace name for a task.
+//
+// Experimental: This type is experimental and may change in the future.
+type AITaskNameRequest struct {
+	Prompt string `json:"prompt"`
+	// TemplateID optionally identifies the template the task will be created
+	// from, giving the name generator more context.
+	TemplateID    uuid.UUID `json:"template_id,omitempty" format:"uuid"`
+	RepositoryURL string    `json:"repository_url,omitempty"`
+	// AvoidNames lists names, such as existing workspace names, that the
+	// generated name must not collide with.
+	AvoidNames []string `json:"avoid_names,omitempty"`
+	// Count is the number of candidate names to generate. It defaults to 1.
+	Count int `json:"count,omitempty"`
+}
+
+// AITaskNameResponse contains the generated workspace names for a task.
+//
+// Experimental: This type is experimental and may change in the future.
+type AITaskNameResponse struct {
+	// Name is the first, preferred candidate.
+	Name string `json:"name"`
+	// Names contains all the distinct candidates, as many as requested.
+	Names []string `json:"names"`
+}
+
+// AITaskName generates a workspace name for a task from its prompt and
+// optional context.
+//
+// Experimental: This method is experimental and may change in the future.
+func (c *ExperimentalClient) AITaskName(ctx context.Context, req AITaskNameRequest) (AITaskNameResponse, error) {
+	res, err := c.Request(ctx, http.MethodPost, "/api/experimental/aitasks/name", req)
+	if err != nil {
+		return AITaskNameResponse{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return AITaskNameResponse{}, ReadBodyAsError(res)
+	}
+	var resp AITaskNameResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// AITaskNameBatchRequest is the request to generate workspace names for up to
+// 20 tasks at once.
+//
+// Experimental: This type is experimental and may change in the future.
+type AITaskNameBatchRequest struct {
+	Requests []AITaskNameRequest `json:"requests"`
+}
+
+// AITaskNameBatchResult is the result of a single request in a batch.
+//
+// Experimental: This type is experimental and may change in the future.
+type AITaskNameBatchResult struct {
+	// Index is the position of the request in the batch.
+	Index int      `json:"index"`
+	Name  string   `json:"name,omitempty"`
+	Names []string `json:"names,omitempty"`
+	// Error is set if no names could be generated for the request.
+	Error *Response `json:"error,omitempty"`
+}
+
+// AITaskNameBatchResponse contains a result for every request in a batch, in
+// the same order.
+//
+// Experimental: This type is experimental and may change in the future.
+type AITaskNameBatchResponse struct {
+	Results []AITaskNameBatchResult `json:"results"`
+}
+
+// AITaskNameBatch generates workspace names for several tasks at once. Each
+// request counts against the rate limit.
+//
+// Experimental: This method is experimental and may change in the future.
+func (c *ExperimentalClient) AITaskNameBatch(ctx context.Context, req AITaskNameBatchRequest) (AITaskNameBatchResponse, error) {
+	res, err := c.Request(ctx, http.MethodPost, "/api/experimental/aitasks/name/batch", req)
+	if err != nil {
+		return AITaskNameBatchResponse{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return AITaskNameBatchResponse{}, ReadBodyAsError(res)
+	}
+	var resp AITaskNameBatchResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// AITaskNameStreamEvent is sent while streaming task names. Events with a
+// Delta carry the model's response as it is generated. The final event carries
+// the validated names, which may differ from the streamed text.
+//
+// Experimental: This type is experimental and may change in the future.
+type AITaskNameStreamEvent struct {
+	Delta string   `json:"delta,omitempty"`
+	Name  string   `json:"name,omitempty"`
+	Names []string `json:"names,omitempty"`
+	// Error is set by the client if the server failed to generate the
+	// names. It is the last event.
+	Error *Response `json:"-" typescript:"-"`
+}
+
+// AITaskNameStream is the streaming variant of AITaskName. The channel is
+// closed after the final event, or when ctx is done.
+//
+// Experimental: This method is experimental and may change in the future.
+func (c *ExperimentalClient) AITaskNameStream(ctx context.Context, req AITaskNameRequest) (<-chan AITaskNameStreamEvent, error) {
+	//nolint:bodyclose
+	res, err := c.Request(ctx, http.MethodPost, "/api/experimental/aitasks/name/stream", req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		return nil, ReadBodyAsError(res)
+	}
+	nextEvent := ServerSentEventReader(ctx, res.Body)
+
+	events := make(chan AITaskNameStreamEvent, 16)
+	go func() {
+		defer close(events)
+		defer res.Body.Close()
+
+		for {
+			sse, err := nextEvent()
+			if err != nil {
+				return
+			}
+			b, ok := sse.Data.([]byte)
+			if !ok {
+				continue
+			}
+			var event AITaskNameStreamEvent
+			switch sse.Type {
+			case ServerSentEventTypeData:
+				if err := json.Unmarshal(b, &event); err != nil {
+					return
+				}
+			case ServerSentEventTypeError:
+				var resp Response
+				if err := json.Unmarshal(b, &resp); err != nil {
+					return
+				}
+				event.Error = &resp
+			default:
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case events <- event:
+			}
+			if event.Error != nil || len(event.Names) > 0 {
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// AITaskParametersRequest is the request to suggest parameter values for a
+// task workspace.
+//
+// Experimental: This type is experimental and may change in the future.
+type AITaskParametersRequest struct {
+	Prompt            string    `json:"prompt"`
+	TemplateVersionID uuid.UUID `json:"template_version_id" format:"uuid"`
+}
+
+// AITaskParametersResponse contains the suggested parameter values.
+//
+// Experimental: This type is experimental and may change in the future.
+type AITaskParametersResponse struct {
+	// Suggestions is a map of parameter names to suggested values. Only
+	// parameters the prompt implies a valid value for are included.
+	Suggestions map[string]string `json:"suggestions"`
+}
+
+// AITaskParameters suggests values for the rich parameters of a template
+// version from a task prompt, so they can be pre-filled when creating the
+// task.
+//
+// Experimental: This method is experimental and may change in the future.
+func (c *ExperimentalClient) AITaskParameters(ctx context.Context, req AITaskParametersRequest) (AITaskParametersResponse, error) {
+	res, err := c.Request(ctx, http.MethodPost, "/api/experimental/aitasks/parameters", req)
+	if err != nil {
+		return AITaskParametersResponse{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return AITaskParametersResponse{}, ReadBodyAsError(res)
+	}
+	var resp AITaskParametersResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// AITaskNameModelSettings overrides the models used to generate task names
+// for an organization.
+//
+// Experimental: This type is experimental and may change in the future.
+type AITaskNameModelSettings struct {
+	// Models is a fallback chain of models tried in order. When empty, the
+	// models configured for the deployment are used.
+	Models []string `json:"models"`
+}
+
+// AITaskNameModelSettings returns the organization's override of the models
+// used to generate task names.
+//
+// Experimental: This method is experimental and may change in the future.
+func (c *ExperimentalClient) AITaskNameModelSettings(ctx context.Context, orgID uuid.UUID) (AITaskNameModelSettings, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/experimental/aitasks/organizations/%s/name-models", orgID), nil)
+	if err != nil {
+		return AITaskNameModelSettings{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return AITaskNameModelSettings{}, ReadBodyAsError(res)
+	}
+	var resp AITaskNameModelSettings
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// PatchAITaskNameModelSettings updates the organization's override of the
+// models used to generate task names. An empty list removes the override.
+//
+// Experimental: This method is experimental and may change in the future.
+func (c *ExperimentalClient) PatchAITaskNameModelSettings(ctx context.Context, orgID uuid.UUID, req AITaskNameModelSettings) (AITaskNameModelSettings, error) {
+	res, err := c.Request(ctx, http.MethodPatch, fmt.Sprintf("/api/experimental/aitasks/organizations/%s/name-models", orgID), req)
+	if err != nil {
+		return AITaskNameModelSettings{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return AITaskNameModelSettings{}, ReadBodyAsError(res)
+	}
+	var resp AITaskNameModelSettings
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// AITaskUsageRequest filters the language model usage returned by
+// AITaskUsage. The organization and user filters are optional.
+//
+// Experimental: This type is experimental and may change in the future.
+type AITaskUsageRequest struct {
+	StartTime      time.Time `json:"start_time" format:"date-time"`
+	EndTime        time.Time `json:"end_time" format:"date-time"`
+	OrganizationID uuid.UUID `json:"organization_id,omitempty" format:"uuid"`
+	UserID         uuid.UUID `json:"user_id,omitempty" format:"uuid"`
+}
+
+// AITaskUsageResponse is the language model usage of AI tasks between the
+// start and end time.
+//
+// Experimental: This type is experimental and may change in the future.
+type AITaskUsageResponse struct {
+	StartTime time.Time     `json:"start_time" format:"date-time"`
+	EndTime   time.Time     `json:"end_time" format:"date-time"`
+	Usage     []AITaskUsage `json:"usage"`
+}
+
+// AITaskUsage is the language model usage of a user for a feature and model
+// within an organization. OrganizationID is nil for usage that wasn't tied to
+// an organization.
+//
+// Experimental: This type is experimental and may change in the future.
+type AITaskUsage struct {
+	UserID           uuid.UUID  `json:"user_id" format:"uuid"`
+	OrganizationID   *uuid.UUID `json:"organization_id,omitempty" format:"uuid"`
+	Feature          string     `json:"feature"`
+	Model            string     `json:"model"`
+	Calls            int64      `json:"calls"`
+	PromptTokens     int64      `json:"prompt_tokens"`
+	CompletionTokens int64      `json:"completion_tokens"`
+}
+
+// AITaskUsage returns the language model tokens used by AI tasks, by user,
+// organization, feature and model.
+//
+// Experimental: This method is experimental and may change in the future.
+func (c *ExperimentalClient) AITaskUsage(ctx context.Context, req AITaskUsageRequest) (AITaskUsageResponse, error) {
+	qp := url.Values{}
+	qp.Add("start_time", req.StartTime.Format(insightsTimeLayout))
+	qp.Add("end_time", req.EndTime.Format(insightsTimeLayout))
+	if req.OrganizationID != uuid.Nil {
+		qp.Add("organization_id", req.OrganizationID.String())
+	}
+	if req.UserID != uuid.Nil {
+		qp.Add("user_id", req.UserID.String())
+	}
+
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/experimental/aitasks/usage?%s", qp.Encode()), nil)
+	if err != nil {
+		return AITaskUsageResponse{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return AITaskUsageResponse{}, ReadBodyAsError(res)
+	}
+	var resp AITaskUsageResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
 type CreateTaskRequest struct {
 	TemplateVersionID       uuid.UUID `json:"template_version_id" format:"uuid"`
 	TemplateVersionPresetID uuid.UUID `json:"template_version_preset_id,omitempty" format:"uuid"`
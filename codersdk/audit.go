@@ -44,6 +44,10 @@ const (
 	// Deprecated: Workspace App connections are now included in the
 	// connection log.
 	ResourceTypeWorkspaceApp ResourceType = "workspace_app"
+	ResourceTypeAIRequest    ResourceType = "ai_request"
+
+	ResourceTypeOrganizationCORSSettings  ResourceType = "organization_cors_settings"
+	ResourceTypeWorkspaceAppCORSPreflight ResourceType = "workspace_app_cors_preflight"
 )
 
 func (r ResourceType) FriendlyString() string {
@@ -80,6 +84,12 @@ func (r ResourceType) FriendlyString() string {
 		return "notifications_settings"
 	case ResourceTypePrebuildsSettings:
 		return "prebuilds_settings"
+	case ResourceTypeAIRequest:
+		return "AI request"
+	case ResourceTypeOrganizationCORSSettings:
+		return "organization CORS policy"
+	case ResourceTypeWorkspaceAppCORSPreflight:
+		return "workspace app CORS preflight"
 	case ResourceTypeOAuth2ProviderApp:
 		return "oauth2 app"
 	case ResourceTypeOAuth2ProviderAppSecret:
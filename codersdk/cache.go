@@ -0,0 +1,265 @@
+package codersdk
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// maxCachedBodyBytes is the size of the largest response body Cache stores.
+const maxCachedBodyBytes = 4 << 20
+
+// CachedResponse is a response stored by Cache.
+// @typescript-ignore CachedResponse
+type CachedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+}
+
+// ResponseCache stores responses for Cache. Keys are hashes, so they are safe
+// to use as file names, and don't reveal the session token they are for.
+// Caches are best effort: failing to store a response is not an error.
+// @typescript-ignore ResponseCache
+type ResponseCache interface {
+	Get(key string) (CachedResponse, bool)
+	Set(key string, res CachedResponse)
+}
+
+// Cache returns a middleware that stores the responses to GET requests that
+// have an ETag or Last-Modified header in cache, and revalidates them with a
+// conditional request the next time. If the response has not changed, the
+// server responds with 304 Not Modified and no body, and the stored response
+// is returned in its place. This saves transferring large lists repeatedly,
+// e.g. in watch loops.
+//
+// Responses are cached per session token. Requests that set their own
+// conditional headers, and responses with Cache-Control: no-store, are not
+// cached.
+func Cache(cache ResponseCache) RequestMiddleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodGet ||
+				req.Header.Get("If-None-Match") != "" ||
+				req.Header.Get("If-Modified-Since") != "" ||
+				req.Header.Get("Range") != "" {
+				return next.RoundTrip(req)
+			}
+
+			key := cacheKey(req)
+			cached, ok := cache.Get(key)
+			if ok {
+				req = req.Clone(req.Context())
+				if etag := cached.Header.Get("ETag"); etag != "" {
+					req.Header.Set("If-None-Match", etag)
+				}
+				if lastModified := cached.Header.Get("Last-Modified"); lastModified != "" {
+					req.Header.Set("If-Modified-Since", lastModified)
+				}
+			}
+
+			res, err := next.RoundTrip(req)
+			if err != nil {
+				return nil, err
+			}
+			if ok && res.StatusCode == http.StatusNotModified {
+				_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 4096))
+				_ = res.Body.Close()
+				return cachedResponse(req, res, cached), nil
+			}
+			if !cacheable(res) {
+				return res, nil
+			}
+
+			body, err := io.ReadAll(io.LimitReader(res.Body, maxCachedBodyBytes+1))
+			if err != nil {
+				_ = res.Body.Close()
+				return nil, xerrors.Errorf("read response body: %w", err)
+			}
+			if len(body) > maxCachedBodyBytes {
+				// Too large to cache, so return the body as is.
+				res.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.MultiReader(bytes.NewReader(body), res.Body), res.Body}
+				return res, nil
+			}
+			_ = res.Body.Close()
+			res.Body = io.NopCloser(bytes.NewReader(body))
+			cache.Set(key, CachedResponse{
+				StatusCode: res.StatusCode,
+				Header:     res.Header.Clone(),
+				Body:       body,
+			})
+			return res, nil
+		})
+	}
+}
+
+// cacheKey hashes the URL of the request and the session token it is sent
+// with.
+func cacheKey(req *http.Request) string {
+	hash := sha256.New()
+	_, _ = io.WriteString(hash, req.URL.String())
+	_, _ = hash.Write([]byte{0})
+	_, _ = io.WriteString(hash, req.Header.Get(SessionTokenHeader))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func cacheable(res *http.Response) bool {
+	if res.StatusCode != http.StatusOK {
+		return false
+	}
+	if res.Header.Get("ETag") == "" && res.Header.Get("Last-Modified") == "" {
+		return false
+	}
+	return !strings.Contains(res.Header.Get("Cache-Control"), "no-store")
+}
+
+// cachedResponse returns the stored response in place of a 304 Not Modified
+// response.
+func cachedResponse(req *http.Request, notModified *http.Response, cached CachedResponse) *http.Response {
+	header := cached.Header.Clone()
+	header.Set("Content-Length", strconv.Itoa(len(cached.Body)))
+	return &http.Response{
+		Status:        strconv.Itoa(cached.StatusCode) + " " + http.StatusText(cached.StatusCode),
+		StatusCode:    cached.StatusCode,
+		Proto:         notModified.Proto,
+		ProtoMajor:    notModified.ProtoMajor,
+		ProtoMinor:    notModified.ProtoMinor,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(cached.Body)),
+		ContentLength: int64(len(cached.Body)),
+		Request:       req,
+		TLS:           notModified.TLS,
+	}
+}
+
+// MemoryResponseCache is a ResponseCache that keeps the most recently used
+// responses in memory.
+// @typescript-ignore MemoryResponseCache
+type MemoryResponseCache struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// recent lists the entries from the most to the least recently used.
+	recent *list.List
+}
+
+type memoryCacheEntry struct {
+	key string
+	res CachedResponse
+}
+
+var _ ResponseCache = &MemoryResponseCache{}
+
+// NewMemoryResponseCache returns a cache that holds up to maxEntries
+// responses, or 1024 if maxEntries is not positive.
+func NewMemoryResponseCache(maxEntries int) *MemoryResponseCache {
+	if maxEntries <= 0 {
+		maxEntries = 1024
+	}
+	return &MemoryResponseCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		recent:     list.New(),
+	}
+}
+
+func (c *MemoryResponseCache) Get(key string) (CachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return CachedResponse{}, false
+	}
+	c.recent.MoveToFront(elem)
+	//nolint:forcetypeassert // Only entries are stored.
+	return elem.Value.(*memoryCacheEntry).res, true
+}
+
+func (c *MemoryResponseCache) Set(key string, res CachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		//nolint:forcetypeassert // Only entries are stored.
+		elem.Value.(*memoryCacheEntry).res = res
+		c.recent.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.recent.PushFront(&memoryCacheEntry{key: key, res: res})
+	for c.recent.Len() > c.maxEntries {
+		oldest := c.recent.Back()
+		c.recent.Remove(oldest)
+		//nolint:forcetypeassert // Only entries are stored.
+		delete(c.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+}
+
+// DiskResponseCache is a ResponseCache that stores responses as files in a
+// directory, so that they are reused across processes, e.g. CLI invocations.
+// Responses are stored unencrypted, so the directory should only be readable
+// by the user.
+// @typescript-ignore DiskResponseCache
+type DiskResponseCache struct {
+	dir string
+}
+
+var _ ResponseCache = &DiskResponseCache{}
+
+// NewDiskResponseCache returns a cache that stores responses in dir, which is
+// created if it does not exist.
+func NewDiskResponseCache(dir string) (*DiskResponseCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, xerrors.Errorf("create cache dir: %w", err)
+	}
+	return &DiskResponseCache{dir: dir}, nil
+}
+
+func (c *DiskResponseCache) Get(key string) (CachedResponse, bool) {
+	data, err := os.ReadFile(filepath.Join(c.dir, key))
+	if err != nil {
+		return CachedResponse{}, false
+	}
+	var res CachedResponse
+	if err := json.Unmarshal(data, &res); err != nil {
+		return CachedResponse{}, false
+	}
+	return res, true
+}
+
+func (c *DiskResponseCache) Set(key string, res CachedResponse) {
+	data, err := json.Marshal(res)
+	if err != nil {
+		return
+	}
+	// Write to a temporary file first, so that concurrent readers never see
+	// a partial response.
+	f, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return
+	}
+	if err := os.Rename(f.Name(), filepath.Join(c.dir, key)); err != nil {
+		_ = os.Remove(f.Name())
+	}
+}
@@ -0,0 +1,123 @@
+package codersdk_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	// newServer returns a server that serves a body that changes with its
+	// version, and counts the full responses it sent.
+	newServer := func(t *testing.T) (*url.URL, *atomic.Int32, *atomic.Int32) {
+		t.Helper()
+
+		var version, sent atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			etag := strconv.Quote("v" + strconv.Itoa(int(version.Load())))
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			sent.Add(1)
+			w.Header().Set("ETag", etag)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"version":`+etag+`,"token":"`+r.Header.Get(codersdk.SessionTokenHeader)+`"}`)
+		}))
+		t.Cleanup(srv.Close)
+		u, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+		return u, &version, &sent
+	}
+
+	get := func(t *testing.T, client *codersdk.Client) string {
+		t.Helper()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		res, err := client.Request(ctx, http.MethodGet, "/api/v2/templates", nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		cache func(t *testing.T) codersdk.ResponseCache
+	}{
+		{
+			name: "Memory",
+			cache: func(*testing.T) codersdk.ResponseCache {
+				return codersdk.NewMemoryResponseCache(0)
+			},
+		},
+		{
+			name: "Disk",
+			cache: func(t *testing.T) codersdk.ResponseCache {
+				cache, err := codersdk.NewDiskResponseCache(t.TempDir())
+				require.NoError(t, err)
+				return cache
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			u, version, sent := newServer(t)
+			cache := tc.cache(t)
+			client := codersdk.New(u)
+			client.SetSessionToken("alice")
+			client.Use(codersdk.Cache(cache))
+
+			first := get(t, client)
+			require.Equal(t, first, get(t, client))
+			require.EqualValues(t, 1, sent.Load())
+
+			// A change is sent in full.
+			version.Add(1)
+			changed := get(t, client)
+			require.NotEqual(t, first, changed)
+			require.EqualValues(t, 2, sent.Load())
+
+			// Responses are not shared between session tokens.
+			other := codersdk.New(u)
+			other.SetSessionToken("bob")
+			other.Use(codersdk.Cache(cache))
+			require.Contains(t, get(t, other), "bob")
+			require.EqualValues(t, 3, sent.Load())
+			require.Contains(t, get(t, client), "alice")
+			require.EqualValues(t, 3, sent.Load())
+		})
+	}
+}
+
+func TestMemoryResponseCache(t *testing.T) {
+	t.Parallel()
+
+	cache := codersdk.NewMemoryResponseCache(2)
+	cache.Set("a", codersdk.CachedResponse{StatusCode: http.StatusOK})
+	cache.Set("b", codersdk.CachedResponse{StatusCode: http.StatusOK})
+	_, ok := cache.Get("a")
+	require.True(t, ok)
+
+	// b is the least recently used, so it is evicted.
+	cache.Set("c", codersdk.CachedResponse{StatusCode: http.StatusOK})
+	_, ok = cache.Get("b")
+	require.False(t, ok)
+	_, ok = cache.Get("a")
+	require.True(t, ok)
+	_, ok = cache.Get("c")
+	require.True(t, ok)
+}
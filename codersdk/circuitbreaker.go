@@ -0,0 +1,154 @@
+package codersdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/quartz"
+)
+
+// ErrCircuitOpen is returned for requests that a circuit breaker rejected
+// because their host is failing.
+var ErrCircuitOpen = xerrors.New("circuit breaker is open")
+
+// CircuitBreakerPolicy configures the circuit breaker installed by
+// CircuitBreaker.
+// @typescript-ignore CircuitBreakerPolicy
+type CircuitBreakerPolicy struct {
+	// FailureThreshold is the number of consecutive failed requests to a host
+	// that opens its circuit. It defaults to 5.
+	FailureThreshold int
+	// ProbeInterval is how long a circuit stays open before a single probe
+	// request is let through to check whether the host has recovered. It
+	// defaults to 30s.
+	ProbeInterval time.Duration
+	// Clock defaults to the real clock.
+	Clock quartz.Clock
+}
+
+// DefaultCircuitBreakerPolicy is the policy used for unset fields.
+var DefaultCircuitBreakerPolicy = CircuitBreakerPolicy{
+	FailureThreshold: 5,
+	ProbeInterval:    30 * time.Second,
+}
+
+// CircuitBreaker returns a middleware that stops sending requests to a host
+// after it fails FailureThreshold times in a row, so that clients back off
+// during an outage instead of adding to it. Requests fail with ErrCircuitOpen
+// until ProbeInterval has passed, then one probe request is sent: the circuit
+// closes if it succeeds, and opens again if it fails.
+//
+// Connection errors and 5xx statuses other than 501 and 505 are failures.
+// Install it before Retry, so that a retried request counts once.
+func CircuitBreaker(policy CircuitBreakerPolicy) RequestMiddleware {
+	if policy.FailureThreshold <= 0 {
+		policy.FailureThreshold = DefaultCircuitBreakerPolicy.FailureThreshold
+	}
+	if policy.ProbeInterval <= 0 {
+		policy.ProbeInterval = DefaultCircuitBreakerPolicy.ProbeInterval
+	}
+	if policy.Clock == nil {
+		policy.Clock = quartz.NewReal()
+	}
+	breaker := &circuitBreaker{
+		policy:   policy,
+		circuits: make(map[string]*circuit),
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if !breaker.allow(req.URL.Host) {
+				return nil, xerrors.Errorf("%s: %w", req.URL.Host, ErrCircuitOpen)
+			}
+			res, err := next.RoundTrip(req)
+			breaker.record(req.Context(), req.URL.Host, res, err)
+			return res, err
+		})
+	}
+}
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+type circuit struct {
+	state    circuitState
+	failures int
+	openedAt time.Time
+}
+
+type circuitBreaker struct {
+	policy CircuitBreakerPolicy
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// allow reports whether a request to host may be sent. Once the probe interval
+// of an open circuit has passed, only the first request is allowed, as a probe.
+func (b *circuitBreaker) allow(host string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[host]
+	if !ok {
+		return true
+	}
+	switch c.state {
+	case circuitOpen:
+		if b.policy.Clock.Since(c.openedAt) < b.policy.ProbeInterval {
+			return false
+		}
+		c.state = circuitHalfOpen
+		return true
+	case circuitHalfOpen:
+		// A probe is in flight.
+		return false
+	default:
+		return true
+	}
+}
+
+func (b *circuitBreaker) record(ctx context.Context, host string, res *http.Response, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[host]
+	if !ok {
+		c = &circuit{}
+		b.circuits[host] = c
+	}
+	if err != nil && (ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		// The caller gave up, which says nothing about the host. Let the
+		// next request probe it instead.
+		if c.state == circuitHalfOpen {
+			c.state = circuitOpen
+			c.openedAt = b.policy.Clock.Now().Add(-b.policy.ProbeInterval)
+		}
+		return
+	}
+
+	failed := err != nil
+	if res != nil {
+		failed = res.StatusCode >= http.StatusInternalServerError &&
+			res.StatusCode != http.StatusNotImplemented &&
+			res.StatusCode != http.StatusHTTPVersionNotSupported
+	}
+	if !failed {
+		delete(b.circuits, host)
+		return
+	}
+	c.failures++
+	if c.state == circuitHalfOpen || c.failures >= b.policy.FailureThreshold {
+		c.state = circuitOpen
+		c.openedAt = b.policy.Clock.Now()
+	}
+}
@@ -0,0 +1,84 @@
+package codersdk_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/testutil"
+	"github.com/coder/quartz"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	var (
+		failing atomic.Bool
+		calls   atomic.Int32
+	)
+	failing.Store(true)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	clock := quartz.NewMock(t)
+	client := codersdk.New(u)
+	client.Use(codersdk.CircuitBreaker(codersdk.CircuitBreakerPolicy{
+		FailureThreshold: 2,
+		ProbeInterval:    time.Minute,
+		Clock:            clock,
+	}))
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	get := func() (int, error) {
+		res, err := client.Request(ctx, http.MethodGet, "/", nil)
+		if err != nil {
+			return 0, err
+		}
+		defer res.Body.Close()
+		return res.StatusCode, nil
+	}
+
+	// The circuit opens after two failures.
+	for range 2 {
+		status, err := get()
+		require.NoError(t, err)
+		require.Equal(t, http.StatusServiceUnavailable, status)
+	}
+	_, err = get()
+	require.ErrorIs(t, err, codersdk.ErrCircuitOpen)
+	require.EqualValues(t, 2, calls.Load())
+
+	// A failed probe opens it again.
+	clock.Advance(time.Minute)
+	status, err := get()
+	require.NoError(t, err)
+	require.Equal(t, http.StatusServiceUnavailable, status)
+	_, err = get()
+	require.ErrorIs(t, err, codersdk.ErrCircuitOpen)
+	require.EqualValues(t, 3, calls.Load())
+
+	// A successful probe closes it.
+	failing.Store(false)
+	clock.Advance(time.Minute)
+	for range 2 {
+		status, err = get()
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, status)
+	}
+	require.EqualValues(t, 5, calls.Load())
+}
@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"slices"
 	"strings"
 	"sync"
 
@@ -117,18 +118,19 @@ func New(serverURL *url.URL) *Client {
 // Client is an HTTP caller for methods to the Coder API.
 // @typescript-ignore Client
 type Client struct {
-	// mu protects the fields sessionToken, logger, and logBodies. These
-	// need to be safe for concurrent access.
+	// mu protects the fields sessionToken, logger, logBodies and
+	// bodyLogOptions. These need to be safe for concurrent access.
 	mu                   sync.RWMutex
 	SessionTokenProvider SessionTokenProvider
 	logger               slog.Logger
 	logBodies            bool
+	bodyLogOptions       BodyLogOptions
 
 	HTTPClient *http.Client
 	URL        *url.URL
 
 	// PlainLogger may be set to log HTTP traffic in a human-readable form.
-	// It uses the LogBodies option.
+	// It uses the LogBodies and BodyLogOptions options.
 	PlainLogger io.Writer
 
 	// Trace can be enabled to propagate tracing spans to the Coder API.
@@ -189,6 +191,38 @@ func (c *Client) SetSessionTokenProvider(provider SessionTokenProvider) {
 	c.SessionTokenProvider = provider
 }
 
+// Clone returns a copy of the client that can be reconfigured without
+// affecting c, e.g. to use a different session token provider, a longer
+// HTTPClient timeout or more middlewares. The copy shares the transport of c,
+// including any middlewares installed so far, so the clients share
+// connections.
+func (c *Client) Clone() *Client {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	clone := &Client{
+		SessionTokenProvider: c.SessionTokenProvider,
+		logger:               c.logger,
+		logBodies:            c.logBodies,
+		bodyLogOptions: BodyLogOptions{
+			RedactFields: slices.Clone(c.bodyLogOptions.RedactFields),
+			MaxBytes:     c.bodyLogOptions.MaxBytes,
+		},
+		PlainLogger:              c.PlainLogger,
+		Trace:                    c.Trace,
+		DisableDirectConnections: c.DisableDirectConnections,
+	}
+	if c.URL != nil {
+		u := *c.URL
+		clone.URL = &u
+	}
+	if c.HTTPClient != nil {
+		httpClient := *c.HTTPClient
+		clone.HTTPClient = &httpClient
+	}
+	return clone
+}
+
 func prefixLines(prefix, s []byte) []byte {
 	ss := bytes.NewBuffer(make([]byte, 0, len(s)*2))
 	for _, line := range bytes.Split(s, []byte("\n")) {
@@ -201,9 +235,25 @@ func prefixLines(prefix, s []byte) []byte {
 
 // Request performs a HTTP request with the body provided. The caller is
 // responsible for closing the response body.
+//
+// If the session token provider is a RefreshingTokenProvider and the server
+// responds with 401 Unauthorized, the token is refreshed and the request is
+// retried once, unless the body is an io.Reader that can't be sent again.
 func (c *Client) Request(ctx context.Context, method, path string, body interface{}, opts ...RequestOption) (*http.Response, error) {
-	opts = append([]RequestOption{c.SessionTokenProvider.AsRequestOption()}, opts...)
-	return c.RequestWithoutSessionToken(ctx, method, path, body, opts...)
+	provider := c.SessionTokenProvider
+	res, err := c.RequestWithoutSessionToken(ctx, method, path, body, append([]RequestOption{provider.AsRequestOption()}, opts...)...)
+	refreshing, ok := provider.(*RefreshingTokenProvider)
+	if err != nil || !ok || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+	if _, ok := body.(io.Reader); ok {
+		return res, nil
+	}
+	if _, err := refreshing.refreshStale(ctx, res.Request.Header.Get(refreshing.header())); err != nil {
+		return res, nil
+	}
+	_ = res.Body.Close()
+	return c.RequestWithoutSessionToken(ctx, method, path, body, append([]RequestOption{provider.AsRequestOption()}, opts...)...)
 }
 
 // RequestWithoutSessionToken performs a HTTP request. It is similar to Request, but does not set
@@ -245,6 +295,7 @@ func (c *Client) RequestWithoutSessionToken(ctx context.Context, method, path st
 	var reqBody []byte
 	c.mu.RLock()
 	logBodies := c.logBodies
+	bodyLogOptions := c.bodyLogOptions
 	c.mu.RUnlock()
 	if r != nil && logBodies {
 		reqBody, err = io.ReadAll(r)
@@ -282,18 +333,20 @@ func (c *Client) RequestWithoutSessionToken(ctx context.Context, method, path st
 		slog.F("url", req.URL.String()),
 	)
 	tracing.RunWithoutSpan(ctx, func(ctx context.Context) {
-		c.Logger().Debug(ctx, "sdk request", slog.F("body", string(reqBody)))
+		c.Logger().Debug(ctx, "sdk request", slog.F("body", string(loggableBody(reqBody, bodyLogOptions))))
 	})
 
 	resp, err := c.HTTPClient.Do(req)
 
 	// We log after sending the request because the HTTP Transport may modify
 	// the request within Do, e.g. by adding headers.
+	// Bodies are appended to the dumps ourselves, so that they are redacted.
 	if resp != nil && c.PlainLogger != nil {
-		out, err := httputil.DumpRequest(resp.Request, logBodies)
+		out, err := httputil.DumpRequest(resp.Request, false)
 		if err != nil {
 			return nil, xerrors.Errorf("dump request: %w", err)
 		}
+		out = append(out, loggableBody(reqBody, bodyLogOptions)...)
 		out = prefixLines([]byte("http --> "), out)
 		_, _ = c.PlainLogger.Write(out)
 	}
@@ -302,15 +355,6 @@ func (c *Client) RequestWithoutSessionToken(ctx context.Context, method, path st
 		return nil, err
 	}
 
-	if c.PlainLogger != nil {
-		out, err := httputil.DumpResponse(resp, logBodies)
-		if err != nil {
-			return nil, xerrors.Errorf("dump response: %w", err)
-		}
-		out = prefixLines([]byte("http <-- "), out)
-		_, _ = c.PlainLogger.Write(out)
-	}
-
 	span.SetAttributes(httpconv.ClientResponse(resp)...)
 	span.SetStatus(httpconv.ClientStatus(resp.StatusCode))
 
@@ -330,6 +374,17 @@ func (c *Client) RequestWithoutSessionToken(ctx context.Context, method, path st
 			resp.Body = io.NopCloser(bytes.NewReader(respBody))
 		}
 	}
+	respBody = loggableBody(respBody, bodyLogOptions)
+
+	if c.PlainLogger != nil {
+		out, err := httputil.DumpResponse(resp, false)
+		if err != nil {
+			return nil, xerrors.Errorf("dump response: %w", err)
+		}
+		out = append(out, respBody...)
+		out = prefixLines([]byte("http <-- "), out)
+		_, _ = c.PlainLogger.Write(out)
+	}
 
 	// See above for why this is not logged to the span.
 	tracing.RunWithoutSpan(ctx, func(ctx context.Context) {
@@ -597,6 +652,46 @@ func AsError(err error) (*Error, bool) {
 	return e, xerrors.As(err, &e)
 }
 
+// IsNotFound reports whether err is an API error with status 404 Not Found.
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsUnauthorized reports whether err is an API error with status 401
+// Unauthorized, e.g. because the session token expired.
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden reports whether err is an API error with status 403 Forbidden.
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+// IsConflict reports whether err is an API error with status 409 Conflict,
+// e.g. because a resource with the same name exists.
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsRateLimited reports whether err is an API error with status 429 Too Many
+// Requests.
+func IsRateLimited(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
+
+// IsValidation reports whether err is an API error for invalid input that
+// lists the offending fields in its Validations.
+func IsValidation(err error) bool {
+	e, ok := AsError(err)
+	return ok && len(e.Validations) > 0
+}
+
+func hasStatus(err error, status int) bool {
+	e, ok := AsError(err)
+	return ok && e.StatusCode() == status
+}
+
 // RequestOption is a function that can be used to modify an http.Request.
 type RequestOption func(*http.Request)
 
@@ -14,6 +14,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/stretchr/testify/assert"
@@ -162,6 +163,37 @@ func Test_Client(t *testing.T) {
 	require.Contains(t, logStr, strings.ReplaceAll(resBody, `"`, `\"`))
 }
 
+func TestClientClone(t *testing.T) {
+	t.Parallel()
+
+	u, err := url.Parse("https://coder.example.com")
+	require.NoError(t, err)
+	client := New(u)
+	client.SetSessionToken("original")
+	client.SetLogBodies(true)
+	client.Trace = true
+	client.Use(Retry(RetryPolicy{}))
+
+	clone := client.Clone()
+	require.Equal(t, "original", clone.SessionToken())
+	require.True(t, clone.LogBodies())
+	require.True(t, clone.Trace)
+	require.Equal(t, client.URL.String(), clone.URL.String())
+	require.IsType(t, &RetryTransport{}, clone.HTTPClient.Transport)
+
+	// Changing the clone leaves the original alone.
+	clone.SetSessionToken("derived")
+	clone.HTTPClient.Timeout = time.Minute
+	clone.URL.Host = "other.example.com"
+	clone.Use(func(next http.RoundTripper) http.RoundTripper {
+		return &HeaderTransport{Transport: next, Header: http.Header{"X-Org": {"coder"}}}
+	})
+	require.Equal(t, "original", client.SessionToken())
+	require.Zero(t, client.HTTPClient.Timeout)
+	require.Equal(t, "coder.example.com", client.URL.Host)
+	require.IsType(t, &RetryTransport{}, client.HTTPClient.Transport)
+}
+
 func Test_readBodyAsError(t *testing.T) {
 	t.Parallel()
 
@@ -350,3 +382,27 @@ func marshal(res any) string {
 
 	return string(b)
 }
+
+func TestErrorPredicates(t *testing.T) {
+	t.Parallel()
+
+	wrap := func(status int, validations ...ValidationError) error {
+		return xerrors.Errorf("get workspace: %w", &Error{
+			statusCode: status,
+			Response:   Response{Message: "Failed.", Validations: validations},
+		})
+	}
+
+	require.True(t, IsNotFound(wrap(http.StatusNotFound)))
+	require.False(t, IsNotFound(wrap(http.StatusForbidden)))
+	require.True(t, IsUnauthorized(wrap(http.StatusUnauthorized)))
+	require.True(t, IsForbidden(wrap(http.StatusForbidden)))
+	require.True(t, IsConflict(wrap(http.StatusConflict)))
+	require.True(t, IsRateLimited(wrap(http.StatusTooManyRequests)))
+	require.True(t, IsValidation(wrap(http.StatusBadRequest, ValidationError{Field: "name", Detail: "required"})))
+	require.False(t, IsValidation(wrap(http.StatusBadRequest)))
+
+	// Errors that aren't from the API match nothing.
+	require.False(t, IsNotFound(xerrors.New("not found")))
+	require.False(t, IsValidation(nil))
+}
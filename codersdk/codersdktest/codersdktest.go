@@ -0,0 +1,235 @@
+// Package codersdktest provides a fake Coder API for unit testing code that
+// uses codersdk, without running coderd as coderdtest does.
+package codersdktest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/codersdk"
+)
+
+// SessionToken is the session token of the clients returned by Server.Client.
+const SessionToken = "codersdktest-session-token"
+
+// Request is a request received by a Server.
+type Request struct {
+	Method string
+	Path   string
+	// Pattern is the pattern of the route that matched the request, or empty
+	// if none did.
+	Pattern string
+	Header  http.Header
+	Query   url.Values
+	Body    []byte
+}
+
+// Fault makes a Server misbehave for the requests to a route.
+type Fault struct {
+	// Latency delays the response.
+	Latency time.Duration
+	// Status responds with a codersdk.Response with this status instead of
+	// calling the route's handler.
+	Status int
+	// Disconnect closes the connection without responding, which clients see
+	// as a connection error.
+	Disconnect bool
+	// Times limits the fault to the next number of matching requests. Zero
+	// applies it to every request.
+	Times int
+}
+
+// Server is a fake Coder API that serves programmable handlers per route and
+// records the requests it receives. Routes are http.ServeMux patterns, such
+// as "/api/v2/users/{user}", registered per method. Requests to unregistered
+// routes get 404 Not Found.
+type Server struct {
+	t   testing.TB
+	srv *httptest.Server
+
+	mu sync.Mutex
+	// handlerMux and faultMux match requests to the patterns of handlers and
+	// faults respectively, which are looked up separately so that e.g. a
+	// fault for every method applies to a route with a handler for GET.
+	handlerMux *http.ServeMux
+	faultMux   *http.ServeMux
+	handlers   map[string]http.Handler
+	faults     map[string]*Fault
+	latency    time.Duration
+	requests   []Request
+}
+
+// New starts a Server that is closed when the test ends.
+func New(t testing.TB) *Server {
+	t.Helper()
+
+	s := &Server{
+		t:          t,
+		handlerMux: http.NewServeMux(),
+		faultMux:   http.NewServeMux(),
+		handlers:   make(map[string]http.Handler),
+		faults:     make(map[string]*Fault),
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.srv.Close)
+	return s
+}
+
+// URL returns the URL of the server.
+func (s *Server) URL() *url.URL {
+	u, err := url.Parse(s.srv.URL)
+	require.NoError(s.t, err)
+	return u
+}
+
+// Client returns a client for the server, which is authenticated with
+// SessionToken. It is a regular codersdk.Client, so it can be configured and
+// given middlewares as in production.
+func (s *Server) Client() *codersdk.Client {
+	client := codersdk.New(s.URL())
+	client.SetSessionToken(SessionToken)
+	return client
+}
+
+// Handle serves requests with method to the route pattern with handler,
+// replacing any handler registered before. An empty method matches every
+// method.
+func (s *Server) Handle(method, pattern string, handler http.Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[routeKey(method, pattern)] = handler
+	s.handlerMux = newMux(s.handlers)
+}
+
+// HandleJSON responds to requests with method to the route pattern with
+// status and response encoded as JSON.
+func (s *Server) HandleJSON(method, pattern string, status int, response any) {
+	s.Handle(method, pattern, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, status, response)
+	}))
+}
+
+// Fault makes requests with method to the route pattern misbehave, whether a
+// handler is registered for it or not. It replaces any fault injected before.
+func (s *Server) Fault(method, pattern string, fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[routeKey(method, pattern)] = &fault
+	s.faultMux = newMux(s.faults)
+}
+
+// SetLatency delays every response by latency.
+func (s *Server) SetLatency(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = latency
+}
+
+// Requests returns the requests received so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// newMux returns a mux for matching requests to the patterns of routes. It is
+// rebuilt whenever routes change, because patterns can't be removed from or
+// replaced in a http.ServeMux.
+func newMux[T any](routes map[string]T) *http.ServeMux {
+	mux := http.NewServeMux()
+	for pattern := range routes {
+		mux.Handle(pattern, http.NotFoundHandler())
+	}
+	return mux
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, codersdk.Response{
+			Message: "Failed to read request body.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	s.mu.Lock()
+	_, pattern := s.handlerMux.Handler(r)
+	handler := s.handlers[pattern]
+	latency := s.latency
+	var fault Fault
+	_, faultPattern := s.faultMux.Handler(r)
+	if f, ok := s.faults[faultPattern]; ok {
+		fault = *f
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				delete(s.faults, faultPattern)
+				s.faultMux = newMux(s.faults)
+			}
+		}
+	}
+	s.requests = append(s.requests, Request{
+		Method:  r.Method,
+		Path:    r.URL.Path,
+		Pattern: pattern,
+		Header:  r.Header.Clone(),
+		Query:   r.URL.Query(),
+		Body:    body,
+	})
+	s.mu.Unlock()
+
+	if delay := latency + fault.Latency; delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-r.Context().Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+
+	switch {
+	case fault.Disconnect:
+		conn, _, err := http.NewResponseController(w).Hijack()
+		if !assert.NoError(s.t, err, "hijack connection") {
+			return
+		}
+		_ = conn.Close()
+	case fault.Status != 0:
+		writeJSON(w, fault.Status, codersdk.Response{
+			Message: fmt.Sprintf("Injected fault for %s.", faultPattern),
+		})
+	case handler != nil:
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		handler.ServeHTTP(w, r)
+	default:
+		writeJSON(w, http.StatusNotFound, codersdk.Response{
+			Message: fmt.Sprintf("No handler for %s %s.", r.Method, r.URL.Path),
+		})
+	}
+}
+
+func routeKey(method, pattern string) string {
+	if method == "" {
+		return pattern
+	}
+	return method + " " + pattern
+}
+
+func writeJSON(w http.ResponseWriter, status int, response any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,95 @@
+package codersdktest_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/codersdk/codersdktest"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m, testutil.GoleakOptions...)
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Handlers", func(t *testing.T) {
+		t.Parallel()
+
+		srv := codersdktest.New(t)
+		user := codersdk.User{ReducedUser: codersdk.ReducedUser{
+			MinimalUser: codersdk.MinimalUser{ID: uuid.New(), Username: "alice"},
+		}}
+		srv.HandleJSON(http.MethodGet, "/api/v2/users/{user}", http.StatusOK, user)
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		client := srv.Client()
+		got, err := client.User(ctx, "alice")
+		require.NoError(t, err)
+		require.Equal(t, user.ID, got.ID)
+
+		_, err = client.Organizations(ctx)
+		var sdkErr *codersdk.Error
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusNotFound, sdkErr.StatusCode())
+
+		requests := srv.Requests()
+		require.Len(t, requests, 2)
+		require.Equal(t, "GET /api/v2/users/{user}", requests[0].Pattern)
+		require.Equal(t, "/api/v2/users/alice", requests[0].Path)
+		require.Equal(t, codersdktest.SessionToken, requests[0].Header.Get(codersdk.SessionTokenHeader))
+		require.Empty(t, requests[1].Pattern)
+	})
+
+	t.Run("Faults", func(t *testing.T) {
+		t.Parallel()
+
+		srv := codersdktest.New(t)
+		srv.HandleJSON(http.MethodGet, "/api/v2/buildinfo", http.StatusOK, codersdk.BuildInfoResponse{Version: "v2.0.0"})
+		srv.Fault(http.MethodGet, "/api/v2/buildinfo", codersdktest.Fault{Status: http.StatusServiceUnavailable, Times: 1})
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		client := srv.Client()
+		_, err := client.BuildInfo(ctx)
+		var sdkErr *codersdk.Error
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusServiceUnavailable, sdkErr.StatusCode())
+
+		// The fault only applied once.
+		info, err := client.BuildInfo(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "v2.0.0", info.Version)
+
+		srv.Fault("", "/api/v2/buildinfo", codersdktest.Fault{Disconnect: true})
+		_, err = client.BuildInfo(ctx)
+		require.Error(t, err)
+		require.False(t, errors.As(err, &sdkErr), "expected a connection error")
+	})
+
+	t.Run("Latency", func(t *testing.T) {
+		t.Parallel()
+
+		srv := codersdktest.New(t)
+		srv.HandleJSON(http.MethodGet, "/api/v2/buildinfo", http.StatusOK, codersdk.BuildInfoResponse{})
+		srv.SetLatency(time.Second)
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		client := srv.Client()
+		client.HTTPClient.Timeout = 10 * time.Millisecond
+		_, err := client.BuildInfo(ctx)
+		require.Error(t, err)
+
+		srv.SetLatency(0)
+		_, err = client.BuildInfo(ctx)
+		require.NoError(t, err)
+	})
+}
@@ -1,8 +1,81 @@
 package codersdk
 
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
 type CORSBehavior string
 
 const (
 	CORSBehaviorSimple   CORSBehavior = "simple"
 	CORSBehaviorPassthru CORSBehavior = "passthru"
+	// CORSBehaviorAllowlist only allows cross-origin requests to apps from
+	// the origins in the template's CORS allowlist.
+	CORSBehaviorAllowlist CORSBehavior = "allowlist"
 )
+
+// Looser reports whether b lets more origins make cross-origin requests to
+// apps than other. Simple is the strictest behavior, followed by allowlist
+// and passthru.
+func (b CORSBehavior) Looser(other CORSBehavior) bool {
+	return b.rank() > other.rank()
+}
+
+func (b CORSBehavior) rank() int {
+	switch b {
+	case CORSBehaviorSimple:
+		return 0
+	case CORSBehaviorAllowlist:
+		return 1
+	case CORSBehaviorPassthru:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// OrganizationCORSSettings is the default CORS policy for workspace apps in an
+// organization.
+type OrganizationCORSSettings struct {
+	// DefaultBehavior is the CORS behavior of templates created without one.
+	DefaultBehavior CORSBehavior `json:"default_behavior"`
+	// AllowLooserTemplates lets templates use a CORS behavior that is looser
+	// than DefaultBehavior. When false, templates can't be set to a looser
+	// behavior, and apps of templates that already use one are served with
+	// DefaultBehavior instead.
+	AllowLooserTemplates bool `json:"allow_looser_templates"`
+}
+
+func (c *Client) OrganizationCORSSettings(ctx context.Context, orgID uuid.UUID) (OrganizationCORSSettings, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/organizations/%s/cors-policy", orgID), nil)
+	if err != nil {
+		return OrganizationCORSSettings{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OrganizationCORSSettings{}, ReadBodyAsError(res)
+	}
+	var resp OrganizationCORSSettings
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+func (c *Client) PatchOrganizationCORSSettings(ctx context.Context, orgID uuid.UUID, req OrganizationCORSSettings) (OrganizationCORSSettings, error) {
+	res, err := c.Request(ctx, http.MethodPatch, fmt.Sprintf("/api/v2/organizations/%s/cors-policy", orgID), req)
+	if err != nil {
+		return OrganizationCORSSettings{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OrganizationCORSSettings{}, ReadBodyAsError(res)
+	}
+	var resp OrganizationCORSSettings
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
@@ -1,8 +1,15 @@
 package codersdk
 
 import (
+	"context"
 	"net/http"
+	"sync"
+	"time"
 
+	"golang.org/x/sync/singleflight"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/quartz"
 	"github.com/coder/websocket"
 )
 
@@ -53,3 +60,133 @@ func (f FixedSessionTokenProvider) SetDialOption(opts *websocket.DialOptions) {
 		opts.HTTPHeader.Set(tokenHeader, f.SessionToken)
 	}
 }
+
+// TokenRefreshFunc obtains a new session token, e.g. by exchanging an OAuth2
+// or OIDC token, and returns it with the time it expires. A zero expiry means
+// the token is used until the server rejects it.
+type TokenRefreshFunc func(ctx context.Context) (token string, expiry time.Time, err error)
+
+// RefreshingTokenProvider provides session tokens that expire. Tokens are
+// refreshed before they expire, and concurrent refreshes are collapsed into
+// one. A Client using it refreshes the token and retries a request once if
+// the server responds with 401 Unauthorized.
+// @typescript-ignore RefreshingTokenProvider
+type RefreshingTokenProvider struct {
+	refresh TokenRefreshFunc
+	// RefreshBefore is how long before the token expires it is refreshed.
+	// It defaults to one minute.
+	RefreshBefore time.Duration
+	// SessionTokenHeader is an optional custom header to use for setting
+	// tokens. By default, 'Coder-Session-Token' is used.
+	SessionTokenHeader string
+	// Clock is used to determine when the token expires. It defaults to the
+	// real clock.
+	Clock quartz.Clock
+
+	group  singleflight.Group
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+var _ SessionTokenProvider = &RefreshingTokenProvider{}
+
+// NewRefreshingTokenProvider returns a provider that obtains tokens with
+// refresh. The first token is obtained when it is first needed.
+func NewRefreshingTokenProvider(refresh TokenRefreshFunc) *RefreshingTokenProvider {
+	return &RefreshingTokenProvider{refresh: refresh}
+}
+
+func (p *RefreshingTokenProvider) AsRequestOption() RequestOption {
+	return func(req *http.Request) {
+		req.Header.Set(p.header(), p.sessionToken(req.Context()))
+	}
+}
+
+func (p *RefreshingTokenProvider) SetDialOption(opts *websocket.DialOptions) {
+	if opts.HTTPHeader == nil {
+		opts.HTTPHeader = http.Header{}
+	}
+	if opts.HTTPHeader.Get(p.header()) == "" {
+		opts.HTTPHeader.Set(p.header(), p.GetSessionToken())
+	}
+}
+
+func (p *RefreshingTokenProvider) GetSessionToken() string {
+	return p.sessionToken(context.Background())
+}
+
+// RefreshToken obtains a new token, even if the current one has not expired.
+func (p *RefreshingTokenProvider) RefreshToken(ctx context.Context) error {
+	p.mu.Lock()
+	token := p.token
+	p.mu.Unlock()
+	_, err := p.refreshStale(ctx, token)
+	return err
+}
+
+// sessionToken returns the current token, refreshing it first if it is about
+// to expire. If refreshing fails, the current token is returned and the server
+// decides whether it is still valid.
+func (p *RefreshingTokenProvider) sessionToken(ctx context.Context) string {
+	p.mu.Lock()
+	token, expiry := p.token, p.expiry
+	p.mu.Unlock()
+	if token != "" && (expiry.IsZero() || p.clock().Until(expiry) > p.refreshBefore()) {
+		return token
+	}
+	refreshed, err := p.refreshStale(ctx, token)
+	if err != nil {
+		return token
+	}
+	return refreshed
+}
+
+// refreshStale replaces the stale token with a new one. If the token has
+// already been replaced, e.g. by a concurrent refresh, the current token is
+// returned instead.
+func (p *RefreshingTokenProvider) refreshStale(ctx context.Context, stale string) (string, error) {
+	token, err, _ := p.group.Do("refresh", func() (any, error) {
+		p.mu.Lock()
+		current := p.token
+		p.mu.Unlock()
+		if current != stale {
+			return current, nil
+		}
+
+		token, expiry, err := p.refresh(ctx)
+		if err != nil {
+			return "", xerrors.Errorf("refresh session token: %w", err)
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.token, p.expiry = token, expiry
+		return token, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	//nolint:forcetypeassert // Always a string.
+	return token.(string), nil
+}
+
+func (p *RefreshingTokenProvider) header() string {
+	if p.SessionTokenHeader == "" {
+		return SessionTokenHeader
+	}
+	return p.SessionTokenHeader
+}
+
+func (p *RefreshingTokenProvider) refreshBefore() time.Duration {
+	if p.RefreshBefore <= 0 {
+		return time.Minute
+	}
+	return p.RefreshBefore
+}
+
+func (p *RefreshingTokenProvider) clock() quartz.Clock {
+	if p.Clock == nil {
+		return quartz.NewReal()
+	}
+	return p.Clock
+}
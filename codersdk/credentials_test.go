@@ -0,0 +1,145 @@
+package codersdk_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/testutil"
+	"github.com/coder/quartz"
+)
+
+func TestRefreshingTokenProvider(t *testing.T) {
+	t.Parallel()
+
+	// counter returns a refresh func that issues token-1, token-2, ... valid
+	// for ttl, and the number of refreshes.
+	counter := func(clock quartz.Clock, ttl time.Duration) (codersdk.TokenRefreshFunc, *atomic.Int32) {
+		var n atomic.Int32
+		return func(context.Context) (string, time.Time, error) {
+			i := n.Add(1)
+			return fmt.Sprintf("token-%d", i), clock.Now().Add(ttl), nil
+		}, &n
+	}
+
+	t.Run("RefreshesBeforeExpiry", func(t *testing.T) {
+		t.Parallel()
+
+		clock := quartz.NewMock(t)
+		refresh, n := counter(clock, 10*time.Minute)
+		provider := codersdk.NewRefreshingTokenProvider(refresh)
+		provider.Clock = clock
+
+		require.Equal(t, "token-1", provider.GetSessionToken())
+		clock.Advance(8 * time.Minute)
+		require.Equal(t, "token-1", provider.GetSessionToken())
+		// Within a minute of expiry.
+		clock.Advance(90 * time.Second)
+		require.Equal(t, "token-2", provider.GetSessionToken())
+		require.EqualValues(t, 2, n.Load())
+	})
+
+	t.Run("SingleFlight", func(t *testing.T) {
+		t.Parallel()
+
+		release := make(chan struct{})
+		var n atomic.Int32
+		provider := codersdk.NewRefreshingTokenProvider(func(context.Context) (string, time.Time, error) {
+			n.Add(1)
+			<-release
+			return "token", time.Time{}, nil
+		})
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if token := provider.GetSessionToken(); token != "token" {
+					t.Errorf("unexpected token %q", token)
+				}
+			}()
+		}
+		// Give the goroutines a chance to pile up on the refresh.
+		time.Sleep(testutil.IntervalFast)
+		close(release)
+		wg.Wait()
+		require.EqualValues(t, 1, n.Load())
+	})
+
+	t.Run("RefreshFails", func(t *testing.T) {
+		t.Parallel()
+
+		clock := quartz.NewMock(t)
+		fail := false
+		provider := codersdk.NewRefreshingTokenProvider(func(context.Context) (string, time.Time, error) {
+			if fail {
+				return "", time.Time{}, xerrors.New("idp unavailable")
+			}
+			return "token", clock.Now().Add(time.Minute), nil
+		})
+		provider.Clock = clock
+
+		require.Equal(t, "token", provider.GetSessionToken())
+		fail = true
+		// The stale token is used rather than none at all.
+		require.Equal(t, "token", provider.GetSessionToken())
+		require.Error(t, provider.RefreshToken(context.Background()))
+	})
+
+	t.Run("RetriesUnauthorized", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			requests  atomic.Int32
+			rejectAll atomic.Bool
+		)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			if rejectAll.Load() || r.Header.Get(codersdk.SessionTokenHeader) != "token-2" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(srv.Close)
+		u, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+
+		// The tokens never expire, so only the 401 triggers a refresh.
+		refresh, n := counter(quartz.NewReal(), 0)
+		client := codersdk.New(u)
+		client.SetSessionTokenProvider(codersdk.NewRefreshingTokenProvider(func(ctx context.Context) (string, time.Time, error) {
+			token, _, err := refresh(ctx)
+			return token, time.Time{}, err
+		}))
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		res, err := client.Request(ctx, http.MethodPost, "/", map[string]string{"a": "b"})
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.EqualValues(t, 2, n.Load())
+		require.EqualValues(t, 2, requests.Load())
+
+		// The refreshed token is rejected too, so the 401 is returned
+		// after a single retry.
+		rejectAll.Store(true)
+		res, err = client.Request(ctx, http.MethodGet, "/", nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+		require.EqualValues(t, 3, n.Load())
+		require.EqualValues(t, 4, requests.Load())
+	})
+}
@@ -463,6 +463,7 @@ type DeploymentValues struct {
 	TLS                             TLSConfig                            `json:"tls,omitempty" typescript:",notnull"`
 	Trace                           TraceConfig                          `json:"trace,omitempty" typescript:",notnull"`
 	HTTPCookies                     HTTPCookieConfig                     `json:"http_cookies,omitempty" typescript:",notnull"`
+	WorkspaceAppCORS                WorkspaceAppCORSConfig               `json:"workspace_app_cors,omitempty" typescript:",notnull"`
 	StrictTransportSecurity         serpent.Int64                        `json:"strict_transport_security,omitempty" typescript:",notnull"`
 	StrictTransportSecurityOptions  serpent.StringArray                  `json:"strict_transport_security_options,omitempty" typescript:",notnull"`
 	SSHKeygenAlgorithm              serpent.String                       `json:"ssh_keygen_algorithm,omitempty" typescript:",notnull"`
@@ -705,6 +706,15 @@ type HTTPCookieConfig struct {
 	SameSite string       `json:"same_site,omitempty" typescript:",notnull"`
 }
 
+// WorkspaceAppCORSConfig configures the CORS headers set on workspace app
+// responses when the template's CORS behavior is simple or allowlist.
+type WorkspaceAppCORSConfig struct {
+	MaxAge         serpent.Duration    `json:"max_age,omitempty" typescript:",notnull"`
+	AllowedMethods serpent.StringArray `json:"allowed_methods,omitempty" typescript:",notnull"`
+	AllowedHeaders serpent.StringArray `json:"allowed_headers,omitempty" typescript:",notnull"`
+	ExposedHeaders serpent.StringArray `json:"exposed_headers,omitempty" typescript:",notnull"`
+}
+
 func (cfg *HTTPCookieConfig) Apply(c *http.Cookie) *http.Cookie {
 	c.Secure = cfg.Secure.Value()
 	c.SameSite = cfg.HTTPSameSite()
@@ -766,8 +776,13 @@ type ProvisionerConfig struct {
 }
 
 type RateLimitConfig struct {
-	DisableAll serpent.Bool  `json:"disable_all" typescript:",notnull"`
-	API        serpent.Int64 `json:"api" typescript:",notnull"`
+	DisableAll                 serpent.Bool  `json:"disable_all" typescript:",notnull"`
+	API                        serpent.Int64 `json:"api" typescript:",notnull"`
+	AITaskName                 serpent.Int64 `json:"ai_task_name" typescript:",notnull"`
+	AITaskNameDeployment       serpent.Int64 `json:"ai_task_name_deployment" typescript:",notnull"`
+	AITaskNameConcurrency      serpent.Int64 `json:"ai_task_name_concurrency" typescript:",notnull"`
+	AITaskNameTokens           serpent.Int64 `json:"ai_task_name_tokens" typescript:",notnull"`
+	AITaskNameDeploymentTokens serpent.Int64 `json:"ai_task_name_deployment_tokens" typescript:",notnull"`
 }
 
 type SwaggerConfig struct {
@@ -2319,6 +2334,51 @@ func (c *DeploymentValues) Options() serpent.OptionSet {
 			Hidden:      true,
 			Annotations: serpent.Annotations{}.Mark(annotationExternalProxies, "true"),
 		},
+		{
+			Name:        "AI Task Name Rate Limit",
+			Description: "Maximum number of AI task name generation requests per minute allowed per user. Negative values mean no rate limit.",
+			Flag:        "ai-task-name-rate-limit",
+			Env:         "CODER_AI_TASK_NAME_RATE_LIMIT",
+			Default:     "30",
+			Value:       &c.RateLimit.AITaskName,
+			Hidden:      true,
+		},
+		{
+			Name:        "AI Task Name Deployment Rate Limit",
+			Description: "Maximum number of AI task name generation requests per minute allowed across the deployment. Negative values mean no rate limit.",
+			Flag:        "ai-task-name-deployment-rate-limit",
+			Env:         "CODER_AI_TASK_NAME_DEPLOYMENT_RATE_LIMIT",
+			Default:     "600",
+			Value:       &c.RateLimit.AITaskNameDeployment,
+			Hidden:      true,
+		},
+		{
+			Name:        "AI Task Name Concurrency",
+			Description: "Maximum number of concurrent language model calls made to generate AI task names. Further calls wait for a free slot. Negative values mean no limit.",
+			Flag:        "ai-task-name-concurrency",
+			Env:         "CODER_AI_TASK_NAME_CONCURRENCY",
+			Default:     "16",
+			Value:       &c.RateLimit.AITaskNameConcurrency,
+			Hidden:      true,
+		},
+		{
+			Name:        "AI Task Name Token Rate Limit",
+			Description: "Maximum number of language model tokens per minute used to generate AI task names per user. Requests after the limit is reached are rejected until the minute is over. Negative values mean no limit.",
+			Flag:        "ai-task-name-token-rate-limit",
+			Env:         "CODER_AI_TASK_NAME_TOKEN_RATE_LIMIT",
+			Default:     "20000",
+			Value:       &c.RateLimit.AITaskNameTokens,
+			Hidden:      true,
+		},
+		{
+			Name:        "AI Task Name Deployment Token Rate Limit",
+			Description: "Maximum number of language model tokens per minute used to generate AI task names across the deployment. Requests after the limit is reached are rejected until the minute is over. Negative values mean no limit.",
+			Flag:        "ai-task-name-deployment-token-rate-limit",
+			Env:         "CODER_AI_TASK_NAME_DEPLOYMENT_TOKEN_RATE_LIMIT",
+			Default:     "400000",
+			Value:       &c.RateLimit.AITaskNameDeploymentTokens,
+			Hidden:      true,
+		},
 		// Logging settings
 		{
 			Name:          "Verbose",
@@ -2571,6 +2631,49 @@ func (c *DeploymentValues) Options() serpent.OptionSet {
 			YAML:        "sameSiteAuthCookie",
 			Annotations: serpent.Annotations{}.Mark(annotationExternalProxies, "true"),
 		},
+		{
+			Name:        "Workspace App CORS Max Age",
+			Description: "How long browsers may cache the result of a CORS preflight request to a workspace app. Set to 0 to omit the Access-Control-Max-Age header.",
+			Flag:        "workspace-app-cors-max-age",
+			Env:         "CODER_WORKSPACE_APP_CORS_MAX_AGE",
+			Default:     "0s",
+			Value:       &c.WorkspaceAppCORS.MaxAge,
+			Group:       &deploymentGroupNetworking,
+			YAML:        "workspaceAppCORSMaxAge",
+			Annotations: serpent.Annotations{}.Mark(annotationFormatDuration, "true").Mark(annotationExternalProxies, "true"),
+		},
+		{
+			Name:        "Workspace App CORS Allowed Methods",
+			Description: "HTTP methods allowed in cross-origin requests to workspace apps.",
+			Flag:        "workspace-app-cors-allowed-methods",
+			Env:         "CODER_WORKSPACE_APP_CORS_ALLOWED_METHODS",
+			Default:     strings.Join([]string{http.MethodHead, http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}, ","),
+			Value:       &c.WorkspaceAppCORS.AllowedMethods,
+			Group:       &deploymentGroupNetworking,
+			YAML:        "workspaceAppCORSAllowedMethods",
+			Annotations: serpent.Annotations{}.Mark(annotationExternalProxies, "true"),
+		},
+		{
+			Name:        "Workspace App CORS Allowed Headers",
+			Description: "Request headers allowed in cross-origin requests to workspace apps. Use '*' to allow any header.",
+			Flag:        "workspace-app-cors-allowed-headers",
+			Env:         "CODER_WORKSPACE_APP_CORS_ALLOWED_HEADERS",
+			Default:     "*",
+			Value:       &c.WorkspaceAppCORS.AllowedHeaders,
+			Group:       &deploymentGroupNetworking,
+			YAML:        "workspaceAppCORSAllowedHeaders",
+			Annotations: serpent.Annotations{}.Mark(annotationExternalProxies, "true"),
+		},
+		{
+			Name:        "Workspace App CORS Exposed Headers",
+			Description: "Response headers from workspace apps that browsers may expose to cross-origin scripts.",
+			Flag:        "workspace-app-cors-exposed-headers",
+			Env:         "CODER_WORKSPACE_APP_CORS_EXPOSED_HEADERS",
+			Value:       &c.WorkspaceAppCORS.ExposedHeaders,
+			Group:       &deploymentGroupNetworking,
+			YAML:        "workspaceAppCORSExposedHeaders",
+			Annotations: serpent.Annotations{}.Mark(annotationExternalProxies, "true"),
+		},
 		{
 			Name:        "Terms of Service URL",
 			Description: "A URL to an external Terms of Service that must be accepted by users when logging in.",
@@ -3229,6 +3332,137 @@ Write out the current server config as YAML to stdout.`,
 			YAML:        "hideAITasks",
 		},
 
+		{
+			Name:        "AI Task Name Cache TTL",
+			Description: "How long names generated for an AI task prompt are reused for repeated requests with the same prompt. Set to 0 to disable the cache.",
+			Flag:        "ai-task-name-cache-ttl",
+			Env:         "CODER_AI_TASK_NAME_CACHE_TTL",
+			Default:     "1h",
+			Value:       &c.AI.TaskNameCacheTTL,
+			Hidden:      true,
+			Annotations: serpent.Annotations{}.Mark(annotationFormatDuration, "true"),
+		},
+		{
+			Name:        "AI Task Name System Prompt File",
+			Description: "Path to a file containing the system prompt, including any examples, used to generate AI task names. Replaces the built-in prompt.",
+			Flag:        "ai-task-name-system-prompt-file",
+			Env:         "CODER_AI_TASK_NAME_SYSTEM_PROMPT_FILE",
+			Value:       &c.AI.TaskNameSystemPromptFile,
+			Hidden:      true,
+		},
+		{
+			Name:        "AI Task Name Instructions",
+			Description: "Additional instructions appended to the system prompt used to generate AI task names, e.g. to require a prefix or extract ticket IDs from the prompt.",
+			Flag:        "ai-task-name-instructions",
+			Env:         "CODER_AI_TASK_NAME_INSTRUCTIONS",
+			Value:       &c.AI.TaskNameInstructions,
+			Hidden:      true,
+		},
+		{
+			Name:        "AI Task Name Timeout",
+			Description: "Maximum time spent generating an AI task name, across all language model calls and retries. When exceeded, the naming endpoint responds with 504 and task creation falls back to a random name.",
+			Flag:        "ai-task-name-timeout",
+			Env:         "CODER_AI_TASK_NAME_TIMEOUT",
+			Default:     "30s",
+			Value:       &c.AI.TaskNameTimeout,
+			Hidden:      true,
+			Annotations: serpent.Annotations{}.Mark(annotationFormatDuration, "true"),
+		},
+		{
+			Name:        "AI Task Name Call Timeout",
+			Description: "Maximum duration of a single language model call, including streaming its response, when generating an AI task name. A call that times out moves on to the next configured model.",
+			Flag:        "ai-task-name-call-timeout",
+			Env:         "CODER_AI_TASK_NAME_CALL_TIMEOUT",
+			Default:     "10s",
+			Value:       &c.AI.TaskNameCallTimeout,
+			Hidden:      true,
+			Annotations: serpent.Annotations{}.Mark(annotationFormatDuration, "true"),
+		},
+		{
+			Name:        "AI Task Name Deny List",
+			Description: "Words that generated AI task names must not contain, such as profanity or internal project names. The language model is asked for another name when one is rejected.",
+			Flag:        "ai-task-name-deny-list",
+			Env:         "CODER_AI_TASK_NAME_DENY_LIST",
+			Value:       &c.AI.TaskNameDenyList,
+			Hidden:      true,
+		},
+		{
+			Name:        "AI Task Prompt Moderation",
+			Description: "What to do with AI task prompts that match the prompt deny list or attempt prompt injection before they are sent to the language model: off disables moderation, reject refuses to generate from the prompt, and sanitize removes the matching text and logs that it did.",
+			Flag:        "ai-task-prompt-moderation",
+			Env:         "CODER_AI_TASK_PROMPT_MODERATION",
+			Default:     "off",
+			Value:       &c.AI.TaskPromptModeration,
+			Hidden:      true,
+		},
+		{
+			Name:        "AI Task Prompt Deny List",
+			Description: "Case-insensitive regular expressions that AI task prompts are moderated against, in addition to built-in prompt injection patterns.",
+			Flag:        "ai-task-prompt-deny-list",
+			Env:         "CODER_AI_TASK_PROMPT_DENY_LIST",
+			Value:       &c.AI.TaskPromptDenyList,
+			Hidden:      true,
+		},
+		{
+			Name:        "AI Task Name Provider",
+			Description: "The language model provider used to generate AI task names: anthropic, bedrock or azure-openai.",
+			Flag:        "ai-task-name-provider",
+			Env:         "CODER_AI_TASK_NAME_PROVIDER",
+			Default:     "anthropic",
+			Value:       &c.AI.TaskNameProvider,
+			Hidden:      true,
+		},
+		{
+			Name:        "AI Task Name Provider Base URL",
+			Description: "Overrides the endpoint of the AI task name provider. Required for azure-openai, where it is the resource endpoint, e.g. https://my-resource.openai.azure.com.",
+			Flag:        "ai-task-name-provider-base-url",
+			Env:         "CODER_AI_TASK_NAME_PROVIDER_BASE_URL",
+			Value:       &c.AI.TaskNameProviderBaseURL,
+			Hidden:      true,
+		},
+		{
+			Name:        "AI Task Name Provider Key",
+			Description: "The API key of the AI task name provider. For anthropic, it defaults to the ANTHROPIC_API_KEY environment variable. Bedrock uses the default AWS credential chain instead.",
+			Flag:        "ai-task-name-provider-key",
+			Env:         "CODER_AI_TASK_NAME_PROVIDER_KEY",
+			Value:       &c.AI.TaskNameProviderKey,
+			Hidden:      true,
+			Annotations: serpent.Annotations{}.Mark(annotationSecretKey, "true"),
+		},
+		{
+			Name:        "AI Task Name Provider Region",
+			Description: "The AWS region used by the bedrock AI task name provider. Defaults to the region of the AWS configuration.",
+			Flag:        "ai-task-name-provider-region",
+			Env:         "CODER_AI_TASK_NAME_PROVIDER_REGION",
+			Value:       &c.AI.TaskNameProviderRegion,
+			Hidden:      true,
+		},
+		{
+			Name:        "AI Task Name Provider API Version",
+			Description: "The REST API version used by the azure-openai AI task name provider.",
+			Flag:        "ai-task-name-provider-api-version",
+			Env:         "CODER_AI_TASK_NAME_PROVIDER_API_VERSION",
+			Value:       &c.AI.TaskNameProviderVersion,
+			Hidden:      true,
+		},
+		{
+			Name:        "AI Task Name Model Aliases",
+			Description: "Maps model names used in CODER_AI_TASK_NAME_MODEL to provider-specific model IDs, as alias=model pairs. For azure-openai, models are deployment names.",
+			Flag:        "ai-task-name-model-aliases",
+			Env:         "CODER_AI_TASK_NAME_MODEL_ALIASES",
+			Value:       &c.AI.TaskNameModelAliases,
+			Hidden:      true,
+		},
+		{
+			Name:        "AI Audit Prompt Content",
+			Description: "Whether audit log entries for AI requests include the start of the prompt. When disabled, only a hash of the prompt is kept.",
+			Flag:        "ai-audit-prompt-content",
+			Env:         "CODER_AI_AUDIT_PROMPT_CONTENT",
+			Default:     "true",
+			Value:       &c.AI.AuditPromptContent,
+			Hidden:      true,
+		},
+
 		// AIBridge Options
 		{
 			Name:        "AIBridge Enabled",
@@ -3307,7 +3541,24 @@ type AIBridgeAnthropicConfig struct {
 }
 
 type AIConfig struct {
-	BridgeConfig AIBridgeConfig `json:"bridge,omitempty"`
+	BridgeConfig     AIBridgeConfig   `json:"bridge,omitempty"`
+	TaskNameCacheTTL serpent.Duration `json:"task_name_cache_ttl,omitempty"`
+	// TaskNameSystemPromptFile is read by the server on startup, so only its
+	// path is part of the deployment config.
+	TaskNameSystemPromptFile serpent.String      `json:"task_name_system_prompt_file,omitempty"`
+	TaskNameInstructions     serpent.String      `json:"task_name_instructions,omitempty"`
+	TaskNameTimeout          serpent.Duration    `json:"task_name_timeout,omitempty"`
+	TaskNameCallTimeout      serpent.Duration    `json:"task_name_call_timeout,omitempty"`
+	TaskNameDenyList         serpent.StringArray `json:"task_name_deny_list,omitempty"`
+	TaskPromptModeration     serpent.String      `json:"task_prompt_moderation,omitempty"`
+	TaskPromptDenyList       serpent.StringArray `json:"task_prompt_deny_list,omitempty"`
+	TaskNameProvider         serpent.String      `json:"task_name_provider,omitempty"`
+	TaskNameProviderBaseURL  serpent.String      `json:"task_name_provider_base_url,omitempty"`
+	TaskNameProviderKey      serpent.String      `json:"task_name_provider_key,omitempty"`
+	TaskNameProviderRegion   serpent.String      `json:"task_name_provider_region,omitempty"`
+	TaskNameProviderVersion  serpent.String      `json:"task_name_provider_api_version,omitempty"`
+	TaskNameModelAliases     serpent.StringArray `json:"task_name_model_aliases,omitempty"`
+	AuditPromptContent       serpent.Bool        `json:"audit_prompt_content,omitempty"`
 }
 
 type SupportConfig struct {
@@ -0,0 +1,55 @@
+package codersdk
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// DialContextFunc opens connections for a transport, like
+// http.Transport.DialContext.
+// @typescript-ignore DialContextFunc
+type DialContextFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// NewDialTransport returns a copy of http.DefaultTransport that opens
+// connections with dial, e.g. to connect through an SSH jump host. Set it as
+// the Transport of a client's HTTPClient before installing any middlewares
+// with Use. To combine it with a proxy, set the DialContext of a transport
+// returned by NewProxyTransport instead.
+func NewDialTransport(dial DialContextFunc) (*http.Transport, error) {
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, xerrors.Errorf("default transport is %T, not *http.Transport", http.DefaultTransport)
+	}
+	transport = transport.Clone()
+	transport.DialContext = dial
+	return transport, nil
+}
+
+// ResolverDialContext returns a DialContextFunc that resolves host names with
+// resolver, e.g. a resolver that queries the internal DNS server of a
+// split-horizon setup. It otherwise dials like http.DefaultTransport.
+func ResolverDialContext(resolver *net.Resolver) DialContextFunc {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Resolver:  resolver,
+	}
+	return dialer.DialContext
+}
+
+// HostsDialContext returns a DialContextFunc that connects to the addresses
+// in hosts instead of resolving their keys, like curl's --resolve option, and
+// dials every other address with dial. Keys and values are host:port
+// addresses, e.g. "coder.example.com:443": "10.0.0.10:443".
+func HostsDialContext(hosts map[string]string, dial DialContextFunc) DialContextFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if override, ok := hosts[addr]; ok {
+			addr = override
+		}
+		return dial(ctx, network, addr)
+	}
+}
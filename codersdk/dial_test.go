@@ -0,0 +1,49 @@
+package codersdk_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestDialTransport(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+	srvURL, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	// coder.internal only resolves to the server through the override.
+	var dialed []string
+	transport, err := codersdk.NewDialTransport(codersdk.HostsDialContext(
+		map[string]string{"coder.internal:80": srvURL.Host},
+		func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dialed = append(dialed, addr)
+			return codersdk.ResolverDialContext(net.DefaultResolver)(ctx, network, addr)
+		},
+	))
+	require.NoError(t, err)
+	transport.Proxy = nil
+	t.Cleanup(transport.CloseIdleConnections)
+
+	client := codersdk.New(&url.URL{Scheme: "http", Host: "coder.internal"})
+	client.HTTPClient.Transport = transport
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	res, err := client.Request(ctx, http.MethodGet, "/", nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, []string{srvURL.Host}, dialed)
+}
@@ -0,0 +1,131 @@
+package codersdk
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RequestMetrics describes a request made by a client, including any retries.
+// @typescript-ignore RequestMetrics
+type RequestMetrics struct {
+	Method string
+	// Route approximates the route template of the request, e.g.
+	// /api/v2/workspaces/{workspace}.
+	Route string
+	// StatusCode is the status of the final response, or 0 if the request
+	// failed without one.
+	StatusCode int
+	Latency    time.Duration
+	// Retries is the number of times the request was retried by a
+	// RetryTransport installed after the Metrics middleware.
+	Retries int
+	Err     error
+}
+
+// MetricsReporter records the requests made by a client.
+// @typescript-ignore MetricsReporter
+type MetricsReporter interface {
+	ReportRequest(ctx context.Context, metrics RequestMetrics)
+}
+
+type retryCountKey struct{}
+
+// Metrics returns a middleware that reports every request to reporter once it
+// completes. Install it before Retry in the same call to Use, or in an
+// earlier call, so that retries are counted as part of a single request.
+func Metrics(reporter MetricsReporter) RequestMiddleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			// Share the count with any enclosing Metrics middleware, so that
+			// every reporter sees the retries.
+			retries, ok := req.Context().Value(retryCountKey{}).(*atomic.Int32)
+			if !ok {
+				retries = &atomic.Int32{}
+				req = req.WithContext(context.WithValue(req.Context(), retryCountKey{}, retries))
+			}
+			route, _ := traceRoute(req.URL.Path)
+
+			start := time.Now()
+			res, err := next.RoundTrip(req)
+			metrics := RequestMetrics{
+				Method:  req.Method,
+				Route:   route,
+				Latency: time.Since(start),
+				Retries: int(retries.Load()),
+				Err:     err,
+			}
+			if res != nil {
+				metrics.StatusCode = res.StatusCode
+			}
+			reporter.ReportRequest(req.Context(), metrics)
+			return res, err
+		})
+	}
+}
+
+// countRetry records a retry of the request for the Metrics middleware.
+func countRetry(ctx context.Context) {
+	if retries, ok := ctx.Value(retryCountKey{}).(*atomic.Int32); ok {
+		retries.Add(1)
+	}
+}
+
+// PrometheusMetricsReporter is a MetricsReporter that records requests as
+// Prometheus metrics, labeled by method, route and status class.
+// @typescript-ignore PrometheusMetricsReporter
+type PrometheusMetricsReporter struct {
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	retries  *prometheus.CounterVec
+}
+
+var _ MetricsReporter = &PrometheusMetricsReporter{}
+
+// NewPrometheusMetricsReporter registers the client metrics with reg.
+func NewPrometheusMetricsReporter(reg prometheus.Registerer) (*PrometheusMetricsReporter, error) {
+	labels := []string{"method", "route", "status"}
+	r := &PrometheusMetricsReporter{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "codersdk",
+			Subsystem: "client",
+			Name:      "requests_total",
+			Help:      "The total number of requests made to the Coder API, by status class.",
+		}, labels),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "codersdk",
+			Subsystem: "client",
+			Name:      "request_latency_seconds",
+			Help:      "The latency of requests made to the Coder API, including retries.",
+			Buckets:   prometheus.DefBuckets,
+		}, labels),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "codersdk",
+			Subsystem: "client",
+			Name:      "request_retries_total",
+			Help:      "The total number of times requests to the Coder API were retried.",
+		}, labels),
+	}
+	for _, c := range []prometheus.Collector{r.requests, r.latency, r.retries} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (r *PrometheusMetricsReporter) ReportRequest(_ context.Context, metrics RequestMetrics) {
+	status := "error"
+	if metrics.StatusCode > 0 {
+		status = strconv.Itoa(metrics.StatusCode/100) + "xx"
+	}
+	r.requests.WithLabelValues(metrics.Method, metrics.Route, status).Inc()
+	r.latency.WithLabelValues(metrics.Method, metrics.Route, status).Observe(metrics.Latency.Seconds())
+	if metrics.Retries > 0 {
+		r.retries.WithLabelValues(metrics.Method, metrics.Route, status).Add(float64(metrics.Retries))
+	}
+}
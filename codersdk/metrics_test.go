@@ -0,0 +1,80 @@
+package codersdk_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/testutil"
+)
+
+type fakeMetricsReporter struct {
+	mu      sync.Mutex
+	metrics []codersdk.RequestMetrics
+}
+
+func (r *fakeMetricsReporter) ReportRequest(_ context.Context, metrics codersdk.RequestMetrics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, metrics)
+}
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	reporter := &fakeMetricsReporter{}
+	reg := prometheus.NewRegistry()
+	promReporter, err := codersdk.NewPrometheusMetricsReporter(reg)
+	require.NoError(t, err)
+
+	client := codersdk.New(u)
+	client.Use(
+		codersdk.Metrics(reporter),
+		codersdk.Metrics(promReporter),
+		codersdk.Retry(codersdk.RetryPolicy{InitialBackoff: time.Millisecond}),
+	)
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	workspaceID := uuid.New()
+	res, err := client.Request(ctx, http.MethodGet, "/api/v2/workspaces/"+workspaceID.String(), nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	route := "/api/v2/workspaces/{workspace}"
+	require.Len(t, reporter.metrics, 1)
+	metrics := reporter.metrics[0]
+	require.Equal(t, http.MethodGet, metrics.Method)
+	require.Equal(t, route, metrics.Route)
+	require.Equal(t, http.StatusOK, metrics.StatusCode)
+	require.Equal(t, 1, metrics.Retries)
+	require.Positive(t, metrics.Latency)
+	require.NoError(t, metrics.Err)
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	require.True(t, testutil.PromCounterHasValue(t, families, 1, "codersdk_client_requests_total", http.MethodGet, route, "2xx"))
+	require.True(t, testutil.PromCounterHasValue(t, families, 1, "codersdk_client_request_retries_total", http.MethodGet, route, "2xx"))
+}
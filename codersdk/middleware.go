@@ -0,0 +1,115 @@
+package codersdk
+
+import (
+	"net/http"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/v2/buildinfo"
+)
+
+// RequestMiddleware wraps the transport a Client sends requests with. It can
+// inspect or modify requests and responses, e.g. to inject headers or record
+// metrics, or respond without calling next at all.
+// @typescript-ignore RequestMiddleware
+type RequestMiddleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to a http.RoundTripper.
+// @typescript-ignore RoundTripperFunc
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Use installs middlewares on the transport of the client's HTTPClient. The
+// first middleware is the outermost, so it sees requests first and responses
+// last. Middlewares installed by a later call wrap those installed earlier.
+//
+// Replacing HTTPClient, or its Transport, after calling Use removes the
+// middlewares.
+func (c *Client) Use(mws ...RequestMiddleware) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.HTTPClient == nil {
+		c.HTTPClient = &http.Client{}
+	}
+	transport := c.HTTPClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	for i := len(mws) - 1; i >= 0; i-- {
+		transport = mws[i](transport)
+	}
+	c.HTTPClient.Transport = transport
+}
+
+// Retry returns a middleware that retries requests according to policy. See
+// RetryTransport.
+func Retry(policy RetryPolicy) RequestMiddleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &RetryTransport{Transport: next, Policy: policy}
+	}
+}
+
+// Headers returns a middleware that adds header to every request, e.g. to
+// attribute traffic through a corporate gateway. See HeaderTransport.
+func Headers(header http.Header) RequestMiddleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &HeaderTransport{Transport: next, Header: header}
+	}
+}
+
+// UserAgent returns a middleware that appends suffix, such as "my-tool/1.2",
+// to the User-Agent of every request. Requests without a User-Agent are sent
+// as codersdk with the version of this module.
+func UserAgent(suffix string) RequestMiddleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			userAgent := req.Header.Get("User-Agent")
+			if userAgent == "" {
+				userAgent = "codersdk/" + buildinfo.Version()
+			}
+			req = req.Clone(req.Context())
+			req.Header.Set("User-Agent", strings.TrimSpace(userAgent+" "+suffix))
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// RequestSigner signs requests, e.g. for gateways in front of coderd that
+// require an AWS SigV4 or HMAC signature header on every request.
+// @typescript-ignore RequestSigner
+type RequestSigner interface {
+	// SignRequest adds a signature to req, which it may modify. The body can
+	// be read with req.GetBody, if it is set.
+	SignRequest(req *http.Request) error
+}
+
+// RequestSignerFunc adapts a function to a RequestSigner.
+// @typescript-ignore RequestSignerFunc
+type RequestSignerFunc func(req *http.Request) error
+
+func (f RequestSignerFunc) SignRequest(req *http.Request) error {
+	return f(req)
+}
+
+// Sign returns a middleware that signs every request with signer. Requests
+// are signed once the session token and any other request options have been
+// applied. Install it after Retry, so that every attempt is signed anew.
+func Sign(signer RequestSigner) RequestMiddleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			if err := signer.SignRequest(req); err != nil {
+				if req.Body != nil {
+					_ = req.Body.Close()
+				}
+				return nil, xerrors.Errorf("sign request: %w", err)
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package codersdk_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestClientUse(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("X-Order")))
+	}))
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	// tag appends name to a request header on the way in, and to a response
+	// header on the way out, to record the order middlewares run in.
+	tag := func(name string) codersdk.RequestMiddleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return codersdk.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				req.Header.Set("X-Order", strings.TrimPrefix(req.Header.Get("X-Order")+","+name, ","))
+				res, err := next.RoundTrip(req)
+				if err != nil {
+					return nil, err
+				}
+				res.Header.Set("X-Order", strings.TrimPrefix(res.Header.Get("X-Order")+","+name, ","))
+				return res, nil
+			})
+		}
+	}
+
+	t.Run("Order", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		client := codersdk.New(u)
+		client.Use(tag("a"), tag("b"))
+		client.Use(tag("c"))
+
+		res, err := client.Request(ctx, http.MethodGet, "/", nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Equal(t, "c,a,b", string(body))
+		require.Equal(t, "b,a,c", res.Header.Get("X-Order"))
+	})
+
+	t.Run("ShortCircuit", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		client := codersdk.New(u)
+		client.Use(func(http.RoundTripper) http.RoundTripper {
+			return codersdk.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusTeapot,
+					Header:     http.Header{},
+					Body:       http.NoBody,
+					Request:    req,
+				}, nil
+			})
+		})
+
+		res, err := client.Request(ctx, http.MethodGet, "/", nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusTeapot, res.StatusCode)
+	})
+}
+
+func TestHeaders(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("User-Agent") + "|" + r.Header.Get("X-Gateway-Team")))
+	}))
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	client := codersdk.New(u)
+	client.Use(
+		codersdk.Headers(http.Header{"X-Gateway-Team": {"platform"}}),
+		codersdk.UserAgent("my-tool/1.2"),
+	)
+
+	res, err := client.Request(ctx, http.MethodGet, "/", nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.Regexp(t, `^codersdk/\S+ my-tool/1\.2\|platform$`, string(body))
+
+	res, err = client.Request(ctx, http.MethodGet, "/", nil, func(r *http.Request) {
+		r.Header.Set("User-Agent", "coder-cli/2.0")
+	})
+	require.NoError(t, err)
+	defer res.Body.Close()
+	body, err = io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.Equal(t, "coder-cli/2.0 my-tool/1.2|platform", string(body))
+}
+
+func TestSign(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("gateway-key")
+	sign := func(token string, body []byte) string {
+		mac := hmac.New(sha256.New, key)
+		_, _ = mac.Write([]byte(token))
+		_, _ = mac.Write(body)
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if !assert.NoError(t, err) {
+			return
+		}
+		if r.Header.Get("X-Signature") != sign(r.Header.Get(codersdk.SessionTokenHeader), body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	client := codersdk.New(u)
+	client.SetSessionToken("token")
+	client.Use(codersdk.Sign(codersdk.RequestSignerFunc(func(req *http.Request) error {
+		var body []byte
+		if req.GetBody != nil {
+			r, err := req.GetBody()
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+			body, err = io.ReadAll(r)
+			if err != nil {
+				return err
+			}
+		}
+		req.Header.Set("X-Signature", sign(req.Header.Get(codersdk.SessionTokenHeader), body))
+		return nil
+	})))
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	res, err := client.Request(ctx, http.MethodPost, "/", map[string]string{"name": "ci"})
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	failing := codersdk.New(u)
+	failing.Use(codersdk.Sign(codersdk.RequestSignerFunc(func(*http.Request) error {
+		return xerrors.New("no credentials")
+	})))
+	_, err = failing.Request(ctx, http.MethodGet, "/", nil)
+	require.ErrorContains(t, err, "no credentials")
+}
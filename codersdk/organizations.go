@@ -209,6 +209,17 @@ type CreateTemplateRequest struct {
 
 	// CORSBehavior allows optionally specifying the CORS behavior for all shared ports.
 	CORSBehavior *CORSBehavior `json:"cors_behavior"`
+
+	// CORSAllowedOrigins are the origins allowed to make cross-origin requests
+	// to apps when CORSBehavior is allowlist. Origins are exact, e.g.
+	// https://app.example.com, or match any subdomain, e.g.
+	// https://*.example.com.
+	CORSAllowedOrigins []string `json:"cors_allowed_origins,omitempty"`
+
+	// CORSAllowCredentials allows credentialed cross-origin requests to apps
+	// from the allowlisted origins. It requires the allowlist CORS behavior
+	// and can't be combined with wildcard origins.
+	CORSAllowCredentials bool `json:"cors_allow_credentials,omitempty"`
 }
 
 // CreateWorkspaceRequest provides options for creating a new workspace.
@@ -0,0 +1,94 @@
+package codersdk
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/xerrors"
+)
+
+// ProxyConfig configures the proxy a client's requests are sent through.
+// @typescript-ignore ProxyConfig
+type ProxyConfig struct {
+	// URL is the proxy URL. The http, https, socks5 and socks5h schemes are
+	// supported. Requests are sent directly if it is empty.
+	URL string
+	// NoProxy lists the hosts that are not proxied, with the syntax of the
+	// NO_PROXY environment variable: host names, which also match their
+	// subdomains, domains with a leading dot, IP addresses and CIDR ranges,
+	// each optionally with a port. "*" disables the proxy.
+	NoProxy []string
+}
+
+// ProxyFromEnvironment returns the proxy configured by the HTTPS_PROXY,
+// HTTP_PROXY and ALL_PROXY environment variables, in that order of
+// preference, and NO_PROXY. The lowercase variants are also accepted.
+func ProxyFromEnvironment() ProxyConfig {
+	cfg := httpproxy.FromEnvironment()
+	proxyURL := cfg.HTTPSProxy
+	if proxyURL == "" {
+		proxyURL = cfg.HTTPProxy
+	}
+	if proxyURL == "" {
+		proxyURL = getEnvAny("ALL_PROXY", "all_proxy")
+	}
+	var noProxy []string
+	for _, host := range strings.Split(cfg.NoProxy, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			noProxy = append(noProxy, host)
+		}
+	}
+	return ProxyConfig{URL: proxyURL, NoProxy: noProxy}
+}
+
+// NewProxyTransport returns a copy of http.DefaultTransport that sends
+// requests through the configured proxy. Set it as the Transport of a
+// client's HTTPClient before installing any middlewares with Use.
+//
+// Requests to localhost and loopback addresses are never proxied.
+func NewProxyTransport(cfg ProxyConfig) (*http.Transport, error) {
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, xerrors.Errorf("default transport is %T, not *http.Transport", http.DefaultTransport)
+	}
+	transport = transport.Clone()
+	if cfg.URL == "" {
+		transport.Proxy = nil
+		return transport, nil
+	}
+
+	proxyURL, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, xerrors.Errorf("parse proxy url: %w", err)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, xerrors.Errorf("unsupported proxy scheme %q", proxyURL.Scheme)
+	}
+	if proxyURL.Host == "" {
+		return nil, xerrors.Errorf("proxy url %q has no host", cfg.URL)
+	}
+
+	proxyFunc := (&httpproxy.Config{
+		HTTPProxy:  proxyURL.String(),
+		HTTPSProxy: proxyURL.String(),
+		NoProxy:    strings.Join(cfg.NoProxy, ","),
+	}).ProxyFunc()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+	return transport, nil
+}
+
+func getEnvAny(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
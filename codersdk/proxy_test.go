@@ -0,0 +1,103 @@
+package codersdk_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestNewProxyTransport(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Proxied", func(t *testing.T) {
+		t.Parallel()
+
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Proxied requests carry the absolute URL.
+			_, _ = w.Write([]byte(r.URL.String()))
+		}))
+		t.Cleanup(proxy.Close)
+
+		transport, err := codersdk.NewProxyTransport(codersdk.ProxyConfig{URL: proxy.URL})
+		require.NoError(t, err)
+
+		u, err := url.Parse("http://coder.example.com")
+		require.NoError(t, err)
+		client := codersdk.New(u)
+		client.HTTPClient.Transport = transport
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		res, err := client.Request(ctx, http.MethodGet, "/api/v2/buildinfo", nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Equal(t, "http://coder.example.com/api/v2/buildinfo", string(body))
+	})
+
+	t.Run("NoProxy", func(t *testing.T) {
+		t.Parallel()
+
+		transport, err := codersdk.NewProxyTransport(codersdk.ProxyConfig{
+			URL:     "socks5://proxy.example.com:1080",
+			NoProxy: []string{"internal.example.com", "10.0.0.0/8"},
+		})
+		require.NoError(t, err)
+
+		for target, want := range map[string]string{
+			"https://coder.example.com":        "socks5://proxy.example.com:1080",
+			"https://internal.example.com":     "",
+			"https://git.internal.example.com": "",
+			"http://10.1.2.3:3000":             "",
+		} {
+			req, err := http.NewRequest(http.MethodGet, target, nil)
+			require.NoError(t, err)
+			proxyURL, err := transport.Proxy(req)
+			require.NoError(t, err)
+			if want == "" {
+				require.Nil(t, proxyURL, target)
+				continue
+			}
+			require.Equal(t, want, proxyURL.String(), target)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := codersdk.NewProxyTransport(codersdk.ProxyConfig{URL: "ftp://proxy.example.com"})
+		require.Error(t, err)
+		_, err = codersdk.NewProxyTransport(codersdk.ProxyConfig{URL: "http://"})
+		require.Error(t, err)
+
+		transport, err := codersdk.NewProxyTransport(codersdk.ProxyConfig{})
+		require.NoError(t, err)
+		require.Nil(t, transport.Proxy)
+	})
+}
+
+//nolint:paralleltest // Sets environment variables.
+func TestProxyFromEnvironment(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "")
+	t.Setenv("https_proxy", "")
+	t.Setenv("HTTP_PROXY", "")
+	t.Setenv("http_proxy", "")
+	t.Setenv("ALL_PROXY", "socks5h://proxy.example.com:1080")
+	t.Setenv("NO_PROXY", "internal.example.com, .corp")
+	t.Setenv("no_proxy", "")
+
+	require.Equal(t, codersdk.ProxyConfig{
+		URL:     "socks5h://proxy.example.com:1080",
+		NoProxy: []string{"internal.example.com", ".corp"},
+	}, codersdk.ProxyFromEnvironment())
+
+	t.Setenv("HTTP_PROXY", "http://proxy.example.com:3128")
+	require.Equal(t, "http://proxy.example.com:3128", codersdk.ProxyFromEnvironment().URL)
+}
@@ -0,0 +1,150 @@
+package codersdk
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/coder/quartz"
+)
+
+// RateLimit is the rate limit coderd reported for a request, in the
+// X-RateLimit-* response headers.
+// @typescript-ignore RateLimit
+type RateLimit struct {
+	// Limit is the number of requests allowed per window.
+	Limit int
+	// Remaining is the number of requests left in the current window.
+	Remaining int
+	// Reset is when the current window ends.
+	Reset time.Time
+}
+
+// ParseRateLimit parses the rate limit headers of a response. It returns
+// false if the response has none, e.g. because rate limiting is disabled.
+func ParseRateLimit(header http.Header) (RateLimit, bool) {
+	limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+	reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return RateLimit{}, false
+	}
+	return RateLimit{
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     time.Unix(reset, 0),
+	}, true
+}
+
+// RateLimitOptions configures the middleware installed by RateLimits.
+// @typescript-ignore RateLimitOptions
+type RateLimitOptions struct {
+	// OnRateLimit is called with the rate limit reported for every response
+	// that has one, e.g. to export the remaining quota as a metric.
+	OnRateLimit func(req *http.Request, limit RateLimit)
+	// Throttle delays requests that would exceed the rate limit until it
+	// resets, instead of sending them to be rejected with 429 Too Many
+	// Requests. The remaining quota is tracked per path, as coderd limits
+	// requests per endpoint, and synced from every response.
+	Throttle bool
+	// Clock defaults to the real clock.
+	Clock quartz.Clock
+}
+
+// RateLimits returns a middleware that reports the rate limits coderd sends
+// with its responses, and optionally throttles requests to stay within them.
+// Install it after Retry, so that retries are throttled too.
+func RateLimits(opts RateLimitOptions) RequestMiddleware {
+	if opts.Clock == nil {
+		opts.Clock = quartz.NewReal()
+	}
+	limiter := &rateLimiter{
+		clock:  opts.Clock,
+		limits: make(map[string]RateLimit),
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if opts.Throttle {
+				if err := limiter.wait(req.Context(), req.URL.Path); err != nil {
+					return nil, err
+				}
+			}
+			res, err := next.RoundTrip(req)
+			if err != nil {
+				return nil, err
+			}
+			limit, ok := ParseRateLimit(res.Header)
+			if !ok {
+				return res, nil
+			}
+			if opts.Throttle {
+				limiter.sync(req.URL.Path, limit)
+			}
+			if opts.OnRateLimit != nil {
+				opts.OnRateLimit(req, limit)
+			}
+			return res, nil
+		})
+	}
+}
+
+// rateLimiter is a token bucket per path, which is refilled when the rate
+// limit window resets and synced with the remaining quota reported by coderd.
+type rateLimiter struct {
+	clock quartz.Clock
+
+	mu     sync.Mutex
+	limits map[string]RateLimit
+}
+
+// wait takes a token for a request to path, waiting for the window to reset
+// if there are none left.
+func (l *rateLimiter) wait(ctx context.Context, path string) error {
+	for {
+		l.mu.Lock()
+		limit, ok := l.limits[path]
+		if ok && !l.clock.Now().Before(limit.Reset) {
+			delete(l.limits, path)
+			ok = false
+		}
+		if !ok || limit.Remaining > 0 {
+			if ok {
+				limit.Remaining--
+				l.limits[path] = limit
+			}
+			l.mu.Unlock()
+			return nil
+		}
+		delay := l.clock.Until(limit.Reset)
+		l.mu.Unlock()
+
+		timer := l.clock.NewTimer(delay, "codersdk", "ratelimit")
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (l *rateLimiter) sync(path string, limit RateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// Forget expired limits, so that paths with IDs in them don't pile up.
+	now := l.clock.Now()
+	for p, limit := range l.limits {
+		if !now.Before(limit.Reset) {
+			delete(l.limits, p)
+		}
+	}
+	l.limits[path] = limit
+}
@@ -0,0 +1,114 @@
+package codersdk_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/testutil"
+	"github.com/coder/quartz"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	t.Parallel()
+
+	limit, ok := codersdk.ParseRateLimit(http.Header{
+		"X-Ratelimit-Limit":     {"512"},
+		"X-Ratelimit-Remaining": {"10"},
+		"X-Ratelimit-Reset":     {"1735689600"},
+	})
+	require.True(t, ok)
+	require.Equal(t, codersdk.RateLimit{
+		Limit:     512,
+		Remaining: 10,
+		Reset:     time.Unix(1735689600, 0),
+	}, limit)
+
+	_, ok = codersdk.ParseRateLimit(http.Header{})
+	require.False(t, ok)
+}
+
+func TestRateLimits(t *testing.T) {
+	t.Parallel()
+
+	clock := quartz.NewMock(t)
+	clock.Set(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	reset := clock.Now().Add(time.Minute)
+
+	// The server allows two requests per window.
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		remaining := 2 - int(calls.Add(1))
+		w.Header().Set("X-Ratelimit-Limit", "2")
+		w.Header().Set("X-Ratelimit-Remaining", strconv.Itoa(max(remaining, 0)))
+		w.Header().Set("X-Ratelimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		if remaining < 0 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	var (
+		mu     sync.Mutex
+		limits []codersdk.RateLimit
+	)
+	client := codersdk.New(u)
+	client.Use(codersdk.RateLimits(codersdk.RateLimitOptions{
+		OnRateLimit: func(_ *http.Request, limit codersdk.RateLimit) {
+			mu.Lock()
+			defer mu.Unlock()
+			limits = append(limits, limit)
+		},
+		Throttle: true,
+		Clock:    clock,
+	}))
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	get := func() int {
+		res, err := client.Request(ctx, http.MethodGet, "/api/v2/workspaces", nil)
+		if !assert.NoError(t, err) {
+			return 0
+		}
+		defer res.Body.Close()
+		return res.StatusCode
+	}
+
+	require.Equal(t, http.StatusOK, get())
+	require.Equal(t, http.StatusOK, get())
+	mu.Lock()
+	require.Len(t, limits, 2)
+	require.Equal(t, 0, limits[1].Remaining)
+	mu.Unlock()
+
+	// The quota is used up, so the next request waits for the window to
+	// reset instead of being rejected.
+	trap := clock.Trap().NewTimer("codersdk", "ratelimit")
+	defer trap.Close()
+	status := make(chan int, 1)
+	go func() {
+		status <- get()
+	}()
+	call := trap.MustWait(ctx)
+	require.Equal(t, time.Minute, call.Duration)
+	require.EqualValues(t, 2, calls.Load())
+	call.MustRelease(ctx)
+
+	// The server resets its window too.
+	calls.Store(0)
+	reset = reset.Add(time.Minute)
+	clock.Advance(time.Minute).MustWait(ctx)
+	require.Equal(t, http.StatusOK, testutil.RequireReceive(ctx, t, status))
+}
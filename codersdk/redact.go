@@ -0,0 +1,125 @@
+package codersdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DefaultRedactedFields are the JSON fields whose values are redacted from
+// logged bodies unless BodyLogOptions.RedactFields is set.
+var DefaultRedactedFields = []string{
+	"access_token",
+	"api_key",
+	"client_secret",
+	"id_token",
+	"key",
+	"password",
+	"private_key",
+	"refresh_token",
+	"secret",
+	"session_token",
+	"token",
+}
+
+// DefaultMaxLoggedBodyBytes is the size logged bodies are truncated to unless
+// BodyLogOptions.MaxBytes is set.
+const DefaultMaxLoggedBodyBytes = 16 << 10
+
+const redactedValue = "[REDACTED]"
+
+// BodyLogOptions configures how request and response bodies are logged when
+// LogBodies is enabled.
+// @typescript-ignore BodyLogOptions
+type BodyLogOptions struct {
+	// RedactFields lists the names of JSON object fields, at any depth, whose
+	// values are replaced before bodies are logged. Names are matched case
+	// insensitively. It defaults to DefaultRedactedFields. Set it to an empty
+	// slice to log bodies as they are.
+	RedactFields []string
+	// MaxBytes is the maximum number of bytes logged per body, after
+	// redaction. It defaults to DefaultMaxLoggedBodyBytes. A negative value
+	// disables the limit.
+	MaxBytes int
+}
+
+// BodyLogOptions returns how request and response bodies are logged.
+func (c *Client) BodyLogOptions() BodyLogOptions {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.bodyLogOptions
+}
+
+// SetBodyLogOptions sets how request and response bodies are logged.
+func (c *Client) SetBodyLogOptions(opts BodyLogOptions) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bodyLogOptions = opts
+}
+
+// loggableBody redacts and truncates a body according to opts. Bodies that
+// are not JSON are only truncated.
+func loggableBody(body []byte, opts BodyLogOptions) []byte {
+	fields := opts.RedactFields
+	if fields == nil {
+		fields = DefaultRedactedFields
+	}
+	if len(fields) > 0 && len(body) > 0 {
+		body = redactJSON(body, fields)
+	}
+
+	maxBytes := opts.MaxBytes
+	if maxBytes == 0 {
+		maxBytes = DefaultMaxLoggedBodyBytes
+	}
+	if maxBytes > 0 && len(body) > maxBytes {
+		truncated := len(body) - maxBytes
+		body = append(body[:maxBytes:maxBytes], fmt.Sprintf("... (%d bytes truncated)", truncated)...)
+	}
+	return body
+}
+
+// redactJSON replaces the values of the given fields in a JSON body. The body
+// is returned as is if it is not JSON or contains none of the fields.
+func redactJSON(body []byte, fields []string) []byte {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return body
+	}
+	if !redactValue(v, fields) {
+		return body
+	}
+	redacted, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+func redactValue(v any, fields []string) bool {
+	redacted := false
+	switch v := v.(type) {
+	case map[string]any:
+		for k, field := range v {
+			if slices.ContainsFunc(fields, func(f string) bool { return strings.EqualFold(f, k) }) {
+				v[k] = redactedValue
+				redacted = true
+				continue
+			}
+			if redactValue(field, fields) {
+				redacted = true
+			}
+		}
+	case []any:
+		for _, elem := range v {
+			if redactValue(elem, fields) {
+				redacted = true
+			}
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,107 @@
+package codersdk
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/sloghuman"
+
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestLoggableBody(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name   string
+		body   string
+		opts   BodyLogOptions
+		expect string
+	}{
+		{
+			name:   "Defaults",
+			body:   `{"name": "ci", "key": "abc", "nested": [{"Access_Token": "def", "id": 1}]}`,
+			expect: `{"key":"[REDACTED]","name":"ci","nested":[{"Access_Token":"[REDACTED]","id":1}]}`,
+		},
+		{
+			name:   "Untouched",
+			body:   `{"msg": "no secrets"}`,
+			expect: `{"msg": "no secrets"}`,
+		},
+		{
+			name:   "NotJSON",
+			body:   "token=abc",
+			expect: "token=abc",
+		},
+		{
+			name:   "CustomFields",
+			body:   `{"key": "abc", "webhook_url": "https://example.com/secret"}`,
+			opts:   BodyLogOptions{RedactFields: []string{"webhook_url"}},
+			expect: `{"key":"abc","webhook_url":"[REDACTED]"}`,
+		},
+		{
+			name:   "Disabled",
+			body:   `{"key": "abc"}`,
+			opts:   BodyLogOptions{RedactFields: []string{}},
+			expect: `{"key": "abc"}`,
+		},
+		{
+			name:   "Truncated",
+			body:   `{"key": "abc"}`,
+			opts:   BodyLogOptions{MaxBytes: 10},
+			expect: `{"key":"[R... (10 bytes truncated)`,
+		},
+		{
+			name:   "Unlimited",
+			body:   strings.Repeat("a", DefaultMaxLoggedBodyBytes+1),
+			opts:   BodyLogOptions{MaxBytes: -1},
+			expect: strings.Repeat("a", DefaultMaxLoggedBodyBytes+1),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.expect, string(loggableBody([]byte(tc.body), tc.opts)))
+		})
+	}
+}
+
+func TestClientRedactsLoggedBodies(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", jsonCT)
+		_, _ = io.WriteString(w, `{"key": "response-secret"}`)
+	}))
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	logBuf := bytes.NewBuffer(nil)
+	plainBuf := bytes.NewBuffer(nil)
+	client := New(u)
+	client.SetLogger(slog.Make(sloghuman.Sink(logBuf)).Leveled(slog.LevelDebug))
+	client.SetLogBodies(true)
+	client.PlainLogger = plainBuf
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	res, err := client.Request(ctx, http.MethodPost, "/", map[string]string{"password": "request-secret"})
+	require.NoError(t, err)
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.Equal(t, `{"key": "response-secret"}`, string(body))
+
+	for _, logs := range []string{logBuf.String(), plainBuf.String()} {
+		require.NotContains(t, logs, "request-secret")
+		require.NotContains(t, logs, "response-secret")
+		require.Contains(t, logs, redactedValue)
+	}
+}
@@ -0,0 +1,167 @@
+package codersdk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures how RetryTransport retries requests.
+// @typescript-ignore RetryPolicy
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first. It
+	// defaults to 3.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry, which doubles on
+	// every further retry. It defaults to 250ms.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts. A Retry-After response
+	// header asking for a longer delay is not retried. It defaults to 10s.
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is the policy used by a RetryTransport without one.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 250 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+}
+
+// RetryTransport is a http.RoundTripper that retries idempotent requests that
+// fail with a connection error, 429 Too Many Requests or a 5xx status, with
+// exponential backoff. Retry-After response headers are honored.
+//
+// Requests are idempotent if their method is, or if they carry an
+// Idempotency-Key header. Requests with a body are only retried if the body
+// can be replayed, which is the case for the requests made by Client.
+// @typescript-ignore RetryTransport
+type RetryTransport struct {
+	Transport http.RoundTripper
+	Policy    RetryPolicy
+}
+
+var _ http.RoundTripper = &RetryTransport{}
+
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	policy := t.policy()
+	if !retryable(req) {
+		return transport.RoundTrip(req)
+	}
+
+	backoff := policy.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		res, err := transport.RoundTrip(req)
+		if attempt >= policy.MaxAttempts || !shouldRetry(req.Context(), res, err) {
+			return res, err
+		}
+
+		delay := backoff/2 + rand.N(backoff/2+1) //nolint:gosec // Jitter does not need to be secure.
+		if res != nil {
+			if after, ok := retryAfter(res.Header.Get("Retry-After"), time.Now()); ok {
+				if after > policy.MaxBackoff {
+					return res, nil
+				}
+				delay = after
+			}
+			// Drain the body so the connection can be reused.
+			_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 4096))
+			_ = res.Body.Close()
+		}
+		backoff = min(backoff*2, policy.MaxBackoff)
+		countRetry(req.Context())
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+func (t *RetryTransport) CloseIdleConnections() {
+	type closeIdler interface {
+		CloseIdleConnections()
+	}
+	if tr, ok := t.Transport.(closeIdler); ok {
+		tr.CloseIdleConnections()
+	}
+}
+
+func (t *RetryTransport) policy() RetryPolicy {
+	policy := t.Policy
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = DefaultRetryPolicy.InitialBackoff
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = DefaultRetryPolicy.MaxBackoff
+	}
+	return policy
+}
+
+// retryable reports whether the request can safely be sent again.
+func retryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete, http.MethodTrace:
+		return true
+	default:
+		return req.Header.Get("Idempotency-Key") != ""
+	}
+}
+
+func shouldRetry(ctx context.Context, res *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch {
+	case res.StatusCode == http.StatusTooManyRequests:
+		return true
+	case res.StatusCode == http.StatusNotImplemented, res.StatusCode == http.StatusHTTPVersionNotSupported:
+		return false
+	default:
+		return res.StatusCode >= http.StatusInternalServerError
+	}
+}
+
+// retryAfter parses a Retry-After header, which is either a number of seconds
+// or an HTTP date.
+func retryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	at, err := http.ParseTime(header)
+	if err != nil {
+		return 0, false
+	}
+	return max(at.Sub(now), 0), true
+}
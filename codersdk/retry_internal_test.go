@@ -0,0 +1,191 @@
+package codersdk
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestRetryTransport(t *testing.T) {
+	t.Parallel()
+
+	policy := RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Second,
+	}
+
+	// newClient returns a client for a server that responds with the given
+	// statuses in turn, and a count of the requests it received.
+	newClient := func(t *testing.T, header http.Header, statuses ...int) (*Client, *atomic.Int32) {
+		t.Helper()
+
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			i := int(calls.Add(1)) - 1
+			if r.Body != nil {
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+				if r.Method == http.MethodPut {
+					assert.Equal(t, "{\"a\":1}\n", string(body))
+				}
+			}
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(statuses[min(i, len(statuses)-1)])
+		}))
+		t.Cleanup(srv.Close)
+
+		u, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+		client := New(u)
+		client.HTTPClient.Transport = &RetryTransport{Policy: policy}
+		return client, &calls
+	}
+
+	t.Run("RetriesServerErrors", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		client, calls := newClient(t, nil, http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK)
+
+		res, err := client.Request(ctx, http.MethodGet, "/", nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.EqualValues(t, 3, calls.Load())
+	})
+
+	t.Run("ReplaysBody", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		client, calls := newClient(t, nil, http.StatusTooManyRequests, http.StatusOK)
+
+		res, err := client.Request(ctx, http.MethodPut, "/", map[string]int{"a": 1})
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.EqualValues(t, 2, calls.Load())
+	})
+
+	t.Run("GivesUp", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		client, calls := newClient(t, nil, http.StatusInternalServerError)
+
+		res, err := client.Request(ctx, http.MethodGet, "/", nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusInternalServerError, res.StatusCode)
+		require.EqualValues(t, policy.MaxAttempts, calls.Load())
+	})
+
+	t.Run("NotIdempotent", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		client, calls := newClient(t, nil, http.StatusServiceUnavailable, http.StatusOK)
+
+		res, err := client.Request(ctx, http.MethodPost, "/", map[string]int{"a": 1})
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+		require.EqualValues(t, 1, calls.Load())
+
+		// An idempotency key makes it safe to retry.
+		res, err = client.Request(ctx, http.MethodPost, "/", map[string]int{"a": 1}, func(r *http.Request) {
+			r.Header.Set("Idempotency-Key", "key")
+		})
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("ClientErrors", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		client, calls := newClient(t, nil, http.StatusNotFound, http.StatusOK)
+
+		res, err := client.Request(ctx, http.MethodGet, "/", nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+		require.EqualValues(t, 1, calls.Load())
+	})
+
+	t.Run("RetryAfterTooLong", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		client, calls := newClient(t, http.Header{"Retry-After": {"60"}}, http.StatusTooManyRequests, http.StatusOK)
+
+		res, err := client.Request(ctx, http.MethodGet, "/", nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+		require.EqualValues(t, 1, calls.Load())
+	})
+
+	t.Run("ConnectionError", func(t *testing.T) {
+		t.Parallel()
+
+		var calls atomic.Int32
+		transport := &RetryTransport{
+			Policy: policy,
+			Transport: RoundTripperFunc(func(*http.Request) (*http.Response, error) {
+				calls.Add(1)
+				return nil, io.ErrUnexpectedEOF
+			}),
+		}
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com", nil)
+		require.NoError(t, err)
+		//nolint:bodyclose // The response is nil.
+		_, err = transport.RoundTrip(req)
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		require.EqualValues(t, policy.MaxAttempts, calls.Load())
+
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		calls.Store(0)
+		req, err = http.NewRequestWithContext(canceled, http.MethodGet, "http://example.com", nil)
+		require.NoError(t, err)
+		//nolint:bodyclose // The response is nil.
+		_, err = transport.RoundTrip(req)
+		require.Error(t, err)
+		require.EqualValues(t, 1, calls.Load())
+	})
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	d, ok := retryAfter("3", now)
+	require.True(t, ok)
+	require.Equal(t, 3*time.Second, d)
+
+	d, ok = retryAfter(now.Add(5*time.Second).Format(http.TimeFormat), now)
+	require.True(t, ok)
+	require.Equal(t, 5*time.Second, d)
+
+	_, ok = retryAfter("", now)
+	require.False(t, ok)
+	_, ok = retryAfter("soon", now)
+	require.False(t, ok)
+}
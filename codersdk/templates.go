@@ -62,6 +62,12 @@ type Template struct {
 	RequireActiveVersion bool                         `json:"require_active_version"`
 	MaxPortShareLevel    WorkspaceAgentPortShareLevel `json:"max_port_share_level"`
 	CORSBehavior         CORSBehavior                 `json:"cors_behavior"`
+	// CORSAllowedOrigins are the origins allowed to make cross-origin
+	// requests to apps when CORSBehavior is allowlist.
+	CORSAllowedOrigins []string `json:"cors_allowed_origins"`
+	// CORSAllowCredentials is true if apps allow credentialed cross-origin
+	// requests from the allowlisted origins.
+	CORSAllowCredentials bool `json:"cors_allow_credentials"`
 
 	UseClassicParameterFlow bool `json:"use_classic_parameter_flow"`
 }
@@ -257,6 +263,15 @@ type UpdateTemplateMeta struct {
 	DisableEveryoneGroupAccess bool                          `json:"disable_everyone_group_access"`
 	MaxPortShareLevel          *WorkspaceAgentPortShareLevel `json:"max_port_share_level,omitempty"`
 	CORSBehavior               *CORSBehavior                 `json:"cors_behavior,omitempty"`
+	// CORSAllowedOrigins replaces the origins allowed to make cross-origin
+	// requests to apps when CORSBehavior is allowlist. Origins are exact,
+	// e.g. https://app.example.com, or match any subdomain, e.g.
+	// https://*.example.com.
+	CORSAllowedOrigins *[]string `json:"cors_allowed_origins,omitempty"`
+	// CORSAllowCredentials allows credentialed cross-origin requests to apps
+	// from the allowlisted origins. It requires the allowlist CORS behavior
+	// and can't be combined with wildcard origins.
+	CORSAllowCredentials *bool `json:"cors_allow_credentials,omitempty"`
 	// UseClassicParameterFlow is a flag that switches the default behavior to use the classic
 	// parameter flow when creating a workspace. This only affects deployments with the experiment
 	// "dynamic-parameters" enabled. This setting will live for a period after the experiment is
@@ -0,0 +1,87 @@
+package codersdk
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.14.0"
+	"go.opentelemetry.io/otel/semconv/v1.14.0/httpconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attributes set on the spans created by Trace.
+const (
+	TraceAttributeOrganization = attribute.Key("coder.organization")
+	TraceAttributeWorkspaceID  = attribute.Key("coder.workspace_id")
+)
+
+// Trace returns a middleware that creates a client span with tracerProvider
+// for every request, and propagates its trace context to the Coder API with
+// the global propagator. Spans are named after the route of the request, and
+// annotated with the organization and workspace it is for, if the path says.
+//
+// Unlike setting Client.Trace, which only propagates the caller's trace
+// context, this records the requests themselves.
+func Trace(tracerProvider trace.TracerProvider) RequestMiddleware {
+	tracer := tracerProvider.Tracer("codersdk")
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			route, attrs := traceRoute(req.URL.Path)
+			ctx, span := tracer.Start(req.Context(), fmt.Sprintf("%s %s", req.Method, route),
+				trace.WithSpanKind(trace.SpanKindClient),
+			)
+			defer span.End()
+
+			// Round trippers must not modify the request they are given.
+			req = req.Clone(ctx)
+			otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+			span.SetAttributes(httpconv.ClientRequest(req)...)
+			span.SetAttributes(semconv.HTTPRouteKey.String(route))
+			span.SetAttributes(attrs...)
+
+			res, err := next.RoundTrip(req)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return nil, err
+			}
+			span.SetAttributes(httpconv.ClientResponse(res)...)
+			span.SetStatus(httpconv.ClientStatus(res.StatusCode))
+			return res, nil
+		})
+	}
+}
+
+// traceRoute approximates the route template of an API path, to keep span
+// names low cardinality, by replacing UUIDs with a parameter named after the
+// resource, e.g. /api/v2/workspaces/{workspace}/builds. It also returns the
+// organization and workspace the path is for, if any.
+func traceRoute(path string) (string, []attribute.KeyValue) {
+	var attrs []attribute.KeyValue
+	parts := strings.Split(path, "/")
+	for i := 1; i < len(parts); i++ {
+		prev := parts[i-1]
+		if prev == "organizations" && parts[i] != "" {
+			attrs = append(attrs, TraceAttributeOrganization.String(parts[i]))
+		}
+		if _, err := uuid.Parse(parts[i]); err != nil {
+			continue
+		}
+		if prev == "workspaces" {
+			attrs = append(attrs, TraceAttributeWorkspaceID.String(parts[i]))
+		}
+		param := "id"
+		if prev != "" {
+			param = strings.TrimSuffix(prev, "s")
+		}
+		parts[i] = "{" + param + "}"
+	}
+	return strings.Join(parts, "/"), attrs
+}
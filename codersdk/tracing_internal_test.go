@@ -0,0 +1,65 @@
+package codersdk
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.14.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestTrace(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	client := New(u)
+	client.Use(Trace(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))))
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	res, err := client.Request(ctx, http.MethodGet, "/api/v2/organizations/coder/workspaces/2f6a7f36-1a1b-4f5c-9e3c-6a4b7f0f8d9e/builds", nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	require.Equal(t, "GET /api/v2/organizations/coder/workspaces/{workspace}/builds", span.Name())
+	require.Equal(t, trace.SpanKindClient, span.SpanKind())
+	require.Subset(t, span.Attributes(), []attribute.KeyValue{
+		semconv.HTTPRouteKey.String("/api/v2/organizations/coder/workspaces/{workspace}/builds"),
+		semconv.HTTPStatusCodeKey.Int(http.StatusNotFound),
+		TraceAttributeOrganization.String("coder"),
+		TraceAttributeWorkspaceID.String("2f6a7f36-1a1b-4f5c-9e3c-6a4b7f0f8d9e"),
+	})
+}
+
+func TestTraceRoute(t *testing.T) {
+	t.Parallel()
+
+	route, attrs := traceRoute("/api/v2/users/me/workspaces")
+	require.Equal(t, "/api/v2/users/me/workspaces", route)
+	require.Empty(t, attrs)
+
+	route, attrs = traceRoute("/api/v2/templateversions/2f6a7f36-1a1b-4f5c-9e3c-6a4b7f0f8d9e/parameters")
+	require.Equal(t, "/api/v2/templateversions/{templateversion}/parameters", route)
+	require.Empty(t, attrs)
+
+	route, attrs = traceRoute("/api/v2/organizations/2f6a7f36-1a1b-4f5c-9e3c-6a4b7f0f8d9e/members")
+	require.Equal(t, "/api/v2/organizations/{organization}/members", route)
+	require.Equal(t, []attribute.KeyValue{TraceAttributeOrganization.String("2f6a7f36-1a1b-4f5c-9e3c-6a4b7f0f8d9e")}, attrs)
+}
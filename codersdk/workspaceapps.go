@@ -1,6 +1,9 @@
 package codersdk
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
 	"time"
 
 	"github.com/google/uuid"
@@ -126,3 +129,53 @@ type WorkspaceAppStatus struct {
 	// NeedsUserAttention specifies whether the status needs user attention.
 	NeedsUserAttention bool `json:"needs_user_attention"`
 }
+
+// WorkspaceAppCORSCheckRequest asks which CORS headers the app proxy would
+// send for a cross-origin request to a workspace app.
+type WorkspaceAppCORSCheckRequest struct {
+	// AppURL is the URL of the app, either a subdomain app on the deployment's
+	// wildcard hostname or a path-based app on the access URL.
+	AppURL string `json:"app_url" validate:"required"`
+	// Origin is the origin the browser would send the request from, e.g.
+	// https://app.example.com.
+	Origin string `json:"origin" validate:"required"`
+	// Method is the method of the request. Defaults to GET.
+	Method string `json:"method,omitempty"`
+}
+
+type WorkspaceAppCORSCheckResponse struct {
+	CORSBehavior CORSBehavior `json:"cors_behavior"`
+	// Message explains the result, e.g. that the app sets its own CORS
+	// headers when the behavior is passthru.
+	Message string `json:"message,omitempty"`
+	// Preflight is the result of the OPTIONS request the browser sends before
+	// the request.
+	Preflight WorkspaceAppCORSCheckResult `json:"preflight"`
+	// Request is the result of the request itself.
+	Request WorkspaceAppCORSCheckResult `json:"request"`
+}
+
+type WorkspaceAppCORSCheckResult struct {
+	// Allowed is true if the browser would allow the origin to read the
+	// response.
+	Allowed bool `json:"allowed"`
+	// Headers are the CORS headers coderd would send.
+	Headers map[string]string `json:"headers"`
+}
+
+// WorkspaceAppCORSCheck evaluates the CORS behavior of a workspace app for a
+// request from the given origin.
+func (c *Client) WorkspaceAppCORSCheck(ctx context.Context, req WorkspaceAppCORSCheckRequest) (WorkspaceAppCORSCheckResponse, error) {
+	res, err := c.Request(ctx, http.MethodPost, "/api/v2/applications/cors-check", req)
+	if err != nil {
+		return WorkspaceAppCORSCheckResponse{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceAppCORSCheckResponse{}, ReadBodyAsError(res)
+	}
+
+	var resp WorkspaceAppCORSCheckResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
@@ -18,15 +18,18 @@ import (
 // AuditableResources map (below) as our documentation - generated in scripts/auditdocgen/main.go -
 // depends upon it.
 var AuditActionMap = map[string][]codersdk.AuditAction{
-	"GitSSHKey":       {codersdk.AuditActionCreate},
-	"Template":        {codersdk.AuditActionWrite, codersdk.AuditActionDelete},
-	"TemplateVersion": {codersdk.AuditActionCreate, codersdk.AuditActionWrite},
-	"User":            {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
-	"Workspace":       {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
-	"WorkspaceBuild":  {codersdk.AuditActionStart, codersdk.AuditActionStop},
-	"Group":           {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
-	"APIKey":          {codersdk.AuditActionLogin, codersdk.AuditActionLogout, codersdk.AuditActionRegister, codersdk.AuditActionCreate, codersdk.AuditActionDelete},
-	"License":         {codersdk.AuditActionCreate, codersdk.AuditActionDelete},
+	"GitSSHKey":                 {codersdk.AuditActionCreate},
+	"Template":                  {codersdk.AuditActionWrite, codersdk.AuditActionDelete},
+	"TemplateVersion":           {codersdk.AuditActionCreate, codersdk.AuditActionWrite},
+	"User":                      {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
+	"Workspace":                 {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
+	"WorkspaceBuild":            {codersdk.AuditActionStart, codersdk.AuditActionStop},
+	"Group":                     {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
+	"APIKey":                    {codersdk.AuditActionLogin, codersdk.AuditActionLogout, codersdk.AuditActionRegister, codersdk.AuditActionCreate, codersdk.AuditActionDelete},
+	"License":                   {codersdk.AuditActionCreate, codersdk.AuditActionDelete},
+	"AIRequest":                 {codersdk.AuditActionCreate},
+	"OrganizationCORSSettings":  {codersdk.AuditActionWrite},
+	"WorkspaceAppCORSPreflight": {codersdk.AuditActionCreate},
 }
 
 type Action string
@@ -116,6 +119,8 @@ var auditableResourcesTypes = map[any]map[string]Action{
 		"activity_bump":                     ActionTrack,
 		"use_classic_parameter_flow":        ActionTrack,
 		"cors_behavior":                     ActionTrack,
+		"cors_allowed_origins":              ActionTrack,
+		"cors_allow_credentials":            ActionTrack,
 	},
 	&database.TemplateVersion{}: {
 		"id":                      ActionTrack,
@@ -243,6 +248,29 @@ var auditableResourcesTypes = map[any]map[string]Action{
 		"id":                    ActionIgnore,
 		"reconciliation_paused": ActionTrack,
 	},
+	&database.AIRequest{}: {
+		"id":          ActionIgnore,
+		"feature":     ActionTrack,
+		"model":       ActionTrack,
+		"prompt_hash": ActionTrack,
+		"prompt":      ActionTrack,
+		"outcome":     ActionTrack,
+		"latency_ms":  ActionTrack,
+	},
+	&database.OrganizationCORSSettings{}: {
+		"default_behavior":       ActionTrack,
+		"allow_looser_templates": ActionTrack,
+	},
+	&database.WorkspaceAppCORSPreflight{}: {
+		"id":            ActionIgnore,
+		"workspace_id":  ActionTrack,
+		"agent_id":      ActionTrack,
+		"app":           ActionTrack,
+		"cors_behavior": ActionTrack,
+		"origin":        ActionTrack,
+		"method":        ActionTrack,
+		"allowed":       ActionTrack,
+	},
 	// TODO: track an ID here when the below ticket is completed:
 	// https://github.com/coder/coder/pull/6012
 	&database.License{}: {
@@ -265,6 +265,7 @@ func (r *RootCmd) proxyServer() *serpent.Command {
 				PrometheusRegistry:     prometheusRegistry,
 				APIRateLimit:           int(cfg.RateLimit.API.Value()),
 				CookieConfig:           cfg.HTTPCookies,
+				AppCORSConfig:          cfg.WorkspaceAppCORS,
 				DisablePathApps:        cfg.DisablePathApps.Value(),
 				ProxySessionToken:      proxySessionToken.Value(),
 				AllowAllCors:           cfg.Dangerous.AllowAllCors.Value(),
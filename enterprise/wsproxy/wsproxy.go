@@ -72,6 +72,7 @@ type Options struct {
 
 	APIRateLimit           int
 	CookieConfig           codersdk.HTTPCookieConfig
+	AppCORSConfig          codersdk.WorkspaceAppCORSConfig
 	DisablePathApps        bool
 	DERPEnabled            bool
 	DERPServerRelayAddress string
@@ -309,6 +310,8 @@ func New(ctx context.Context, opts *Options) (*Server, error) {
 
 		DisablePathApps: opts.DisablePathApps,
 		Cookies:         opts.CookieConfig,
+		CORS:            opts.AppCORSConfig,
+		CORSMetrics:     workspaceapps.NewCORSMetrics(s.PrometheusRegistry),
 
 		AgentProvider:            agentProvider,
 		StatsCollector:           workspaceapps.NewStatsCollector(opts.StatsCollectorOptions),
@@ -28,11 +28,108 @@ export interface AIBridgeOpenAIConfig {
 // From codersdk/deployment.go
 export interface AIConfig {
 	readonly bridge?: AIBridgeConfig;
+	readonly task_name_cache_ttl?: number;
+	readonly task_name_system_prompt_file?: string;
+	readonly task_name_instructions?: string;
+	readonly task_name_timeout?: number;
+	readonly task_name_call_timeout?: number;
+	readonly task_name_deny_list?: string;
+	readonly task_prompt_moderation?: string;
+	readonly task_prompt_deny_list?: string;
+	readonly task_name_provider?: string;
+	readonly task_name_provider_base_url?: string;
+	readonly task_name_provider_key?: string;
+	readonly task_name_provider_region?: string;
+	readonly task_name_provider_api_version?: string;
+	readonly task_name_model_aliases?: string;
+	readonly audit_prompt_content?: boolean;
+}
+
+// From codersdk/aitasks.go
+export interface AITaskNameBatchRequest {
+	readonly requests: readonly AITaskNameRequest[];
+}
+
+// From codersdk/aitasks.go
+export interface AITaskNameBatchResponse {
+	readonly results: readonly AITaskNameBatchResult[];
+}
+
+// From codersdk/aitasks.go
+export interface AITaskNameBatchResult {
+	readonly index: number;
+	readonly name?: string;
+	readonly names?: readonly string[];
+	readonly error?: Response;
+}
+
+// From codersdk/aitasks.go
+export interface AITaskNameModelSettings {
+	readonly models: readonly string[];
+}
+
+// From codersdk/aitasks.go
+export interface AITaskNameRequest {
+	readonly prompt: string;
+	readonly template_id?: string;
+	readonly repository_url?: string;
+	readonly avoid_names?: readonly string[];
+	readonly count?: number;
+}
+
+// From codersdk/aitasks.go
+export interface AITaskNameResponse {
+	readonly name: string;
+	readonly names: readonly string[];
+}
+
+// From codersdk/aitasks.go
+export interface AITaskNameStreamEvent {
+	readonly delta?: string;
+	readonly name?: string;
+	readonly names?: readonly string[];
+}
+
+// From codersdk/aitasks.go
+export interface AITaskParametersRequest {
+	readonly prompt: string;
+	readonly template_version_id: string;
+}
+
+// From codersdk/aitasks.go
+export interface AITaskParametersResponse {
+	readonly suggestions: Record<string, string>;
 }
 
 // From codersdk/aitasks.go
 export const AITaskPromptParameterName = "AI Prompt";
 
+// From codersdk/aitasks.go
+export interface AITaskUsage {
+	readonly user_id: string;
+	readonly organization_id?: string;
+	readonly feature: string;
+	readonly model: string;
+	readonly calls: number;
+	readonly prompt_tokens: number;
+	readonly completion_tokens: number;
+}
+
+// From codersdk/aitasks.go
+export interface AITaskUsageRequest {
+	readonly start_time: string;
+	readonly end_time: string;
+	readonly organization_id?: string;
+	readonly user_id?: string;
+}
+
+// From codersdk/aitasks.go
+export interface AITaskUsageResponse {
+	readonly start_time: string;
+	readonly end_time: string;
+	readonly usage: readonly AITaskUsage[];
+}
+
 // From codersdk/aitasks.go
 export interface AITasksPromptsResponse {
 	readonly prompts: Record<string, string>;
@@ -332,9 +429,13 @@ export const BypassRatelimitHeader = "X-Coder-Bypass-Ratelimit";
 export const CLITelemetryHeader = "Coder-CLI-Telemetry";
 
 // From codersdk/cors_behavior.go
-export type CORSBehavior = "passthru" | "simple";
+export type CORSBehavior = "allowlist" | "passthru" | "simple";
 
-export const CORSBehaviors: CORSBehavior[] = ["passthru", "simple"];
+export const CORSBehaviors: CORSBehavior[] = [
+	"allowlist",
+	"passthru",
+	"simple",
+];
 
 // From codersdk/workspacebuilds.go
 export interface CancelWorkspaceBuildParams {
@@ -530,6 +631,8 @@ export interface CreateTemplateRequest {
 	readonly max_port_share_level: WorkspaceAgentPortShareLevel | null;
 	readonly template_use_classic_parameter_flow?: boolean;
 	readonly cors_behavior: CORSBehavior | null;
+	readonly cors_allowed_origins?: readonly string[];
+	readonly cors_allow_credentials?: boolean;
 }
 
 // From codersdk/templateversions.go
@@ -826,6 +929,7 @@ export interface DeploymentValues {
 	readonly tls?: TLSConfig;
 	readonly trace?: TraceConfig;
 	readonly http_cookies?: HTTPCookieConfig;
+	readonly workspace_app_cors?: WorkspaceAppCORSConfig;
 	readonly strict_transport_security?: number;
 	readonly strict_transport_security_options?: string;
 	readonly ssh_keygen_algorithm?: string;
@@ -1858,6 +1962,12 @@ export interface Organization extends MinimalOrganization {
 	readonly is_default: boolean;
 }
 
+// From codersdk/cors_behavior.go
+export interface OrganizationCORSSettings {
+	readonly default_behavior: CORSBehavior;
+	readonly allow_looser_templates: boolean;
+}
+
 // From codersdk/organizations.go
 export interface OrganizationMember {
 	readonly user_id: string;
@@ -2500,6 +2610,11 @@ export const RBACResources: RBACResource[] = [
 export interface RateLimitConfig {
 	readonly disable_all: boolean;
 	readonly api: number;
+	readonly ai_task_name: number;
+	readonly ai_task_name_deployment: number;
+	readonly ai_task_name_concurrency: number;
+	readonly ai_task_name_tokens: number;
+	readonly ai_task_name_deployment_tokens: number;
 }
 
 // From codersdk/users.go
@@ -2556,6 +2671,7 @@ export interface ResolveAutostartResponse {
 
 // From codersdk/audit.go
 export type ResourceType =
+	| "ai_request"
 	| "api_key"
 	| "convert_login"
 	| "custom_role"
@@ -2571,6 +2687,7 @@ export type ResourceType =
 	| "oauth2_provider_app"
 	| "oauth2_provider_app_secret"
 	| "organization"
+	| "organization_cors_settings"
 	| "organization_member"
 	| "prebuilds_settings"
 	| "template"
@@ -2579,10 +2696,12 @@ export type ResourceType =
 	| "workspace"
 	| "workspace_agent"
 	| "workspace_app"
+	| "workspace_app_cors_preflight"
 	| "workspace_build"
 	| "workspace_proxy";
 
 export const ResourceTypes: ResourceType[] = [
+	"ai_request",
 	"api_key",
 	"convert_login",
 	"custom_role",
@@ -2598,6 +2717,7 @@ export const ResourceTypes: ResourceType[] = [
 	"oauth2_provider_app",
 	"oauth2_provider_app_secret",
 	"organization",
+	"organization_cors_settings",
 	"organization_member",
 	"prebuilds_settings",
 	"template",
@@ -2606,6 +2726,7 @@ export const ResourceTypes: ResourceType[] = [
 	"workspace",
 	"workspace_agent",
 	"workspace_app",
+	"workspace_app_cors_preflight",
 	"workspace_build",
 	"workspace_proxy",
 ];
@@ -2933,6 +3054,8 @@ export interface Template {
 	readonly require_active_version: boolean;
 	readonly max_port_share_level: WorkspaceAgentPortShareLevel;
 	readonly cors_behavior: CORSBehavior;
+	readonly cors_allowed_origins: readonly string[];
+	readonly cors_allow_credentials: boolean;
 	readonly use_classic_parameter_flow: boolean;
 }
 
@@ -3307,6 +3430,8 @@ export interface UpdateTemplateMeta {
 	readonly disable_everyone_group_access: boolean;
 	readonly max_port_share_level?: WorkspaceAgentPortShareLevel;
 	readonly cors_behavior?: CORSBehavior;
+	readonly cors_allowed_origins?: readonly string[];
+	readonly cors_allow_credentials?: boolean;
 	readonly use_classic_parameter_flow?: boolean;
 }
 
@@ -3891,6 +4016,35 @@ export interface WorkspaceApp {
 	readonly statuses: readonly WorkspaceAppStatus[];
 }
 
+// From codersdk/workspaceapps.go
+export interface WorkspaceAppCORSCheckRequest {
+	readonly app_url: string;
+	readonly origin: string;
+	readonly method?: string;
+}
+
+// From codersdk/workspaceapps.go
+export interface WorkspaceAppCORSCheckResponse {
+	readonly cors_behavior: CORSBehavior;
+	readonly message?: string;
+	readonly preflight: WorkspaceAppCORSCheckResult;
+	readonly request: WorkspaceAppCORSCheckResult;
+}
+
+// From codersdk/workspaceapps.go
+export interface WorkspaceAppCORSCheckResult {
+	readonly allowed: boolean;
+	readonly headers: Record<string, string>;
+}
+
+// From codersdk/deployment.go
+export interface WorkspaceAppCORSConfig {
+	readonly max_age?: number;
+	readonly allowed_methods?: string;
+	readonly allowed_headers?: string;
+	readonly exposed_headers?: string;
+}
+
 // From codersdk/workspaceapps.go
 export type WorkspaceAppHealth =
 	| "disabled"